@@ -0,0 +1,162 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"datamodel"
+	pb "datamodel/protobuf"
+	"utils"
+)
+
+// Batcher buffers adds on the client and sends the values of a sketch or
+// domain in a single Add once Size values are buffered, or every Interval.
+// Errors of background flushes are passed to OnError and returned by the
+// next Flush.
+type Batcher struct {
+	// OnError is called with the failed request and the error when a flush
+	// fails, it may be nil
+	OnError func(req *pb.AddRequest, err error)
+
+	client   *Client
+	size     int
+	lock     sync.Mutex
+	pending  map[string]*pb.AddRequest
+	err      error
+	stop     chan struct{}
+	stopOnce sync.Once
+	finished sync.WaitGroup
+}
+
+// NewBatcher returns a Batcher flushing a sketch or domain once size values
+// are buffered for it, and every interval unless interval is 0
+func (c *Client) NewBatcher(size int, interval time.Duration) *Batcher {
+	b := &Batcher{
+		client:  c,
+		size:    size,
+		pending: make(map[string]*pb.AddRequest),
+		stop:    make(chan struct{}),
+	}
+	if interval > 0 {
+		b.finished.Add(1)
+		go b.run(interval)
+	}
+	return b
+}
+
+func (b *Batcher) run(interval time.Duration) {
+	defer b.finished.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			b.flushPending(context.Background())
+		case <-b.stop:
+			return
+		}
+	}
+}
+
+// Add buffers values for every sketch of a domain
+func (b *Batcher) Add(domain string, values ...string) {
+	b.buffer("domain:"+domain, func() *pb.AddRequest {
+		return &pb.AddRequest{Domain: &pb.Domain{Name: utils.Stringp(domain)}}
+	}, values)
+}
+
+// AddToSketch buffers values for a single sketch
+func (b *Batcher) AddToSketch(name string, typ pb.SketchType, values ...string) {
+	sketch := newSketch(name, typ, nil)
+	info := &datamodel.Info{Sketch: sketch}
+	b.buffer("sketch:"+info.ID(), func() *pb.AddRequest {
+		return &pb.AddRequest{Sketch: sketch}
+	}, values)
+}
+
+func (b *Batcher) buffer(key string, newRequest func() *pb.AddRequest, values []string) {
+	b.lock.Lock()
+	req, ok := b.pending[key]
+	if !ok {
+		req = newRequest()
+		b.pending[key] = req
+	}
+	req.Values = append(req.Values, values...)
+	if len(req.Values) < b.size {
+		b.lock.Unlock()
+		return
+	}
+	delete(b.pending, key)
+	b.lock.Unlock()
+	b.send(context.Background(), req)
+}
+
+// send adds the values of req, recording the error if it fails
+func (b *Batcher) send(ctx context.Context, req *pb.AddRequest) {
+	reply, err := b.client.stub().Add(ctx, req)
+	if err == nil {
+		for _, res := range reply.GetResults() {
+			if !res.GetSuccess() {
+				err = &AddError{res}
+				break
+			}
+		}
+	}
+	if err == nil {
+		return
+	}
+	b.lock.Lock()
+	b.err = err
+	b.lock.Unlock()
+	if b.OnError != nil {
+		b.OnError(req, err)
+	}
+}
+
+// flushPending sends every buffered value, errors are recorded for the next
+// Flush
+func (b *Batcher) flushPending(ctx context.Context) {
+	b.lock.Lock()
+	pending := b.pending
+	b.pending = make(map[string]*pb.AddRequest)
+	b.lock.Unlock()
+
+	for _, req := range pending {
+		b.send(ctx, req)
+	}
+}
+
+// Flush sends every buffered value and returns the last error that occurred
+// since the previous Flush
+func (b *Batcher) Flush(ctx context.Context) error {
+	b.flushPending(ctx)
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	err := b.err
+	b.err = nil
+	return err
+}
+
+// Close stops the interval flushes and flushes the buffered values, it may be
+// called more than once
+func (b *Batcher) Close() error {
+	b.stopOnce.Do(func() {
+		close(b.stop)
+		b.finished.Wait()
+	})
+	return b.Flush(context.Background())
+}
+
+// AddError is returned when the server could not add the values to a sketch
+type AddError struct {
+	Result *pb.AddResult
+}
+
+func (e *AddError) Error() string {
+	return fmt.Sprintf("Could not add to %s %s: %s",
+		e.Result.GetSketch().GetType(), e.Result.GetSketch().GetName(), e.Result.GetError())
+}
@@ -0,0 +1,473 @@
+// Package client is a Go client for the Skizze server. It wraps the generated
+// gRPC stubs in a typed API, spreads calls over a pool of connections and
+// retries reads that failed because the server was unavailable.
+package client
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	pb "datamodel/protobuf"
+	"utils"
+)
+
+// Options configure a Client, zero values are replaced by the defaults
+type Options struct {
+	// Conns is the number of connections calls are spread over (default 1)
+	Conns int
+	// DialTimeout makes Dial wait until the server is reachable, Dial returns
+	// immediately if it is 0
+	DialTimeout time.Duration
+	// Retries is the number of times a read is retried (default 3), use a
+	// negative value to disable retries
+	Retries int
+	// Backoff is the delay before the first retry, it doubles on every
+	// retry (default 50ms)
+	Backoff time.Duration
+}
+
+// Defaults for Options
+const (
+	DefaultConns   = 1
+	DefaultRetries = 3
+	DefaultBackoff = 50 * time.Millisecond
+)
+
+// Client is a connection pool to a Skizze server, it is safe for concurrent
+// use
+type Client struct {
+	opts    Options
+	conns   []*grpc.ClientConn
+	clients []pb.SkizzeClient
+	next    uint32
+}
+
+// Dial connects to the Skizze server at address (host:port)
+func Dial(address string, opts Options) (*Client, error) {
+	if opts.Conns <= 0 {
+		opts.Conns = DefaultConns
+	}
+	if opts.Retries == 0 {
+		opts.Retries = DefaultRetries
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = DefaultBackoff
+	}
+	dialOpts := []grpc.DialOption{grpc.WithInsecure()}
+	if opts.DialTimeout > 0 {
+		dialOpts = append(dialOpts, grpc.WithBlock(), grpc.WithTimeout(opts.DialTimeout))
+	}
+
+	c := &Client{opts: opts}
+	for i := 0; i < opts.Conns; i++ {
+		conn, err := grpc.Dial(address, dialOpts...)
+		if err != nil {
+			_ = c.Close()
+			return nil, err
+		}
+		c.conns = append(c.conns, conn)
+		c.clients = append(c.clients, pb.NewSkizzeClient(conn))
+	}
+	return c, nil
+}
+
+// Close closes every connection of the pool
+func (c *Client) Close() error {
+	var err error
+	for _, conn := range c.conns {
+		if err2 := conn.Close(); err == nil {
+			err = err2
+		}
+	}
+	return err
+}
+
+// stub picks the connections of the pool round robin
+func (c *Client) stub() pb.SkizzeClient {
+	n := atomic.AddUint32(&c.next, 1)
+	return c.clients[int(n)%len(c.clients)]
+}
+
+// isRetryable reports whether a read may succeed when it is sent again
+func isRetryable(err error) bool {
+	return grpc.Code(err) == codes.Unavailable
+}
+
+// retry calls fn until it succeeds, fails with an error that is not
+// retryable, ctx is done or the retries are exhausted
+func (c *Client) retry(ctx context.Context, fn func(pb.SkizzeClient) error) error {
+	backoff := c.opts.Backoff
+	for i := 0; ; i++ {
+		err := fn(c.stub())
+		if err == nil || !isRetryable(err) || i >= c.opts.Retries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func newSketch(name string, typ pb.SketchType, props *pb.SketchProperties) *pb.Sketch {
+	return &pb.Sketch{
+		Name:       utils.Stringp(name),
+		Type:       &typ,
+		Properties: props,
+	}
+}
+
+// CreateDomain creates a domain with the given sketches, or with one sketch
+// of every type and the server defaults if none are given. The names of the
+// sketches default to the name of the domain.
+func (c *Client) CreateDomain(ctx context.Context, name string, sketches ...*pb.Sketch) (*pb.Domain, error) {
+	for _, sketch := range sketches {
+		if sketch.Name == nil {
+			sketch.Name = utils.Stringp(name)
+		}
+	}
+	return c.stub().CreateDomain(ctx, &pb.Domain{Name: utils.Stringp(name), Sketches: sketches})
+}
+
+// DeleteDomain deletes a domain and its sketches
+func (c *Client) DeleteDomain(ctx context.Context, name string) error {
+	_, err := c.stub().DeleteDomain(ctx, &pb.Domain{Name: utils.Stringp(name)})
+	return err
+}
+
+// GetDomain returns a domain and its sketches
+func (c *Client) GetDomain(ctx context.Context, name string) (*pb.Domain, error) {
+	var dom *pb.Domain
+	err := c.retry(ctx, func(stub pb.SkizzeClient) error {
+		var err error
+		dom, err = stub.GetDomain(ctx, &pb.Domain{Name: utils.Stringp(name)})
+		return err
+	})
+	return dom, err
+}
+
+// ListDomains returns the names of every domain
+func (c *Client) ListDomains(ctx context.Context) ([]string, error) {
+	var reply *pb.ListDomainsReply
+	err := c.retry(ctx, func(stub pb.SkizzeClient) error {
+		var err error
+		reply, err = stub.ListDomains(ctx, &pb.Empty{})
+		return err
+	})
+	return reply.GetNames(), err
+}
+
+// CreateSketch creates a standalone sketch, props may be nil to use the
+// server defaults
+func (c *Client) CreateSketch(ctx context.Context, name string, typ pb.SketchType, props *pb.SketchProperties) (*pb.Sketch, error) {
+	return c.stub().CreateSketch(ctx, newSketch(name, typ, props))
+}
+
+// DeleteSketch deletes a sketch
+func (c *Client) DeleteSketch(ctx context.Context, name string, typ pb.SketchType) error {
+	_, err := c.stub().DeleteSketch(ctx, newSketch(name, typ, nil))
+	return err
+}
+
+// GetSketch returns the properties and state of a sketch
+func (c *Client) GetSketch(ctx context.Context, name string, typ pb.SketchType) (*pb.Sketch, error) {
+	var sketch *pb.Sketch
+	err := c.retry(ctx, func(stub pb.SkizzeClient) error {
+		var err error
+		sketch, err = stub.GetSketch(ctx, newSketch(name, typ, nil))
+		return err
+	})
+	return sketch, err
+}
+
+// ListSketches returns every sketch, including the sketches of domains
+func (c *Client) ListSketches(ctx context.Context) ([]*pb.Sketch, error) {
+	var reply *pb.ListReply
+	err := c.retry(ctx, func(stub pb.SkizzeClient) error {
+		var err error
+		reply, err = stub.ListAll(ctx, &pb.Empty{})
+		return err
+	})
+	return reply.GetSketches(), err
+}
+
+// Add adds values to every sketch of a domain and reports the outcome per
+// sketch. Adds are not idempotent and are never retried.
+func (c *Client) Add(ctx context.Context, domain string, values ...string) ([]*pb.AddResult, error) {
+	reply, err := c.stub().Add(ctx, &pb.AddRequest{
+		Domain: &pb.Domain{Name: utils.Stringp(domain)},
+		Values: values,
+	})
+	return reply.GetResults(), err
+}
+
+// AddToSketch adds values to a single sketch
+func (c *Client) AddToSketch(ctx context.Context, name string, typ pb.SketchType, values ...string) (*pb.AddResult, error) {
+	reply, err := c.stub().Add(ctx, &pb.AddRequest{
+		Sketch: newSketch(name, typ, nil),
+		Values: values,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(reply.GetResults()) != 1 {
+		return nil, errors.New("Expected a single add result")
+	}
+	return reply.GetResults()[0], nil
+}
+
+// AddToGroups adds every value to the group of its key in the KCARD or
+// KRANK sketch called name, keys[i] being the key of values[i]
+func (c *Client) AddToGroups(ctx context.Context, name string, typ pb.SketchType, keys []string, values []string) (*pb.AddResult, error) {
+	reply, err := c.stub().Add(ctx, &pb.AddRequest{
+		Sketch: newSketch(name, typ, nil),
+		Keys:   keys,
+		Values: values,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(reply.GetResults()) != 1 {
+		return nil, errors.New("Expected a single add result")
+	}
+	return reply.GetResults()[0], nil
+}
+
+// AddIfAbsent adds the values that are not in the MEMB sketch called name yet
+// and returns for every value whether it was already present. A value given
+// twice is present the second time.
+func (c *Client) AddIfAbsent(ctx context.Context, name string, values ...string) ([]bool, error) {
+	reply, err := c.stub().AddIfAbsent(ctx, &pb.AddIfAbsentRequest{
+		Sketch: newSketch(name, pb.SketchType_MEMB, nil),
+		Values: values,
+	})
+	if err != nil {
+		return nil, err
+	}
+	present := make([]bool, len(reply.GetResults()))
+	for i, v := range reply.GetResults() {
+		present[i] = v.GetIsMember()
+	}
+	return present, nil
+}
+
+// getRequest builds the request of a read on the sketch called name
+func getRequest(name string, typ pb.SketchType, values []string) *pb.GetRequest {
+	return &pb.GetRequest{
+		Sketches: []*pb.Sketch{newSketch(name, typ, nil)},
+		Values:   values,
+	}
+}
+
+// Cardinality returns the number of distinct values added to the CARD
+// sketch called name
+func (c *Client) Cardinality(ctx context.Context, name string) (int64, error) {
+	res, err := c.CardinalityBounds(ctx, name, 0)
+	return res.GetCardinality(), err
+}
+
+// CardinalityBounds returns the cardinality of the CARD sketch called name
+// together with its lower and upper bounds at the given confidence, 0 uses
+// the server's default confidence
+func (c *Client) CardinalityBounds(ctx context.Context, name string, confidence float64) (*pb.CardinalityResult, error) {
+	req := getRequest(name, pb.SketchType_CARD, nil)
+	if confidence != 0 {
+		req.Confidence = utils.Float32p(float32(confidence))
+	}
+	var reply *pb.GetCardinalityReply
+	err := c.retry(ctx, func(stub pb.SkizzeClient) error {
+		var err error
+		reply, err = stub.GetCardinality(ctx, req)
+		return err
+	})
+	if err != nil || len(reply.GetResults()) == 0 {
+		return nil, err
+	}
+	return reply.GetResults()[0], nil
+}
+
+// Frequency returns how often each of values was added to the FREQ sketch
+// called name
+func (c *Client) Frequency(ctx context.Context, name string, values ...string) (map[string]int64, error) {
+	var reply *pb.GetFrequencyReply
+	err := c.retry(ctx, func(stub pb.SkizzeClient) error {
+		var err error
+		reply, err = stub.GetFrequency(ctx, getRequest(name, pb.SketchType_FREQ, values))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	freqs := make(map[string]int64, len(values))
+	for _, res := range reply.GetResults() {
+		for _, v := range res.GetFrequencies() {
+			freqs[v.GetValue()] = v.GetCount()
+		}
+	}
+	return freqs, nil
+}
+
+// Membership returns whether each of values was added to the MEMB sketch
+// called name
+func (c *Client) Membership(ctx context.Context, name string, values ...string) (map[string]bool, error) {
+	var reply *pb.GetMembershipReply
+	err := c.retry(ctx, func(stub pb.SkizzeClient) error {
+		var err error
+		reply, err = stub.GetMembership(ctx, getRequest(name, pb.SketchType_MEMB, values))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	members := make(map[string]bool, len(values))
+	for _, res := range reply.GetResults() {
+		for _, v := range res.GetMemberships() {
+			members[v.GetValue()] = v.GetIsMember()
+		}
+	}
+	return members, nil
+}
+
+// TopK returns the top ranking values of the RANK sketch called name
+func (c *Client) TopK(ctx context.Context, name string) ([]*pb.Rank, error) {
+	var reply *pb.GetRankingsReply
+	err := c.retry(ctx, func(stub pb.SkizzeClient) error {
+		var err error
+		reply, err = stub.GetRankings(ctx, getRequest(name, pb.SketchType_RANK, nil))
+		return err
+	})
+	if err != nil || len(reply.GetResults()) == 0 {
+		return nil, err
+	}
+	return reply.GetResults()[0].GetRankings(), nil
+}
+
+// RangeCount returns the number of values between lo and hi, both included,
+// in the RANGE sketch called name
+func (c *Client) RangeCount(ctx context.Context, name string, lo, hi int64) (*pb.RangeCountResult, error) {
+	req := &pb.RangeCountRequest{
+		Sketches: []*pb.Sketch{newSketch(name, pb.SketchType_RANGE, nil)},
+		Lo:       utils.Int64p(lo),
+		Hi:       utils.Int64p(hi),
+	}
+	var reply *pb.GetRangeCountReply
+	err := c.retry(ctx, func(stub pb.SkizzeClient) error {
+		var err error
+		reply, err = stub.GetRangeCount(ctx, req)
+		return err
+	})
+	if err != nil || len(reply.GetResults()) == 0 {
+		return nil, err
+	}
+	return reply.GetResults()[0], nil
+}
+
+// Sample returns the values sampled by the SAMP sketch called name
+func (c *Client) Sample(ctx context.Context, name string) (*pb.SampleResult, error) {
+	req := &pb.GetRequest{Sketches: []*pb.Sketch{newSketch(name, pb.SketchType_SAMP, nil)}}
+	var reply *pb.GetSampleReply
+	err := c.retry(ctx, func(stub pb.SkizzeClient) error {
+		var err error
+		reply, err = stub.GetSample(ctx, req)
+		return err
+	})
+	if err != nil || len(reply.GetResults()) == 0 {
+		return nil, err
+	}
+	return reply.GetResults()[0], nil
+}
+
+func (c *Client) groups(ctx context.Context, req *pb.GroupsRequest) ([]*pb.GroupResult, error) {
+	var reply *pb.GetGroupsReply
+	err := c.retry(ctx, func(stub pb.SkizzeClient) error {
+		var err error
+		reply, err = stub.GetGroups(ctx, req)
+		return err
+	})
+	return reply.GetResults(), err
+}
+
+// Groups returns the results of the groups of the given keys in the KCARD
+// or KRANK sketch called name
+func (c *Client) Groups(ctx context.Context, name string, typ pb.SketchType, keys ...string) ([]*pb.GroupResult, error) {
+	return c.groups(ctx, &pb.GroupsRequest{Sketch: newSketch(name, typ, nil), Keys: keys})
+}
+
+// TopGroups returns up to limit groups (10 if limit is 0) with the largest
+// cardinality in the KCARD sketch called name
+func (c *Client) TopGroups(ctx context.Context, name string, limit int) ([]*pb.GroupResult, error) {
+	return c.groups(ctx, &pb.GroupsRequest{
+		Sketch: newSketch(name, pb.SketchType_KCARD, nil),
+		Limit:  utils.Int32p(int32(limit)),
+	})
+}
+
+// CardinalityExpression estimates the cardinality of unions, intersections
+// and differences of sketches of type typ (CARD or THETA) and domains, with
+// bounds at the given confidence (0 for the default)
+func (c *Client) CardinalityExpression(ctx context.Context, typ pb.SketchType, expr *pb.CardinalityExpression, confidence float64) (*pb.CardinalityExpressionReply, error) {
+	req := &pb.CardinalityExpressionRequest{
+		Expression: expr,
+		Confidence: utils.Float32p(float32(confidence)),
+		Type:       &typ,
+	}
+	var reply *pb.CardinalityExpressionReply
+	err := c.retry(ctx, func(stub pb.SkizzeClient) error {
+		var err error
+		reply, err = stub.GetCardinalityExpression(ctx, req)
+		return err
+	})
+	return reply, err
+}
+
+func (c *Client) findSimilar(ctx context.Context, req *pb.FindSimilarRequest) ([]*pb.Similarity, error) {
+	var reply *pb.FindSimilarReply
+	err := c.retry(ctx, func(stub pb.SkizzeClient) error {
+		var err error
+		reply, err = stub.FindSimilar(ctx, req)
+		return err
+	})
+	return reply.GetResults(), err
+}
+
+// FindSimilar returns up to limit MINHASH sketches (10 if limit is 0) ranked
+// by the similarity of their values to those of the MINHASH sketch called
+// name. With lsh only the sketches likely to be similar are compared.
+func (c *Client) FindSimilar(ctx context.Context, name string, limit int, lsh bool) ([]*pb.Similarity, error) {
+	return c.findSimilar(ctx, &pb.FindSimilarRequest{
+		Sketch: newSketch(name, pb.SketchType_MINHASH, nil),
+		Limit:  utils.Int32p(int32(limit)),
+		Lsh:    utils.Boolp(lsh),
+	})
+}
+
+// Similarity returns the Jaccard similarity of the values of the MINHASH
+// sketches called name and other
+func (c *Client) Similarity(ctx context.Context, name string, other string) (*pb.Similarity, error) {
+	results, err := c.findSimilar(ctx, &pb.FindSimilarRequest{
+		Sketch:     newSketch(name, pb.SketchType_MINHASH, nil),
+		Candidates: []*pb.Sketch{newSketch(other, pb.SketchType_MINHASH, nil)},
+	})
+	if err != nil || len(results) == 0 {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// QueryDomain reads every sketch of a domain at once
+func (c *Client) QueryDomain(ctx context.Context, name string, values ...string) ([]*pb.QueryResult, error) {
+	var reply *pb.QueryDomainReply
+	err := c.retry(ctx, func(stub pb.SkizzeClient) error {
+		var err error
+		reply, err = stub.QueryDomain(ctx, &pb.QueryDomainRequest{Name: utils.Stringp(name), Values: values})
+		return err
+	})
+	return reply.GetResults(), err
+}
@@ -0,0 +1,395 @@
+package client
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"config"
+	pb "datamodel/protobuf"
+	"manager"
+	"server"
+	"testutils"
+	"utils"
+)
+
+const address = "127.0.0.1:7779"
+
+func setupServer(t *testing.T) *Client {
+	config.Reset()
+	testutils.SetupTests()
+	go server.Run(manager.NewManager(), "127.0.0.1", 7779, config.DataDir)
+	time.Sleep(time.Millisecond * 50)
+
+	c, err := Dial(address, Options{Conns: 2, DialTimeout: time.Second})
+	if err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	return c
+}
+
+func tearDownServer(c *Client) {
+	_ = c.Close()
+	server.Stop()
+	testutils.TearDownTests()
+}
+
+func TestDomain(t *testing.T) {
+	c := setupServer(t)
+	defer tearDownServer(c)
+	ctx := context.Background()
+
+	if _, err := c.CreateDomain(ctx, "marvel"); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if results, err := c.Add(ctx, "marvel", "hulk", "thor", "hulk"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if len(results) != 4 {
+		t.Error("Expected 4 add results, got", results)
+	}
+
+	if card, err := c.Cardinality(ctx, "marvel"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if card != 2 {
+		t.Error("Expected cardinality 2, got", card)
+	}
+	if res, err := c.CardinalityBounds(ctx, "marvel", 0.99); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if res.GetConfidence() != 0.99 || res.GetLower() > 2 || res.GetUpper() < 2 {
+		t.Error("Expected 2 within bounds at 0.99, got", res)
+	}
+	if _, err := c.CardinalityBounds(ctx, "marvel", 1.5); grpc.Code(err) != codes.InvalidArgument {
+		t.Error("Expected InvalidArgument, got", err)
+	}
+	if freqs, err := c.Frequency(ctx, "marvel", "hulk", "loki"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if freqs["hulk"] != 2 || freqs["loki"] != 0 {
+		t.Error("Expected hulk == 2 and loki == 0, got", freqs)
+	}
+	if members, err := c.Membership(ctx, "marvel", "thor", "loki"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if !members["thor"] || members["loki"] {
+		t.Error("Expected thor to be a member and loki not, got", members)
+	}
+	if ranks, err := c.TopK(ctx, "marvel"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if len(ranks) != 2 || ranks[0].GetValue() != "hulk" {
+		t.Error("Expected [hulk thor], got", ranks)
+	}
+	if results, err := c.QueryDomain(ctx, "marvel", "hulk"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if len(results) != 4 {
+		t.Error("Expected 4 query results, got", results)
+	}
+	if names, err := c.ListDomains(ctx); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if len(names) != 1 || names[0] != "marvel" {
+		t.Error("Expected [marvel], got", names)
+	}
+
+	if err := c.DeleteDomain(ctx, "marvel"); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if _, err := c.GetDomain(ctx, "marvel"); grpc.Code(err) != codes.NotFound {
+		t.Error("Expected NotFound, got", err)
+	}
+}
+
+func TestSketch(t *testing.T) {
+	c := setupServer(t)
+	defer tearDownServer(c)
+	ctx := context.Background()
+
+	props := &pb.SketchProperties{MaxUniqueItems: utils.Int64p(1000)}
+	if _, err := c.CreateSketch(ctx, "marvel", pb.SketchType_CARD, props); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if res, err := c.AddToSketch(ctx, "marvel", pb.SketchType_CARD, "hulk", "thor"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if !res.GetSuccess() || res.GetApplied() != 2 {
+		t.Error("Expected 2 values applied, got", res)
+	}
+	if sketch, err := c.GetSketch(ctx, "marvel", pb.SketchType_CARD); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if sketch.GetProperties().GetMaxUniqueItems() != 1000 {
+		t.Error("Expected maxUniqueItems 1000, got", sketch)
+	}
+	if sketches, err := c.ListSketches(ctx); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if len(sketches) != 1 {
+		t.Error("Expected 1 sketch, got", sketches)
+	}
+	if _, err := c.AddIfAbsent(ctx, "marvel", "hulk"); grpc.Code(err) != codes.NotFound {
+		t.Error("Expected NotFound, got", err)
+	}
+	if _, err := c.CreateSketch(ctx, "marvel", pb.SketchType_MEMB, props); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if present, err := c.AddIfAbsent(ctx, "marvel", "hulk", "thor", "hulk"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if len(present) != 3 || present[0] || present[1] || !present[2] {
+		t.Error("Expected only the second hulk to be present, got", present)
+	}
+	if err := c.DeleteSketch(ctx, "marvel", pb.SketchType_CARD); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if _, err := c.Cardinality(ctx, "marvel"); grpc.Code(err) != codes.NotFound {
+		t.Error("Expected NotFound, got", err)
+	}
+}
+
+func TestRangeCount(t *testing.T) {
+	c := setupServer(t)
+	defer tearDownServer(c)
+	ctx := context.Background()
+
+	props := &pb.SketchProperties{MaxValue: utils.Int64p(100)}
+	if _, err := c.CreateSketch(ctx, "latency", pb.SketchType_RANGE, props); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if _, err := c.AddToSketch(ctx, "latency", pb.SketchType_RANGE, "1", "50", "99"); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if res, err := c.RangeCount(ctx, "latency", 0, 50); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if res.GetCount() != 2 {
+		t.Error("Expected 2 values in [0, 50], got", res.GetCount())
+	}
+	if _, err := c.RangeCount(ctx, "latency", 50, 0); grpc.Code(err) != codes.InvalidArgument {
+		t.Error("Expected InvalidArgument, got", err)
+	}
+}
+
+func TestCardinalityExpression(t *testing.T) {
+	c := setupServer(t)
+	defer tearDownServer(c)
+	ctx := context.Background()
+
+	for name, values := range map[string][]string{
+		"marvel": {"hulk", "thor", "wolverine"},
+		"xmen":   {"wolverine", "storm"},
+	} {
+		if _, err := c.CreateSketch(ctx, name, pb.SketchType_CARD, nil); err != nil {
+			t.Fatal("Expected no errors, got", err)
+		}
+		if _, err := c.AddToSketch(ctx, name, pb.SketchType_CARD, values...); err != nil {
+			t.Error("Expected no errors, got", err)
+		}
+	}
+	card := pb.SketchType_CARD
+	op := pb.SetOperation_DIFFERENCE
+	expr := &pb.CardinalityExpression{
+		Op: &op,
+		Operands: []*pb.CardinalityExpression{
+			{Sketch: &pb.Sketch{Name: utils.Stringp("marvel"), Type: &card}},
+			{Sketch: &pb.Sketch{Name: utils.Stringp("xmen"), Type: &card}},
+		},
+	}
+	if reply, err := c.CardinalityExpression(ctx, pb.SketchType_CARD, expr, 0); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if res := reply.GetResult(); res.GetCardinality() != 2 {
+		t.Error("Expected 2 avengers that are no xmen, got", res.GetCardinality())
+	}
+	expr.Operands = nil
+	if _, err := c.CardinalityExpression(ctx, pb.SketchType_CARD, expr, 0); grpc.Code(err) != codes.InvalidArgument {
+		t.Error("Expected InvalidArgument, got", err)
+	}
+}
+
+func TestFindSimilar(t *testing.T) {
+	c := setupServer(t)
+	defer tearDownServer(c)
+	ctx := context.Background()
+
+	for name, values := range map[string][]string{
+		"marvel":  {"hulk", "thor", "wolverine"},
+		"avenger": {"hulk", "thor", "wolverine", "ironman"},
+		"xmen":    {"storm", "cyclops"},
+	} {
+		if _, err := c.CreateSketch(ctx, name, pb.SketchType_MINHASH, nil); err != nil {
+			t.Fatal("Expected no errors, got", err)
+		}
+		if _, err := c.AddToSketch(ctx, name, pb.SketchType_MINHASH, values...); err != nil {
+			t.Error("Expected no errors, got", err)
+		}
+	}
+	if results, err := c.FindSimilar(ctx, "marvel", 1, false); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if len(results) != 1 || results[0].GetSketch().GetName() != "avenger" {
+		t.Error("Expected avenger to be the most similar, got", results)
+	}
+	if res, err := c.Similarity(ctx, "marvel", "xmen"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if res.GetSimilarity() > 0.1 {
+		t.Error("Expected marvel and xmen to differ, got", res.GetSimilarity())
+	}
+	if _, err := c.Similarity(ctx, "marvel", "dc"); grpc.Code(err) != codes.NotFound {
+		t.Error("Expected NotFound, got", err)
+	}
+}
+
+func TestSample(t *testing.T) {
+	c := setupServer(t)
+	defer tearDownServer(c)
+	ctx := context.Background()
+
+	if _, err := c.CreateSketch(ctx, "marvel", pb.SketchType_SAMP, nil); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if _, err := c.AddToSketch(ctx, "marvel", pb.SketchType_SAMP, "hulk", "thor", "wolverine"); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if res, err := c.Sample(ctx, "marvel"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if len(res.GetValues()) != 3 || res.GetCount() != 3 {
+		t.Error("Expected all 3 values in the sample, got", res)
+	}
+	if _, err := c.Sample(ctx, "dc"); grpc.Code(err) != codes.NotFound {
+		t.Error("Expected NotFound, got", err)
+	}
+}
+
+func TestGroups(t *testing.T) {
+	c := setupServer(t)
+	defer tearDownServer(c)
+	ctx := context.Background()
+
+	if _, err := c.CreateSketch(ctx, "pages", pb.SketchType_KCARD, nil); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	keys := []string{"/home", "/home", "/pricing"}
+	if res, err := c.AddToGroups(ctx, "pages", pb.SketchType_KCARD, keys, []string{"neil", "seif", "neil"}); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if res.GetApplied() != 3 {
+		t.Error("Expected 3 values applied, got", res)
+	}
+	if res, err := c.Groups(ctx, "pages", pb.SketchType_KCARD, "/pricing"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if len(res) != 1 || res[0].GetCardinality().GetCardinality() != 1 {
+		t.Error("Expected 1 visitor of /pricing, got", res)
+	}
+	if res, err := c.TopGroups(ctx, "pages", 1); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if len(res) != 1 || res[0].GetKey() != "/home" {
+		t.Error("Expected /home to be the top group, got", res)
+	}
+	if _, err := c.AddToGroups(ctx, "pages", pb.SketchType_KCARD, keys, nil); grpc.Code(err) != codes.InvalidArgument {
+		t.Error("Expected InvalidArgument, got", err)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	c := &Client{
+		opts:    Options{Retries: 2, Backoff: time.Millisecond},
+		clients: []pb.SkizzeClient{nil},
+	}
+	ctx := context.Background()
+
+	calls := 0
+	err := c.retry(ctx, func(pb.SkizzeClient) error {
+		calls++
+		if calls < 3 {
+			return grpc.Errorf(codes.Unavailable, "down")
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("Expected success after 3 calls, got %d calls and %v", calls, err)
+	}
+
+	calls = 0
+	err = c.retry(ctx, func(pb.SkizzeClient) error {
+		calls++
+		return grpc.Errorf(codes.Unavailable, "down")
+	})
+	if grpc.Code(err) != codes.Unavailable || calls != 3 {
+		t.Errorf("Expected Unavailable after 3 calls, got %d calls and %v", calls, err)
+	}
+
+	calls = 0
+	err = c.retry(ctx, func(pb.SkizzeClient) error {
+		calls++
+		return errors.New("broken")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("Expected a single call, got %d calls and %v", calls, err)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	err = c.retry(canceled, func(pb.SkizzeClient) error {
+		return grpc.Errorf(codes.Unavailable, "down")
+	})
+	if err != context.Canceled {
+		t.Error("Expected context.Canceled, got", err)
+	}
+}
+
+func TestBatcher(t *testing.T) {
+	c := setupServer(t)
+	defer tearDownServer(c)
+	ctx := context.Background()
+
+	if _, err := c.CreateSketch(ctx, "marvel", pb.SketchType_FREQ, nil); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if _, err := c.CreateDomain(ctx, "dc"); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+
+	b := c.NewBatcher(3, 0)
+	b.AddToSketch("marvel", pb.SketchType_FREQ, "hulk", "thor")
+	b.Add("dc", "batman")
+	if freqs, _ := c.Frequency(ctx, "marvel", "hulk"); freqs["hulk"] != 0 {
+		t.Error("Expected values to be buffered, got", freqs)
+	}
+	// Reaching the size flushes the sketch
+	b.AddToSketch("marvel", pb.SketchType_FREQ, "hulk")
+	if freqs, _ := c.Frequency(ctx, "marvel", "hulk"); freqs["hulk"] != 2 {
+		t.Error("Expected hulk == 2, got", freqs)
+	}
+	if err := b.Flush(ctx); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if card, _ := c.Cardinality(ctx, "dc"); card != 1 {
+		t.Error("Expected cardinality 1, got", card)
+	}
+
+	var failed *pb.AddRequest
+	b.OnError = func(req *pb.AddRequest, err error) { failed = req }
+	b.AddToSketch("x-men", pb.SketchType_FREQ, "wolverine")
+	if err := b.Close(); err == nil {
+		t.Error("Expected error adding to a non-existing sketch, got", err)
+	}
+	if failed.GetSketch().GetName() != "x-men" {
+		t.Error("Expected OnError to be called with the x-men request, got", failed)
+	}
+}
+
+func TestBatcherInterval(t *testing.T) {
+	c := setupServer(t)
+	defer tearDownServer(c)
+	ctx := context.Background()
+
+	if _, err := c.CreateSketch(ctx, "marvel", pb.SketchType_CARD, nil); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	b := c.NewBatcher(1000, 10*time.Millisecond)
+	b.AddToSketch("marvel", pb.SketchType_CARD, "hulk", "thor")
+	b.AddToSketch("x-men", pb.SketchType_CARD, "wolverine")
+	time.Sleep(50 * time.Millisecond)
+	if card, _ := c.Cardinality(ctx, "marvel"); card != 2 {
+		t.Error("Expected cardinality 2, got", card)
+	}
+	// The error of the interval flush is kept for Close
+	if err := b.Close(); err == nil {
+		t.Error("Expected error adding to a non-existing sketch, got", err)
+	}
+	if err := b.Close(); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+}
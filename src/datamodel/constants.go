@@ -7,44 +7,64 @@ HLLPP	=> HyperLogLogPlusPlus
 CML		=> Count-min-log sketch
 TopK	=> Top-K
 Bloom 	=> Bloom Filter
+Range	=> Dyadic count-min sketch
+Theta	=> K minimum values sketch
+MinHash	=> MinHash signature
+Sample	=> Reservoir sample
+Keyed*	=> A sketch of the type per group key
 */
 const (
-	DOM   = "dom"
-	HLLPP = "card"
-	CML   = "freq"
-	TopK  = "rank"
-	Bloom = "memb"
+	DOM       = "dom"
+	HLLPP     = "card"
+	CML       = "freq"
+	TopK      = "rank"
+	Bloom     = "memb"
+	Range     = "range"
+	Theta     = "theta"
+	MinHash   = "minhash"
+	Sample    = "samp"
+	KeyedCard = "kcard"
+	KeyedRank = "krank"
 )
 
-/*
-  MEMB = 1;
-  FREQ = 2;
-  RANK = 3;
-  CARD = 4;
-*/
-var typeMap = map[pb.SketchType]string{
-	pb.SketchType_MEMB: Bloom,
-	pb.SketchType_FREQ: CML,
-	pb.SketchType_RANK: TopK,
-	pb.SketchType_CARD: HLLPP,
-}
+// Default properties of the built-in sketch types, used for domain sketches
+// created without explicit ones
+const (
+	DefaultMaxUniqueItems = int64(1000000)
+	DefaultSize           = int64(100)
+	DefaultMaxValue       = int64(1<<32 - 1)
+	DefaultThetaSize      = int64(4096)
+	DefaultMinHashSize    = int64(128)
+	DefaultSampleSize     = int64(100)
+)
+
+// DefaultConfidence is the confidence of cardinality bounds when a query does
+// not ask for one
+const DefaultConfidence = 0.95
 
-// GetTypes ...
+// GetTypes returns the names of the registered sketch types
 func GetTypes() []string {
-	return []string{HLLPP, CML, TopK, Bloom}
+	var names []string
+	for _, t := range Types() {
+		names = append(names, t.Name)
+	}
+	return names
 }
 
-// GetTypeString ...
+// GetTypeString returns the name of a sketch type, or an empty string if the
+// type is not registered
 func GetTypeString(typ pb.SketchType) string {
-	return typeMap[typ]
+	if t, ok := LookupType(typ); ok {
+		return t.Name
+	}
+	return ""
 }
 
-// GetTypesPb ...
+// GetTypesPb returns the enum values of the registered sketch types
 func GetTypesPb() []pb.SketchType {
-	return []pb.SketchType{
-		pb.SketchType_MEMB,
-		pb.SketchType_FREQ,
-		pb.SketchType_RANK,
-		pb.SketchType_CARD,
+	var types []pb.SketchType
+	for _, t := range Types() {
+		types = append(types, t.Type)
 	}
+	return types
 }
@@ -4,6 +4,8 @@ import (
 	pb "datamodel/protobuf"
 	"fmt"
 	"utils"
+
+	"github.com/gogo/protobuf/proto"
 )
 
 // Info represents a info string describing the sketch
@@ -16,11 +18,17 @@ type Info struct {
 // ID return a unique ID based on the name and type
 func (info *Info) ID() string {
 	if len(info.id) == 0 {
-		info.id = fmt.Sprintf("%s.%s", info.GetName(), info.GetType())
+		info.id = fmt.Sprintf("%s.%s", info.GetName(), TypeName(info.GetType()))
 	}
 	return info.id
 }
 
+// Rename the sketch, its ID changes accordingly
+func (info *Info) Rename(name string) {
+	info.Name = utils.Stringp(name)
+	info.id = ""
+}
+
 // Locked returns the lock state of the sketch
 func (info *Info) Locked() bool {
 	return info.locked
@@ -36,16 +44,16 @@ func (info *Info) Unlock() {
 	info.locked = false
 }
 
-// Copy sketch
+// Copy sketch, with all of its properties
 func (info *Info) Copy() *Info {
 	typ := info.GetType()
+	props := NewEmptyProperties()
+	if info.Properties != nil {
+		props = proto.Clone(info.Properties).(*pb.SketchProperties)
+	}
 	return &Info{
 		Sketch: &pb.Sketch{
-			Properties: &pb.SketchProperties{
-				ErrorRate:      utils.Float32p(info.Properties.GetErrorRate()),
-				MaxUniqueItems: utils.Int64p(info.Properties.GetMaxUniqueItems()),
-				Size:           utils.Int64p(info.Properties.GetSize()),
-			},
+			Properties: props,
 			State: &pb.SketchState{
 				FillRate:     utils.Float32p(info.State.GetFillRate()),
 				LastSnapshot: utils.Int64p(info.State.GetLastSnapshot()),
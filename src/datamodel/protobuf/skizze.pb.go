@@ -1,6 +1,5 @@
-// Code generated by protoc-gen-go.
+// Code generated by protoc-gen-go. DO NOT EDIT.
 // source: src/datamodel/protobuf/skizze.proto
-// DO NOT EDIT!
 
 /*
 Package protobuf is a generated protocol buffer package.
@@ -24,8 +23,15 @@ It has these top-level messages:
 	ListRequest
 	ListReply
 	ListDomainsReply
+	AttachSketchRequest
+	DetachSketchRequest
+	DomainNameRequest
+	SketchNameRequest
 	AddRequest
+	AddResult
 	AddReply
+	AddIfAbsentRequest
+	AddIfAbsentReply
 	GetRequest
 	MembershipResult
 	FrequencyResult
@@ -35,6 +41,24 @@ It has these top-level messages:
 	GetFrequencyReply
 	GetCardinalityReply
 	GetRankingsReply
+	RangeCountRequest
+	RangeCountResult
+	GetRangeCountReply
+	CardinalityExpression
+	CardinalityExpressionRequest
+	CardinalityExpressionReply
+	FindSimilarRequest
+	Similarity
+	FindSimilarReply
+	SampleResult
+	SampledValue
+	GetSampleReply
+	GroupsRequest
+	GroupResult
+	GetGroupsReply
+	QueryDomainRequest
+	QueryResult
+	QueryDomainReply
 */
 package protobuf
 
@@ -54,7 +78,9 @@ var _ = math.Inf
 
 // This is a compile-time assertion to ensure that this generated file
 // is compatible with the proto package it is being compiled against.
-const _ = proto.ProtoPackageIsVersion1
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
 //
 // Enums
@@ -62,23 +88,41 @@ const _ = proto.ProtoPackageIsVersion1
 type SketchType int32
 
 const (
-	SketchType_MEMB SketchType = 1
-	SketchType_FREQ SketchType = 2
-	SketchType_RANK SketchType = 3
-	SketchType_CARD SketchType = 4
+	SketchType_MEMB    SketchType = 1
+	SketchType_FREQ    SketchType = 2
+	SketchType_RANK    SketchType = 3
+	SketchType_CARD    SketchType = 4
+	SketchType_RANGE   SketchType = 5
+	SketchType_THETA   SketchType = 6
+	SketchType_MINHASH SketchType = 7
+	SketchType_SAMP    SketchType = 8
+	SketchType_KCARD   SketchType = 9
+	SketchType_KRANK   SketchType = 10
 )
 
 var SketchType_name = map[int32]string{
-	1: "MEMB",
-	2: "FREQ",
-	3: "RANK",
-	4: "CARD",
+	1:  "MEMB",
+	2:  "FREQ",
+	3:  "RANK",
+	4:  "CARD",
+	5:  "RANGE",
+	6:  "THETA",
+	7:  "MINHASH",
+	8:  "SAMP",
+	9:  "KCARD",
+	10: "KRANK",
 }
 var SketchType_value = map[string]int32{
-	"MEMB": 1,
-	"FREQ": 2,
-	"RANK": 3,
-	"CARD": 4,
+	"MEMB":    1,
+	"FREQ":    2,
+	"RANK":    3,
+	"CARD":    4,
+	"RANGE":   5,
+	"THETA":   6,
+	"MINHASH": 7,
+	"SAMP":    8,
+	"KCARD":   9,
+	"KRANK":   10,
 }
 
 func (x SketchType) Enum() *SketchType {
@@ -99,6 +143,43 @@ func (x *SketchType) UnmarshalJSON(data []byte) error {
 }
 func (SketchType) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
 
+type SetOperation int32
+
+const (
+	SetOperation_UNION        SetOperation = 1
+	SetOperation_INTERSECTION SetOperation = 2
+	SetOperation_DIFFERENCE   SetOperation = 3
+)
+
+var SetOperation_name = map[int32]string{
+	1: "UNION",
+	2: "INTERSECTION",
+	3: "DIFFERENCE",
+}
+var SetOperation_value = map[string]int32{
+	"UNION":        1,
+	"INTERSECTION": 2,
+	"DIFFERENCE":   3,
+}
+
+func (x SetOperation) Enum() *SetOperation {
+	p := new(SetOperation)
+	*p = x
+	return p
+}
+func (x SetOperation) String() string {
+	return proto.EnumName(SetOperation_name, int32(x))
+}
+func (x *SetOperation) UnmarshalJSON(data []byte) error {
+	value, err := proto.UnmarshalJSONEnum(SetOperation_value, data, "SetOperation")
+	if err != nil {
+		return err
+	}
+	*x = SetOperation(value)
+	return nil
+}
+func (SetOperation) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
 type SnapshotStatus int32
 
 const (
@@ -137,7 +218,50 @@ func (x *SnapshotStatus) UnmarshalJSON(data []byte) error {
 	*x = SnapshotStatus(value)
 	return nil
 }
-func (SnapshotStatus) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+func (SnapshotStatus) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+type AddStatus int32
+
+const (
+	AddStatus_ADDED     AddStatus = 1
+	AddStatus_LOCKED    AddStatus = 2
+	AddStatus_SATURATED AddStatus = 3
+	AddStatus_MISSING   AddStatus = 4
+	AddStatus_ERROR     AddStatus = 5
+)
+
+var AddStatus_name = map[int32]string{
+	1: "ADDED",
+	2: "LOCKED",
+	3: "SATURATED",
+	4: "MISSING",
+	5: "ERROR",
+}
+var AddStatus_value = map[string]int32{
+	"ADDED":     1,
+	"LOCKED":    2,
+	"SATURATED": 3,
+	"MISSING":   4,
+	"ERROR":     5,
+}
+
+func (x AddStatus) Enum() *AddStatus {
+	p := new(AddStatus)
+	*p = x
+	return p
+}
+func (x AddStatus) String() string {
+	return proto.EnumName(AddStatus_name, int32(x))
+}
+func (x *AddStatus) UnmarshalJSON(data []byte) error {
+	value, err := proto.UnmarshalJSONEnum(AddStatus_value, data, "AddStatus")
+	if err != nil {
+		return err
+	}
+	*x = AddStatus(value)
+	return nil
+}
+func (AddStatus) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
 
 //
 // Generic Structures
@@ -155,6 +279,10 @@ type SketchProperties struct {
 	MaxUniqueItems   *int64   `protobuf:"varint,1,opt,name=maxUniqueItems" json:"maxUniqueItems,omitempty"`
 	ErrorRate        *float32 `protobuf:"fixed32,2,opt,name=errorRate" json:"errorRate,omitempty"`
 	Size             *int64   `protobuf:"varint,3,opt,name=size" json:"size,omitempty"`
+	HalfLife         *int64   `protobuf:"varint,4,opt,name=halfLife" json:"halfLife,omitempty"`
+	MinValue         *int64   `protobuf:"varint,5,opt,name=minValue" json:"minValue,omitempty"`
+	MaxValue         *int64   `protobuf:"varint,6,opt,name=maxValue" json:"maxValue,omitempty"`
+	Weighted         *bool    `protobuf:"varint,7,opt,name=weighted" json:"weighted,omitempty"`
 	XXX_unrecognized []byte   `json:"-"`
 }
 
@@ -184,6 +312,34 @@ func (m *SketchProperties) GetSize() int64 {
 	return 0
 }
 
+func (m *SketchProperties) GetHalfLife() int64 {
+	if m != nil && m.HalfLife != nil {
+		return *m.HalfLife
+	}
+	return 0
+}
+
+func (m *SketchProperties) GetMinValue() int64 {
+	if m != nil && m.MinValue != nil {
+		return *m.MinValue
+	}
+	return 0
+}
+
+func (m *SketchProperties) GetMaxValue() int64 {
+	if m != nil && m.MaxValue != nil {
+		return *m.MaxValue
+	}
+	return 0
+}
+
+func (m *SketchProperties) GetWeighted() bool {
+	if m != nil && m.Weighted != nil {
+		return *m.Weighted
+	}
+	return false
+}
+
 type SketchState struct {
 	FillRate         *float32 `protobuf:"fixed32,1,opt,name=fillRate" json:"fillRate,omitempty"`
 	LastSnapshot     *int64   `protobuf:"varint,2,opt,name=lastSnapshot" json:"lastSnapshot,omitempty"`
@@ -209,7 +365,8 @@ func (m *SketchState) GetLastSnapshot() int64 {
 	return 0
 }
 
-// CreateDomain: name:required, propertiess:optional (array = nSketchTypes, order of types above)
+// CreateDomain: name:required, sketches:optional (one per type with its own properties,
+//               every type with default properties if empty)
 // DeleteDomain: name:required
 // GetDomain   : name:required
 type Domain struct {
@@ -281,10 +438,12 @@ func (m *Sketch) GetState() *SketchState {
 	return nil
 }
 
+// falsePositiveRate: estimated probability that a value is wrongly reported as a member
 type Membership struct {
-	Value            *string `protobuf:"bytes,1,req,name=value" json:"value,omitempty"`
-	IsMember         *bool   `protobuf:"varint,2,req,name=isMember" json:"isMember,omitempty"`
-	XXX_unrecognized []byte  `json:"-"`
+	Value             *string  `protobuf:"bytes,1,req,name=value" json:"value,omitempty"`
+	IsMember          *bool    `protobuf:"varint,2,req,name=isMember" json:"isMember,omitempty"`
+	FalsePositiveRate *float32 `protobuf:"fixed32,3,opt,name=falsePositiveRate" json:"falsePositiveRate,omitempty"`
+	XXX_unrecognized  []byte   `json:"-"`
 }
 
 func (m *Membership) Reset()                    { *m = Membership{} }
@@ -306,9 +465,18 @@ func (m *Membership) GetIsMember() bool {
 	return false
 }
 
+func (m *Membership) GetFalsePositiveRate() float32 {
+	if m != nil && m.FalsePositiveRate != nil {
+		return *m.FalsePositiveRate
+	}
+	return 0
+}
+
+// error: bound of the overestimation of count, count - error is a lower bound
 type Frequency struct {
 	Value            *string `protobuf:"bytes,1,req,name=value" json:"value,omitempty"`
 	Count            *int64  `protobuf:"varint,2,req,name=count" json:"count,omitempty"`
+	Error            *int64  `protobuf:"varint,3,opt,name=error" json:"error,omitempty"`
 	XXX_unrecognized []byte  `json:"-"`
 }
 
@@ -331,9 +499,18 @@ func (m *Frequency) GetCount() int64 {
 	return 0
 }
 
+func (m *Frequency) GetError() int64 {
+	if m != nil && m.Error != nil {
+		return *m.Error
+	}
+	return 0
+}
+
+// error: maximum overestimation of count, count - error is a lower bound
 type Rank struct {
 	Value            *string `protobuf:"bytes,1,req,name=value" json:"value,omitempty"`
 	Count            *int64  `protobuf:"varint,2,req,name=count" json:"count,omitempty"`
+	Error            *int64  `protobuf:"varint,3,opt,name=error" json:"error,omitempty"`
 	XXX_unrecognized []byte  `json:"-"`
 }
 
@@ -356,6 +533,13 @@ func (m *Rank) GetCount() int64 {
 	return 0
 }
 
+func (m *Rank) GetError() int64 {
+	if m != nil && m.Error != nil {
+		return *m.Error
+	}
+	return 0
+}
+
 // Right now empty but in the future can request specific snapshot location
 // (e.g. S3 or disk) and snapshot options
 type CreateSnapshotRequest struct {
@@ -486,17 +670,126 @@ func (m *ListDomainsReply) GetNames() []string {
 	return nil
 }
 
+// AttachSketch: domain:required, sketch:required (name defaults to the domain name,
+//               an existing standalone sketch is adopted, otherwise a new one is created)
+type AttachSketchRequest struct {
+	Domain           *string `protobuf:"bytes,1,req,name=domain" json:"domain,omitempty"`
+	Sketch           *Sketch `protobuf:"bytes,2,req,name=sketch" json:"sketch,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *AttachSketchRequest) Reset()                    { *m = AttachSketchRequest{} }
+func (m *AttachSketchRequest) String() string            { return proto.CompactTextString(m) }
+func (*AttachSketchRequest) ProtoMessage()               {}
+func (*AttachSketchRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *AttachSketchRequest) GetDomain() string {
+	if m != nil && m.Domain != nil {
+		return *m.Domain
+	}
+	return ""
+}
+
+func (m *AttachSketchRequest) GetSketch() *Sketch {
+	if m != nil {
+		return m.Sketch
+	}
+	return nil
+}
+
+// DetachSketch: domain:required, sketch:required (the sketch is kept as a standalone sketch)
+type DetachSketchRequest struct {
+	Domain           *string `protobuf:"bytes,1,req,name=domain" json:"domain,omitempty"`
+	Sketch           *Sketch `protobuf:"bytes,2,req,name=sketch" json:"sketch,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *DetachSketchRequest) Reset()                    { *m = DetachSketchRequest{} }
+func (m *DetachSketchRequest) String() string            { return proto.CompactTextString(m) }
+func (*DetachSketchRequest) ProtoMessage()               {}
+func (*DetachSketchRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *DetachSketchRequest) GetDomain() string {
+	if m != nil && m.Domain != nil {
+		return *m.Domain
+	}
+	return ""
+}
+
+func (m *DetachSketchRequest) GetSketch() *Sketch {
+	if m != nil {
+		return m.Sketch
+	}
+	return nil
+}
+
+// RenameDomain: domain:required, newName:required
+// CloneDomain : domain:required, newName:required
+type DomainNameRequest struct {
+	Domain           *Domain `protobuf:"bytes,1,req,name=domain" json:"domain,omitempty"`
+	NewName          *string `protobuf:"bytes,2,req,name=newName" json:"newName,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *DomainNameRequest) Reset()                    { *m = DomainNameRequest{} }
+func (m *DomainNameRequest) String() string            { return proto.CompactTextString(m) }
+func (*DomainNameRequest) ProtoMessage()               {}
+func (*DomainNameRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *DomainNameRequest) GetDomain() *Domain {
+	if m != nil {
+		return m.Domain
+	}
+	return nil
+}
+
+func (m *DomainNameRequest) GetNewName() string {
+	if m != nil && m.NewName != nil {
+		return *m.NewName
+	}
+	return ""
+}
+
+// RenameSketch: sketch:required, newName:required
+// CloneSketch : sketch:required, newName:required
+type SketchNameRequest struct {
+	Sketch           *Sketch `protobuf:"bytes,1,req,name=sketch" json:"sketch,omitempty"`
+	NewName          *string `protobuf:"bytes,2,req,name=newName" json:"newName,omitempty"`
+	XXX_unrecognized []byte  `json:"-"`
+}
+
+func (m *SketchNameRequest) Reset()                    { *m = SketchNameRequest{} }
+func (m *SketchNameRequest) String() string            { return proto.CompactTextString(m) }
+func (*SketchNameRequest) ProtoMessage()               {}
+func (*SketchNameRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+func (m *SketchNameRequest) GetSketch() *Sketch {
+	if m != nil {
+		return m.Sketch
+	}
+	return nil
+}
+
+func (m *SketchNameRequest) GetNewName() string {
+	if m != nil && m.NewName != nil {
+		return *m.NewName
+	}
+	return ""
+}
+
 type AddRequest struct {
 	Domain           *Domain  `protobuf:"bytes,1,opt,name=domain" json:"domain,omitempty"`
 	Sketch           *Sketch  `protobuf:"bytes,2,opt,name=sketch" json:"sketch,omitempty"`
 	Values           []string `protobuf:"bytes,3,rep,name=values" json:"values,omitempty"`
+	Timestamp        *int64   `protobuf:"varint,4,opt,name=timestamp" json:"timestamp,omitempty"`
+	Keys             []string `protobuf:"bytes,5,rep,name=keys" json:"keys,omitempty"`
 	XXX_unrecognized []byte   `json:"-"`
 }
 
 func (m *AddRequest) Reset()                    { *m = AddRequest{} }
 func (m *AddRequest) String() string            { return proto.CompactTextString(m) }
 func (*AddRequest) ProtoMessage()               {}
-func (*AddRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+func (*AddRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
 
 func (m *AddRequest) GetDomain() *Domain {
 	if m != nil {
@@ -519,27 +812,151 @@ func (m *AddRequest) GetValues() []string {
 	return nil
 }
 
+func (m *AddRequest) GetTimestamp() int64 {
+	if m != nil && m.Timestamp != nil {
+		return *m.Timestamp
+	}
+	return 0
+}
+
+func (m *AddRequest) GetKeys() []string {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+// AddResult: sketch the values were added to, success if every value was applied,
+//            applied: number of values the sketch accepted, status/error: why it failed
+type AddResult struct {
+	Sketch           *Sketch    `protobuf:"bytes,1,req,name=sketch" json:"sketch,omitempty"`
+	Success          *bool      `protobuf:"varint,2,req,name=success" json:"success,omitempty"`
+	Applied          *int64     `protobuf:"varint,3,req,name=applied" json:"applied,omitempty"`
+	Status           *AddStatus `protobuf:"varint,4,req,name=status,enum=protobuf.AddStatus" json:"status,omitempty"`
+	Error            *string    `protobuf:"bytes,5,opt,name=error" json:"error,omitempty"`
+	XXX_unrecognized []byte     `json:"-"`
+}
+
+func (m *AddResult) Reset()                    { *m = AddResult{} }
+func (m *AddResult) String() string            { return proto.CompactTextString(m) }
+func (*AddResult) ProtoMessage()               {}
+func (*AddResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *AddResult) GetSketch() *Sketch {
+	if m != nil {
+		return m.Sketch
+	}
+	return nil
+}
+
+func (m *AddResult) GetSuccess() bool {
+	if m != nil && m.Success != nil {
+		return *m.Success
+	}
+	return false
+}
+
+func (m *AddResult) GetApplied() int64 {
+	if m != nil && m.Applied != nil {
+		return *m.Applied
+	}
+	return 0
+}
+
+func (m *AddResult) GetStatus() AddStatus {
+	if m != nil && m.Status != nil {
+		return *m.Status
+	}
+	return AddStatus_ADDED
+}
+
+func (m *AddResult) GetError() string {
+	if m != nil && m.Error != nil {
+		return *m.Error
+	}
+	return ""
+}
+
 type AddReply struct {
-	XXX_unrecognized []byte `json:"-"`
+	Results          []*AddResult `protobuf:"bytes,1,rep,name=results" json:"results,omitempty"`
+	XXX_unrecognized []byte       `json:"-"`
 }
 
 func (m *AddReply) Reset()                    { *m = AddReply{} }
 func (m *AddReply) String() string            { return proto.CompactTextString(m) }
 func (*AddReply) ProtoMessage()               {}
-func (*AddReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+func (*AddReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+
+func (m *AddReply) GetResults() []*AddResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+// AddIfAbsentRequest: values are tested and added in a single step, only MEMB
+//                     sketches support it
+type AddIfAbsentRequest struct {
+	Sketch           *Sketch  `protobuf:"bytes,1,req,name=sketch" json:"sketch,omitempty"`
+	Values           []string `protobuf:"bytes,2,rep,name=values" json:"values,omitempty"`
+	XXX_unrecognized []byte   `json:"-"`
+}
+
+func (m *AddIfAbsentRequest) Reset()                    { *m = AddIfAbsentRequest{} }
+func (m *AddIfAbsentRequest) String() string            { return proto.CompactTextString(m) }
+func (*AddIfAbsentRequest) ProtoMessage()               {}
+func (*AddIfAbsentRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+func (m *AddIfAbsentRequest) GetSketch() *Sketch {
+	if m != nil {
+		return m.Sketch
+	}
+	return nil
+}
+
+func (m *AddIfAbsentRequest) GetValues() []string {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+// AddIfAbsentReply: one membership per value, isMember is true if the value was
+//                   (probably) present before the request
+type AddIfAbsentReply struct {
+	Results          []*Membership `protobuf:"bytes,1,rep,name=results" json:"results,omitempty"`
+	XXX_unrecognized []byte        `json:"-"`
+}
+
+func (m *AddIfAbsentReply) Reset()                    { *m = AddIfAbsentReply{} }
+func (m *AddIfAbsentReply) String() string            { return proto.CompactTextString(m) }
+func (*AddIfAbsentReply) ProtoMessage()               {}
+func (*AddIfAbsentReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+
+func (m *AddIfAbsentReply) GetResults() []*Membership {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
 
 // All Sketches will be of one kind
 // All values will apply to all sketches (if card or ranking, values will be ignored)
+// confidence: of the cardinality bounds, in ]0, 1[ (default 0.95)
 type GetRequest struct {
 	Sketches         []*Sketch `protobuf:"bytes,1,rep,name=sketches" json:"sketches,omitempty"`
 	Values           []string  `protobuf:"bytes,2,rep,name=values" json:"values,omitempty"`
+	Confidence       *float32  `protobuf:"fixed32,3,opt,name=confidence" json:"confidence,omitempty"`
+	Limit            *int32    `protobuf:"varint,4,opt,name=limit" json:"limit,omitempty"`
+	Offset           *int32    `protobuf:"varint,5,opt,name=offset" json:"offset,omitempty"`
+	MinCount         *int64    `protobuf:"varint,6,opt,name=minCount" json:"minCount,omitempty"`
 	XXX_unrecognized []byte    `json:"-"`
 }
 
 func (m *GetRequest) Reset()                    { *m = GetRequest{} }
 func (m *GetRequest) String() string            { return proto.CompactTextString(m) }
 func (*GetRequest) ProtoMessage()               {}
-func (*GetRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+func (*GetRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
 
 func (m *GetRequest) GetSketches() []*Sketch {
 	if m != nil {
@@ -555,6 +972,34 @@ func (m *GetRequest) GetValues() []string {
 	return nil
 }
 
+func (m *GetRequest) GetConfidence() float32 {
+	if m != nil && m.Confidence != nil {
+		return *m.Confidence
+	}
+	return 0
+}
+
+func (m *GetRequest) GetLimit() int32 {
+	if m != nil && m.Limit != nil {
+		return *m.Limit
+	}
+	return 0
+}
+
+func (m *GetRequest) GetOffset() int32 {
+	if m != nil && m.Offset != nil {
+		return *m.Offset
+	}
+	return 0
+}
+
+func (m *GetRequest) GetMinCount() int64 {
+	if m != nil && m.MinCount != nil {
+		return *m.MinCount
+	}
+	return 0
+}
+
 type MembershipResult struct {
 	Memberships      []*Membership `protobuf:"bytes,1,rep,name=memberships" json:"memberships,omitempty"`
 	XXX_unrecognized []byte        `json:"-"`
@@ -563,7 +1008,7 @@ type MembershipResult struct {
 func (m *MembershipResult) Reset()                    { *m = MembershipResult{} }
 func (m *MembershipResult) String() string            { return proto.CompactTextString(m) }
 func (*MembershipResult) ProtoMessage()               {}
-func (*MembershipResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+func (*MembershipResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
 
 func (m *MembershipResult) GetMemberships() []*Membership {
 	if m != nil {
@@ -580,7 +1025,7 @@ type FrequencyResult struct {
 func (m *FrequencyResult) Reset()                    { *m = FrequencyResult{} }
 func (m *FrequencyResult) String() string            { return proto.CompactTextString(m) }
 func (*FrequencyResult) ProtoMessage()               {}
-func (*FrequencyResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+func (*FrequencyResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
 
 func (m *FrequencyResult) GetFrequencies() []*Frequency {
 	if m != nil {
@@ -589,15 +1034,19 @@ func (m *FrequencyResult) GetFrequencies() []*Frequency {
 	return nil
 }
 
+// lower, upper: bounds of the cardinality at the given confidence
 type CardinalityResult struct {
-	Cardinality      *int64 `protobuf:"varint,1,req,name=cardinality" json:"cardinality,omitempty"`
-	XXX_unrecognized []byte `json:"-"`
+	Cardinality      *int64   `protobuf:"varint,1,req,name=cardinality" json:"cardinality,omitempty"`
+	Lower            *int64   `protobuf:"varint,2,opt,name=lower" json:"lower,omitempty"`
+	Upper            *int64   `protobuf:"varint,3,opt,name=upper" json:"upper,omitempty"`
+	Confidence       *float32 `protobuf:"fixed32,4,opt,name=confidence" json:"confidence,omitempty"`
+	XXX_unrecognized []byte   `json:"-"`
 }
 
 func (m *CardinalityResult) Reset()                    { *m = CardinalityResult{} }
 func (m *CardinalityResult) String() string            { return proto.CompactTextString(m) }
 func (*CardinalityResult) ProtoMessage()               {}
-func (*CardinalityResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+func (*CardinalityResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
 
 func (m *CardinalityResult) GetCardinality() int64 {
 	if m != nil && m.Cardinality != nil {
@@ -606,6 +1055,27 @@ func (m *CardinalityResult) GetCardinality() int64 {
 	return 0
 }
 
+func (m *CardinalityResult) GetLower() int64 {
+	if m != nil && m.Lower != nil {
+		return *m.Lower
+	}
+	return 0
+}
+
+func (m *CardinalityResult) GetUpper() int64 {
+	if m != nil && m.Upper != nil {
+		return *m.Upper
+	}
+	return 0
+}
+
+func (m *CardinalityResult) GetConfidence() float32 {
+	if m != nil && m.Confidence != nil {
+		return *m.Confidence
+	}
+	return 0
+}
+
 type RankingsResult struct {
 	Rankings         []*Rank `protobuf:"bytes,1,rep,name=rankings" json:"rankings,omitempty"`
 	XXX_unrecognized []byte  `json:"-"`
@@ -614,7 +1084,7 @@ type RankingsResult struct {
 func (m *RankingsResult) Reset()                    { *m = RankingsResult{} }
 func (m *RankingsResult) String() string            { return proto.CompactTextString(m) }
 func (*RankingsResult) ProtoMessage()               {}
-func (*RankingsResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+func (*RankingsResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
 
 func (m *RankingsResult) GetRankings() []*Rank {
 	if m != nil {
@@ -631,7 +1101,7 @@ type GetMembershipReply struct {
 func (m *GetMembershipReply) Reset()                    { *m = GetMembershipReply{} }
 func (m *GetMembershipReply) String() string            { return proto.CompactTextString(m) }
 func (*GetMembershipReply) ProtoMessage()               {}
-func (*GetMembershipReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+func (*GetMembershipReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
 
 func (m *GetMembershipReply) GetResults() []*MembershipResult {
 	if m != nil {
@@ -648,7 +1118,7 @@ type GetFrequencyReply struct {
 func (m *GetFrequencyReply) Reset()                    { *m = GetFrequencyReply{} }
 func (m *GetFrequencyReply) String() string            { return proto.CompactTextString(m) }
 func (*GetFrequencyReply) ProtoMessage()               {}
-func (*GetFrequencyReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+func (*GetFrequencyReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
 
 func (m *GetFrequencyReply) GetResults() []*FrequencyResult {
 	if m != nil {
@@ -665,7 +1135,7 @@ type GetCardinalityReply struct {
 func (m *GetCardinalityReply) Reset()                    { *m = GetCardinalityReply{} }
 func (m *GetCardinalityReply) String() string            { return proto.CompactTextString(m) }
 func (*GetCardinalityReply) ProtoMessage()               {}
-func (*GetCardinalityReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+func (*GetCardinalityReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
 
 func (m *GetCardinalityReply) GetResults() []*CardinalityResult {
 	if m != nil {
@@ -682,7 +1152,7 @@ type GetRankingsReply struct {
 func (m *GetRankingsReply) Reset()                    { *m = GetRankingsReply{} }
 func (m *GetRankingsReply) String() string            { return proto.CompactTextString(m) }
 func (*GetRankingsReply) ProtoMessage()               {}
-func (*GetRankingsReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+func (*GetRankingsReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{32} }
 
 func (m *GetRankingsReply) GetResults() []*RankingsResult {
 	if m != nil {
@@ -691,42 +1161,667 @@ func (m *GetRankingsReply) GetResults() []*RankingsResult {
 	return nil
 }
 
-func init() {
-	proto.RegisterType((*Empty)(nil), "protobuf.Empty")
-	proto.RegisterType((*SketchProperties)(nil), "protobuf.SketchProperties")
-	proto.RegisterType((*SketchState)(nil), "protobuf.SketchState")
-	proto.RegisterType((*Domain)(nil), "protobuf.Domain")
-	proto.RegisterType((*Sketch)(nil), "protobuf.Sketch")
-	proto.RegisterType((*Membership)(nil), "protobuf.Membership")
-	proto.RegisterType((*Frequency)(nil), "protobuf.Frequency")
-	proto.RegisterType((*Rank)(nil), "protobuf.Rank")
-	proto.RegisterType((*CreateSnapshotRequest)(nil), "protobuf.CreateSnapshotRequest")
-	proto.RegisterType((*CreateSnapshotReply)(nil), "protobuf.CreateSnapshotReply")
-	proto.RegisterType((*GetSnapshotRequest)(nil), "protobuf.GetSnapshotRequest")
-	proto.RegisterType((*GetSnapshotReply)(nil), "protobuf.GetSnapshotReply")
-	proto.RegisterType((*ListRequest)(nil), "protobuf.ListRequest")
-	proto.RegisterType((*ListReply)(nil), "protobuf.ListReply")
-	proto.RegisterType((*ListDomainsReply)(nil), "protobuf.ListDomainsReply")
-	proto.RegisterType((*AddRequest)(nil), "protobuf.AddRequest")
-	proto.RegisterType((*AddReply)(nil), "protobuf.AddReply")
-	proto.RegisterType((*GetRequest)(nil), "protobuf.GetRequest")
-	proto.RegisterType((*MembershipResult)(nil), "protobuf.MembershipResult")
-	proto.RegisterType((*FrequencyResult)(nil), "protobuf.FrequencyResult")
-	proto.RegisterType((*CardinalityResult)(nil), "protobuf.CardinalityResult")
-	proto.RegisterType((*RankingsResult)(nil), "protobuf.RankingsResult")
-	proto.RegisterType((*GetMembershipReply)(nil), "protobuf.GetMembershipReply")
-	proto.RegisterType((*GetFrequencyReply)(nil), "protobuf.GetFrequencyReply")
-	proto.RegisterType((*GetCardinalityReply)(nil), "protobuf.GetCardinalityReply")
-	proto.RegisterType((*GetRankingsReply)(nil), "protobuf.GetRankingsReply")
-	proto.RegisterEnum("protobuf.SketchType", SketchType_name, SketchType_value)
-	proto.RegisterEnum("protobuf.SnapshotStatus", SnapshotStatus_name, SnapshotStatus_value)
+// RangeCountRequest: number of values between lo and hi, both included
+type RangeCountRequest struct {
+	Sketches         []*Sketch `protobuf:"bytes,1,rep,name=sketches" json:"sketches,omitempty"`
+	Lo               *int64    `protobuf:"varint,2,req,name=lo" json:"lo,omitempty"`
+	Hi               *int64    `protobuf:"varint,3,req,name=hi" json:"hi,omitempty"`
+	XXX_unrecognized []byte    `json:"-"`
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *RangeCountRequest) Reset()                    { *m = RangeCountRequest{} }
+func (m *RangeCountRequest) String() string            { return proto.CompactTextString(m) }
+func (*RangeCountRequest) ProtoMessage()               {}
+func (*RangeCountRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{33} }
 
-// Client API for Skizze service
+func (m *RangeCountRequest) GetSketches() []*Sketch {
+	if m != nil {
+		return m.Sketches
+	}
+	return nil
+}
+
+func (m *RangeCountRequest) GetLo() int64 {
+	if m != nil && m.Lo != nil {
+		return *m.Lo
+	}
+	return 0
+}
+
+func (m *RangeCountRequest) GetHi() int64 {
+	if m != nil && m.Hi != nil {
+		return *m.Hi
+	}
+	return 0
+}
+
+type RangeCountResult struct {
+	Lo               *int64 `protobuf:"varint,1,opt,name=lo" json:"lo,omitempty"`
+	Hi               *int64 `protobuf:"varint,2,opt,name=hi" json:"hi,omitempty"`
+	Count            *int64 `protobuf:"varint,3,opt,name=count" json:"count,omitempty"`
+	Error            *int64 `protobuf:"varint,4,opt,name=error" json:"error,omitempty"`
+	XXX_unrecognized []byte `json:"-"`
+}
+
+func (m *RangeCountResult) Reset()                    { *m = RangeCountResult{} }
+func (m *RangeCountResult) String() string            { return proto.CompactTextString(m) }
+func (*RangeCountResult) ProtoMessage()               {}
+func (*RangeCountResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34} }
+
+func (m *RangeCountResult) GetLo() int64 {
+	if m != nil && m.Lo != nil {
+		return *m.Lo
+	}
+	return 0
+}
+
+func (m *RangeCountResult) GetHi() int64 {
+	if m != nil && m.Hi != nil {
+		return *m.Hi
+	}
+	return 0
+}
+
+func (m *RangeCountResult) GetCount() int64 {
+	if m != nil && m.Count != nil {
+		return *m.Count
+	}
+	return 0
+}
+
+func (m *RangeCountResult) GetError() int64 {
+	if m != nil && m.Error != nil {
+		return *m.Error
+	}
+	return 0
+}
+
+type GetRangeCountReply struct {
+	Results          []*RangeCountResult `protobuf:"bytes,1,rep,name=results" json:"results,omitempty"`
+	XXX_unrecognized []byte              `json:"-"`
+}
+
+func (m *GetRangeCountReply) Reset()                    { *m = GetRangeCountReply{} }
+func (m *GetRangeCountReply) String() string            { return proto.CompactTextString(m) }
+func (*GetRangeCountReply) ProtoMessage()               {}
+func (*GetRangeCountReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{35} }
+
+func (m *GetRangeCountReply) GetResults() []*RangeCountResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+// CardinalityExpression: a leaf names a sketch or a domain (its sketch of the type
+//                        of the request), any other node applies op to its
+//                        operands. DIFFERENCE removes all further operands from
+//                        the first one.
+type CardinalityExpression struct {
+	Sketch           *Sketch                  `protobuf:"bytes,1,opt,name=sketch" json:"sketch,omitempty"`
+	Domain           *string                  `protobuf:"bytes,2,opt,name=domain" json:"domain,omitempty"`
+	Op               *SetOperation            `protobuf:"varint,3,opt,name=op,enum=protobuf.SetOperation" json:"op,omitempty"`
+	Operands         []*CardinalityExpression `protobuf:"bytes,4,rep,name=operands" json:"operands,omitempty"`
+	XXX_unrecognized []byte                   `json:"-"`
+}
+
+func (m *CardinalityExpression) Reset()                    { *m = CardinalityExpression{} }
+func (m *CardinalityExpression) String() string            { return proto.CompactTextString(m) }
+func (*CardinalityExpression) ProtoMessage()               {}
+func (*CardinalityExpression) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{36} }
+
+func (m *CardinalityExpression) GetSketch() *Sketch {
+	if m != nil {
+		return m.Sketch
+	}
+	return nil
+}
+
+func (m *CardinalityExpression) GetDomain() string {
+	if m != nil && m.Domain != nil {
+		return *m.Domain
+	}
+	return ""
+}
+
+func (m *CardinalityExpression) GetOp() SetOperation {
+	if m != nil && m.Op != nil {
+		return *m.Op
+	}
+	return SetOperation_UNION
+}
+
+func (m *CardinalityExpression) GetOperands() []*CardinalityExpression {
+	if m != nil {
+		return m.Operands
+	}
+	return nil
+}
+
+// CardinalityExpressionRequest: confidence of the bounds, in ]0, 1[ (default 0.95)
+//                               type: of the sketches, CARD or THETA (THETA sketches
+//                               intersect and subtract natively)
+type CardinalityExpressionRequest struct {
+	Expression       *CardinalityExpression `protobuf:"bytes,1,req,name=expression" json:"expression,omitempty"`
+	Confidence       *float32               `protobuf:"fixed32,2,opt,name=confidence" json:"confidence,omitempty"`
+	Type             *SketchType            `protobuf:"varint,3,opt,name=type,enum=protobuf.SketchType,def=4" json:"type,omitempty"`
+	XXX_unrecognized []byte                 `json:"-"`
+}
+
+func (m *CardinalityExpressionRequest) Reset()                    { *m = CardinalityExpressionRequest{} }
+func (m *CardinalityExpressionRequest) String() string            { return proto.CompactTextString(m) }
+func (*CardinalityExpressionRequest) ProtoMessage()               {}
+func (*CardinalityExpressionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{37} }
+
+const Default_CardinalityExpressionRequest_Type SketchType = SketchType_CARD
+
+func (m *CardinalityExpressionRequest) GetExpression() *CardinalityExpression {
+	if m != nil {
+		return m.Expression
+	}
+	return nil
+}
+
+func (m *CardinalityExpressionRequest) GetConfidence() float32 {
+	if m != nil && m.Confidence != nil {
+		return *m.Confidence
+	}
+	return 0
+}
+
+func (m *CardinalityExpressionRequest) GetType() SketchType {
+	if m != nil && m.Type != nil {
+		return *m.Type
+	}
+	return Default_CardinalityExpressionRequest_Type
+}
+
+// CardinalityExpressionReply: warning is set when the bounds are wide compared
+//                             to the estimate, e.g. for small intersections
+type CardinalityExpressionReply struct {
+	Result           *CardinalityResult `protobuf:"bytes,1,req,name=result" json:"result,omitempty"`
+	Warning          *string            `protobuf:"bytes,2,opt,name=warning" json:"warning,omitempty"`
+	XXX_unrecognized []byte             `json:"-"`
+}
+
+func (m *CardinalityExpressionReply) Reset()                    { *m = CardinalityExpressionReply{} }
+func (m *CardinalityExpressionReply) String() string            { return proto.CompactTextString(m) }
+func (*CardinalityExpressionReply) ProtoMessage()               {}
+func (*CardinalityExpressionReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{38} }
+
+func (m *CardinalityExpressionReply) GetResult() *CardinalityResult {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *CardinalityExpressionReply) GetWarning() string {
+	if m != nil && m.Warning != nil {
+		return *m.Warning
+	}
+	return ""
+}
+
+// FindSimilarRequest: sketch: MINHASH sketch to compare the others with
+//                     candidates: sketches to compare with, all MINHASH sketches of the same size if empty
+//                     limit: number of most similar sketches to return (default 10)
+//                     minSimilarity: leave out sketches less similar
+//                     lsh: only compare sketches sharing a band of their signature, which skips
+//                          most sketches with a similarity below about 0.4
+type FindSimilarRequest struct {
+	Sketch           *Sketch   `protobuf:"bytes,1,req,name=sketch" json:"sketch,omitempty"`
+	Candidates       []*Sketch `protobuf:"bytes,2,rep,name=candidates" json:"candidates,omitempty"`
+	Limit            *int32    `protobuf:"varint,3,opt,name=limit" json:"limit,omitempty"`
+	MinSimilarity    *float32  `protobuf:"fixed32,4,opt,name=minSimilarity" json:"minSimilarity,omitempty"`
+	Lsh              *bool     `protobuf:"varint,5,opt,name=lsh" json:"lsh,omitempty"`
+	XXX_unrecognized []byte    `json:"-"`
+}
+
+func (m *FindSimilarRequest) Reset()                    { *m = FindSimilarRequest{} }
+func (m *FindSimilarRequest) String() string            { return proto.CompactTextString(m) }
+func (*FindSimilarRequest) ProtoMessage()               {}
+func (*FindSimilarRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{39} }
+
+func (m *FindSimilarRequest) GetSketch() *Sketch {
+	if m != nil {
+		return m.Sketch
+	}
+	return nil
+}
+
+func (m *FindSimilarRequest) GetCandidates() []*Sketch {
+	if m != nil {
+		return m.Candidates
+	}
+	return nil
+}
+
+func (m *FindSimilarRequest) GetLimit() int32 {
+	if m != nil && m.Limit != nil {
+		return *m.Limit
+	}
+	return 0
+}
+
+func (m *FindSimilarRequest) GetMinSimilarity() float32 {
+	if m != nil && m.MinSimilarity != nil {
+		return *m.MinSimilarity
+	}
+	return 0
+}
+
+func (m *FindSimilarRequest) GetLsh() bool {
+	if m != nil && m.Lsh != nil {
+		return *m.Lsh
+	}
+	return false
+}
+
+// Similarity: estimated Jaccard similarity of the values of two sketches, error: its standard error
+type Similarity struct {
+	Sketch           *Sketch  `protobuf:"bytes,1,req,name=sketch" json:"sketch,omitempty"`
+	Similarity       *float32 `protobuf:"fixed32,2,req,name=similarity" json:"similarity,omitempty"`
+	Error            *float32 `protobuf:"fixed32,3,opt,name=error" json:"error,omitempty"`
+	XXX_unrecognized []byte   `json:"-"`
+}
+
+func (m *Similarity) Reset()                    { *m = Similarity{} }
+func (m *Similarity) String() string            { return proto.CompactTextString(m) }
+func (*Similarity) ProtoMessage()               {}
+func (*Similarity) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{40} }
+
+func (m *Similarity) GetSketch() *Sketch {
+	if m != nil {
+		return m.Sketch
+	}
+	return nil
+}
+
+func (m *Similarity) GetSimilarity() float32 {
+	if m != nil && m.Similarity != nil {
+		return *m.Similarity
+	}
+	return 0
+}
+
+func (m *Similarity) GetError() float32 {
+	if m != nil && m.Error != nil {
+		return *m.Error
+	}
+	return 0
+}
+
+type FindSimilarReply struct {
+	Results          []*Similarity `protobuf:"bytes,1,rep,name=results" json:"results,omitempty"`
+	XXX_unrecognized []byte        `json:"-"`
+}
+
+func (m *FindSimilarReply) Reset()                    { *m = FindSimilarReply{} }
+func (m *FindSimilarReply) String() string            { return proto.CompactTextString(m) }
+func (*FindSimilarReply) ProtoMessage()               {}
+func (*FindSimilarReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{41} }
+
+func (m *FindSimilarReply) GetResults() []*Similarity {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+// SampleResult: values sampled uniformly (or by weight) from the count values added
+type SampleResult struct {
+	Values           []*SampledValue `protobuf:"bytes,1,rep,name=values" json:"values,omitempty"`
+	Count            *int64          `protobuf:"varint,2,opt,name=count" json:"count,omitempty"`
+	XXX_unrecognized []byte          `json:"-"`
+}
+
+func (m *SampleResult) Reset()                    { *m = SampleResult{} }
+func (m *SampleResult) String() string            { return proto.CompactTextString(m) }
+func (*SampleResult) ProtoMessage()               {}
+func (*SampleResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{42} }
+
+func (m *SampleResult) GetValues() []*SampledValue {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+func (m *SampleResult) GetCount() int64 {
+	if m != nil && m.Count != nil {
+		return *m.Count
+	}
+	return 0
+}
+
+type SampledValue struct {
+	Value            *string  `protobuf:"bytes,1,req,name=value" json:"value,omitempty"`
+	Weight           *float32 `protobuf:"fixed32,2,opt,name=weight" json:"weight,omitempty"`
+	XXX_unrecognized []byte   `json:"-"`
+}
+
+func (m *SampledValue) Reset()                    { *m = SampledValue{} }
+func (m *SampledValue) String() string            { return proto.CompactTextString(m) }
+func (*SampledValue) ProtoMessage()               {}
+func (*SampledValue) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{43} }
+
+func (m *SampledValue) GetValue() string {
+	if m != nil && m.Value != nil {
+		return *m.Value
+	}
+	return ""
+}
+
+func (m *SampledValue) GetWeight() float32 {
+	if m != nil && m.Weight != nil {
+		return *m.Weight
+	}
+	return 0
+}
+
+type GetSampleReply struct {
+	Results          []*SampleResult `protobuf:"bytes,1,rep,name=results" json:"results,omitempty"`
+	XXX_unrecognized []byte          `json:"-"`
+}
+
+func (m *GetSampleReply) Reset()                    { *m = GetSampleReply{} }
+func (m *GetSampleReply) String() string            { return proto.CompactTextString(m) }
+func (*GetSampleReply) ProtoMessage()               {}
+func (*GetSampleReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{44} }
+
+func (m *GetSampleReply) GetResults() []*SampleResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+// GroupsRequest: results of the groups of a KCARD or KRANK sketch named by keys, or of the
+//                limit groups (10 by default) with the largest cardinality if keys is empty
+type GroupsRequest struct {
+	Sketch           *Sketch  `protobuf:"bytes,1,req,name=sketch" json:"sketch,omitempty"`
+	Keys             []string `protobuf:"bytes,2,rep,name=keys" json:"keys,omitempty"`
+	Limit            *int32   `protobuf:"varint,3,opt,name=limit" json:"limit,omitempty"`
+	Confidence       *float32 `protobuf:"fixed32,4,opt,name=confidence" json:"confidence,omitempty"`
+	XXX_unrecognized []byte   `json:"-"`
+}
+
+func (m *GroupsRequest) Reset()                    { *m = GroupsRequest{} }
+func (m *GroupsRequest) String() string            { return proto.CompactTextString(m) }
+func (*GroupsRequest) ProtoMessage()               {}
+func (*GroupsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{45} }
+
+func (m *GroupsRequest) GetSketch() *Sketch {
+	if m != nil {
+		return m.Sketch
+	}
+	return nil
+}
+
+func (m *GroupsRequest) GetKeys() []string {
+	if m != nil {
+		return m.Keys
+	}
+	return nil
+}
+
+func (m *GroupsRequest) GetLimit() int32 {
+	if m != nil && m.Limit != nil {
+		return *m.Limit
+	}
+	return 0
+}
+
+func (m *GroupsRequest) GetConfidence() float32 {
+	if m != nil && m.Confidence != nil {
+		return *m.Confidence
+	}
+	return 0
+}
+
+type GroupResult struct {
+	Key              *string            `protobuf:"bytes,1,req,name=key" json:"key,omitempty"`
+	Cardinality      *CardinalityResult `protobuf:"bytes,2,opt,name=cardinality" json:"cardinality,omitempty"`
+	Rankings         *RankingsResult    `protobuf:"bytes,3,opt,name=rankings" json:"rankings,omitempty"`
+	XXX_unrecognized []byte             `json:"-"`
+}
+
+func (m *GroupResult) Reset()                    { *m = GroupResult{} }
+func (m *GroupResult) String() string            { return proto.CompactTextString(m) }
+func (*GroupResult) ProtoMessage()               {}
+func (*GroupResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{46} }
+
+func (m *GroupResult) GetKey() string {
+	if m != nil && m.Key != nil {
+		return *m.Key
+	}
+	return ""
+}
+
+func (m *GroupResult) GetCardinality() *CardinalityResult {
+	if m != nil {
+		return m.Cardinality
+	}
+	return nil
+}
+
+func (m *GroupResult) GetRankings() *RankingsResult {
+	if m != nil {
+		return m.Rankings
+	}
+	return nil
+}
+
+type GetGroupsReply struct {
+	Results          []*GroupResult `protobuf:"bytes,1,rep,name=results" json:"results,omitempty"`
+	Groups           *int64         `protobuf:"varint,2,opt,name=groups" json:"groups,omitempty"`
+	XXX_unrecognized []byte         `json:"-"`
+}
+
+func (m *GetGroupsReply) Reset()                    { *m = GetGroupsReply{} }
+func (m *GetGroupsReply) String() string            { return proto.CompactTextString(m) }
+func (*GetGroupsReply) ProtoMessage()               {}
+func (*GetGroupsReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{47} }
+
+func (m *GetGroupsReply) GetResults() []*GroupResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+func (m *GetGroupsReply) GetGroups() int64 {
+	if m != nil && m.Groups != nil {
+		return *m.Groups
+	}
+	return 0
+}
+
+// QueryDomain: name:required, values:optional (apply to the MEMB and FREQ sketches)
+type QueryDomainRequest struct {
+	Name             *string  `protobuf:"bytes,1,req,name=name" json:"name,omitempty"`
+	Values           []string `protobuf:"bytes,2,rep,name=values" json:"values,omitempty"`
+	Confidence       *float32 `protobuf:"fixed32,3,opt,name=confidence" json:"confidence,omitempty"`
+	XXX_unrecognized []byte   `json:"-"`
+}
+
+func (m *QueryDomainRequest) Reset()                    { *m = QueryDomainRequest{} }
+func (m *QueryDomainRequest) String() string            { return proto.CompactTextString(m) }
+func (*QueryDomainRequest) ProtoMessage()               {}
+func (*QueryDomainRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{48} }
+
+func (m *QueryDomainRequest) GetName() string {
+	if m != nil && m.Name != nil {
+		return *m.Name
+	}
+	return ""
+}
+
+func (m *QueryDomainRequest) GetValues() []string {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+func (m *QueryDomainRequest) GetConfidence() float32 {
+	if m != nil && m.Confidence != nil {
+		return *m.Confidence
+	}
+	return 0
+}
+
+// Only the result matching the type of the sketch is set
+type QueryResult struct {
+	Sketch           *Sketch            `protobuf:"bytes,1,req,name=sketch" json:"sketch,omitempty"`
+	Cardinality      *CardinalityResult `protobuf:"bytes,2,opt,name=cardinality" json:"cardinality,omitempty"`
+	Rankings         *RankingsResult    `protobuf:"bytes,3,opt,name=rankings" json:"rankings,omitempty"`
+	Frequency        *FrequencyResult   `protobuf:"bytes,4,opt,name=frequency" json:"frequency,omitempty"`
+	Membership       *MembershipResult  `protobuf:"bytes,5,opt,name=membership" json:"membership,omitempty"`
+	Sample           *SampleResult      `protobuf:"bytes,6,opt,name=sample" json:"sample,omitempty"`
+	XXX_unrecognized []byte             `json:"-"`
+}
+
+func (m *QueryResult) Reset()                    { *m = QueryResult{} }
+func (m *QueryResult) String() string            { return proto.CompactTextString(m) }
+func (*QueryResult) ProtoMessage()               {}
+func (*QueryResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{49} }
+
+func (m *QueryResult) GetSketch() *Sketch {
+	if m != nil {
+		return m.Sketch
+	}
+	return nil
+}
+
+func (m *QueryResult) GetCardinality() *CardinalityResult {
+	if m != nil {
+		return m.Cardinality
+	}
+	return nil
+}
+
+func (m *QueryResult) GetRankings() *RankingsResult {
+	if m != nil {
+		return m.Rankings
+	}
+	return nil
+}
+
+func (m *QueryResult) GetFrequency() *FrequencyResult {
+	if m != nil {
+		return m.Frequency
+	}
+	return nil
+}
+
+func (m *QueryResult) GetMembership() *MembershipResult {
+	if m != nil {
+		return m.Membership
+	}
+	return nil
+}
+
+func (m *QueryResult) GetSample() *SampleResult {
+	if m != nil {
+		return m.Sample
+	}
+	return nil
+}
+
+type QueryDomainReply struct {
+	Name             *string        `protobuf:"bytes,1,req,name=name" json:"name,omitempty"`
+	Results          []*QueryResult `protobuf:"bytes,2,rep,name=results" json:"results,omitempty"`
+	XXX_unrecognized []byte         `json:"-"`
+}
+
+func (m *QueryDomainReply) Reset()                    { *m = QueryDomainReply{} }
+func (m *QueryDomainReply) String() string            { return proto.CompactTextString(m) }
+func (*QueryDomainReply) ProtoMessage()               {}
+func (*QueryDomainReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{50} }
+
+func (m *QueryDomainReply) GetName() string {
+	if m != nil && m.Name != nil {
+		return *m.Name
+	}
+	return ""
+}
+
+func (m *QueryDomainReply) GetResults() []*QueryResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Empty)(nil), "protobuf.Empty")
+	proto.RegisterType((*SketchProperties)(nil), "protobuf.SketchProperties")
+	proto.RegisterType((*SketchState)(nil), "protobuf.SketchState")
+	proto.RegisterType((*Domain)(nil), "protobuf.Domain")
+	proto.RegisterType((*Sketch)(nil), "protobuf.Sketch")
+	proto.RegisterType((*Membership)(nil), "protobuf.Membership")
+	proto.RegisterType((*Frequency)(nil), "protobuf.Frequency")
+	proto.RegisterType((*Rank)(nil), "protobuf.Rank")
+	proto.RegisterType((*CreateSnapshotRequest)(nil), "protobuf.CreateSnapshotRequest")
+	proto.RegisterType((*CreateSnapshotReply)(nil), "protobuf.CreateSnapshotReply")
+	proto.RegisterType((*GetSnapshotRequest)(nil), "protobuf.GetSnapshotRequest")
+	proto.RegisterType((*GetSnapshotReply)(nil), "protobuf.GetSnapshotReply")
+	proto.RegisterType((*ListRequest)(nil), "protobuf.ListRequest")
+	proto.RegisterType((*ListReply)(nil), "protobuf.ListReply")
+	proto.RegisterType((*ListDomainsReply)(nil), "protobuf.ListDomainsReply")
+	proto.RegisterType((*AttachSketchRequest)(nil), "protobuf.AttachSketchRequest")
+	proto.RegisterType((*DetachSketchRequest)(nil), "protobuf.DetachSketchRequest")
+	proto.RegisterType((*DomainNameRequest)(nil), "protobuf.DomainNameRequest")
+	proto.RegisterType((*SketchNameRequest)(nil), "protobuf.SketchNameRequest")
+	proto.RegisterType((*AddRequest)(nil), "protobuf.AddRequest")
+	proto.RegisterType((*AddResult)(nil), "protobuf.AddResult")
+	proto.RegisterType((*AddReply)(nil), "protobuf.AddReply")
+	proto.RegisterType((*AddIfAbsentRequest)(nil), "protobuf.AddIfAbsentRequest")
+	proto.RegisterType((*AddIfAbsentReply)(nil), "protobuf.AddIfAbsentReply")
+	proto.RegisterType((*GetRequest)(nil), "protobuf.GetRequest")
+	proto.RegisterType((*MembershipResult)(nil), "protobuf.MembershipResult")
+	proto.RegisterType((*FrequencyResult)(nil), "protobuf.FrequencyResult")
+	proto.RegisterType((*CardinalityResult)(nil), "protobuf.CardinalityResult")
+	proto.RegisterType((*RankingsResult)(nil), "protobuf.RankingsResult")
+	proto.RegisterType((*GetMembershipReply)(nil), "protobuf.GetMembershipReply")
+	proto.RegisterType((*GetFrequencyReply)(nil), "protobuf.GetFrequencyReply")
+	proto.RegisterType((*GetCardinalityReply)(nil), "protobuf.GetCardinalityReply")
+	proto.RegisterType((*GetRankingsReply)(nil), "protobuf.GetRankingsReply")
+	proto.RegisterType((*RangeCountRequest)(nil), "protobuf.RangeCountRequest")
+	proto.RegisterType((*RangeCountResult)(nil), "protobuf.RangeCountResult")
+	proto.RegisterType((*GetRangeCountReply)(nil), "protobuf.GetRangeCountReply")
+	proto.RegisterType((*CardinalityExpression)(nil), "protobuf.CardinalityExpression")
+	proto.RegisterType((*CardinalityExpressionRequest)(nil), "protobuf.CardinalityExpressionRequest")
+	proto.RegisterType((*CardinalityExpressionReply)(nil), "protobuf.CardinalityExpressionReply")
+	proto.RegisterType((*FindSimilarRequest)(nil), "protobuf.FindSimilarRequest")
+	proto.RegisterType((*Similarity)(nil), "protobuf.Similarity")
+	proto.RegisterType((*FindSimilarReply)(nil), "protobuf.FindSimilarReply")
+	proto.RegisterType((*SampleResult)(nil), "protobuf.SampleResult")
+	proto.RegisterType((*SampledValue)(nil), "protobuf.SampledValue")
+	proto.RegisterType((*GetSampleReply)(nil), "protobuf.GetSampleReply")
+	proto.RegisterType((*GroupsRequest)(nil), "protobuf.GroupsRequest")
+	proto.RegisterType((*GroupResult)(nil), "protobuf.GroupResult")
+	proto.RegisterType((*GetGroupsReply)(nil), "protobuf.GetGroupsReply")
+	proto.RegisterType((*QueryDomainRequest)(nil), "protobuf.QueryDomainRequest")
+	proto.RegisterType((*QueryResult)(nil), "protobuf.QueryResult")
+	proto.RegisterType((*QueryDomainReply)(nil), "protobuf.QueryDomainReply")
+	proto.RegisterEnum("protobuf.SketchType", SketchType_name, SketchType_value)
+	proto.RegisterEnum("protobuf.SetOperation", SetOperation_name, SetOperation_value)
+	proto.RegisterEnum("protobuf.SnapshotStatus", SnapshotStatus_name, SnapshotStatus_value)
+	proto.RegisterEnum("protobuf.AddStatus", AddStatus_name, AddStatus_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for Skizze service
 
 type SkizzeClient interface {
 	CreateSnapshot(ctx context.Context, in *CreateSnapshotRequest, opts ...grpc.CallOption) (*CreateSnapshotReply, error)
@@ -737,14 +1832,29 @@ type SkizzeClient interface {
 	CreateDomain(ctx context.Context, in *Domain, opts ...grpc.CallOption) (*Domain, error)
 	DeleteDomain(ctx context.Context, in *Domain, opts ...grpc.CallOption) (*Empty, error)
 	GetDomain(ctx context.Context, in *Domain, opts ...grpc.CallOption) (*Domain, error)
+	AttachSketch(ctx context.Context, in *AttachSketchRequest, opts ...grpc.CallOption) (*Domain, error)
+	DetachSketch(ctx context.Context, in *DetachSketchRequest, opts ...grpc.CallOption) (*Domain, error)
+	ClearDomain(ctx context.Context, in *Domain, opts ...grpc.CallOption) (*Empty, error)
+	RenameDomain(ctx context.Context, in *DomainNameRequest, opts ...grpc.CallOption) (*Domain, error)
+	CloneDomain(ctx context.Context, in *DomainNameRequest, opts ...grpc.CallOption) (*Domain, error)
 	CreateSketch(ctx context.Context, in *Sketch, opts ...grpc.CallOption) (*Sketch, error)
 	DeleteSketch(ctx context.Context, in *Sketch, opts ...grpc.CallOption) (*Empty, error)
 	GetSketch(ctx context.Context, in *Sketch, opts ...grpc.CallOption) (*Sketch, error)
+	ClearSketch(ctx context.Context, in *Sketch, opts ...grpc.CallOption) (*Empty, error)
+	RenameSketch(ctx context.Context, in *SketchNameRequest, opts ...grpc.CallOption) (*Sketch, error)
+	CloneSketch(ctx context.Context, in *SketchNameRequest, opts ...grpc.CallOption) (*Sketch, error)
 	Add(ctx context.Context, in *AddRequest, opts ...grpc.CallOption) (*AddReply, error)
+	AddIfAbsent(ctx context.Context, in *AddIfAbsentRequest, opts ...grpc.CallOption) (*AddIfAbsentReply, error)
 	GetMembership(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetMembershipReply, error)
 	GetFrequency(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetFrequencyReply, error)
 	GetCardinality(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetCardinalityReply, error)
 	GetRankings(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetRankingsReply, error)
+	GetRangeCount(ctx context.Context, in *RangeCountRequest, opts ...grpc.CallOption) (*GetRangeCountReply, error)
+	GetCardinalityExpression(ctx context.Context, in *CardinalityExpressionRequest, opts ...grpc.CallOption) (*CardinalityExpressionReply, error)
+	FindSimilar(ctx context.Context, in *FindSimilarRequest, opts ...grpc.CallOption) (*FindSimilarReply, error)
+	GetSample(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetSampleReply, error)
+	GetGroups(ctx context.Context, in *GroupsRequest, opts ...grpc.CallOption) (*GetGroupsReply, error)
+	QueryDomain(ctx context.Context, in *QueryDomainRequest, opts ...grpc.CallOption) (*QueryDomainReply, error)
 }
 
 type skizzeClient struct {
@@ -818,81 +1928,216 @@ func (c *skizzeClient) DeleteDomain(ctx context.Context, in *Domain, opts ...grp
 	return out, nil
 }
 
-func (c *skizzeClient) GetDomain(ctx context.Context, in *Domain, opts ...grpc.CallOption) (*Domain, error) {
-	out := new(Domain)
-	err := grpc.Invoke(ctx, "/protobuf.Skizze/GetDomain", in, out, c.cc, opts...)
+func (c *skizzeClient) GetDomain(ctx context.Context, in *Domain, opts ...grpc.CallOption) (*Domain, error) {
+	out := new(Domain)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/GetDomain", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skizzeClient) AttachSketch(ctx context.Context, in *AttachSketchRequest, opts ...grpc.CallOption) (*Domain, error) {
+	out := new(Domain)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/AttachSketch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skizzeClient) DetachSketch(ctx context.Context, in *DetachSketchRequest, opts ...grpc.CallOption) (*Domain, error) {
+	out := new(Domain)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/DetachSketch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skizzeClient) ClearDomain(ctx context.Context, in *Domain, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/ClearDomain", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skizzeClient) RenameDomain(ctx context.Context, in *DomainNameRequest, opts ...grpc.CallOption) (*Domain, error) {
+	out := new(Domain)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/RenameDomain", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skizzeClient) CloneDomain(ctx context.Context, in *DomainNameRequest, opts ...grpc.CallOption) (*Domain, error) {
+	out := new(Domain)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/CloneDomain", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skizzeClient) CreateSketch(ctx context.Context, in *Sketch, opts ...grpc.CallOption) (*Sketch, error) {
+	out := new(Sketch)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/CreateSketch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skizzeClient) DeleteSketch(ctx context.Context, in *Sketch, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/DeleteSketch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skizzeClient) GetSketch(ctx context.Context, in *Sketch, opts ...grpc.CallOption) (*Sketch, error) {
+	out := new(Sketch)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/GetSketch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skizzeClient) ClearSketch(ctx context.Context, in *Sketch, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/ClearSketch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skizzeClient) RenameSketch(ctx context.Context, in *SketchNameRequest, opts ...grpc.CallOption) (*Sketch, error) {
+	out := new(Sketch)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/RenameSketch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skizzeClient) CloneSketch(ctx context.Context, in *SketchNameRequest, opts ...grpc.CallOption) (*Sketch, error) {
+	out := new(Sketch)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/CloneSketch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skizzeClient) Add(ctx context.Context, in *AddRequest, opts ...grpc.CallOption) (*AddReply, error) {
+	out := new(AddReply)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/Add", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skizzeClient) AddIfAbsent(ctx context.Context, in *AddIfAbsentRequest, opts ...grpc.CallOption) (*AddIfAbsentReply, error) {
+	out := new(AddIfAbsentReply)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/AddIfAbsent", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skizzeClient) GetMembership(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetMembershipReply, error) {
+	out := new(GetMembershipReply)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/GetMembership", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skizzeClient) GetFrequency(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetFrequencyReply, error) {
+	out := new(GetFrequencyReply)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/GetFrequency", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *skizzeClient) CreateSketch(ctx context.Context, in *Sketch, opts ...grpc.CallOption) (*Sketch, error) {
-	out := new(Sketch)
-	err := grpc.Invoke(ctx, "/protobuf.Skizze/CreateSketch", in, out, c.cc, opts...)
+func (c *skizzeClient) GetCardinality(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetCardinalityReply, error) {
+	out := new(GetCardinalityReply)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/GetCardinality", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *skizzeClient) DeleteSketch(ctx context.Context, in *Sketch, opts ...grpc.CallOption) (*Empty, error) {
-	out := new(Empty)
-	err := grpc.Invoke(ctx, "/protobuf.Skizze/DeleteSketch", in, out, c.cc, opts...)
+func (c *skizzeClient) GetRankings(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetRankingsReply, error) {
+	out := new(GetRankingsReply)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/GetRankings", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *skizzeClient) GetSketch(ctx context.Context, in *Sketch, opts ...grpc.CallOption) (*Sketch, error) {
-	out := new(Sketch)
-	err := grpc.Invoke(ctx, "/protobuf.Skizze/GetSketch", in, out, c.cc, opts...)
+func (c *skizzeClient) GetRangeCount(ctx context.Context, in *RangeCountRequest, opts ...grpc.CallOption) (*GetRangeCountReply, error) {
+	out := new(GetRangeCountReply)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/GetRangeCount", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *skizzeClient) Add(ctx context.Context, in *AddRequest, opts ...grpc.CallOption) (*AddReply, error) {
-	out := new(AddReply)
-	err := grpc.Invoke(ctx, "/protobuf.Skizze/Add", in, out, c.cc, opts...)
+func (c *skizzeClient) GetCardinalityExpression(ctx context.Context, in *CardinalityExpressionRequest, opts ...grpc.CallOption) (*CardinalityExpressionReply, error) {
+	out := new(CardinalityExpressionReply)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/GetCardinalityExpression", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *skizzeClient) GetMembership(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetMembershipReply, error) {
-	out := new(GetMembershipReply)
-	err := grpc.Invoke(ctx, "/protobuf.Skizze/GetMembership", in, out, c.cc, opts...)
+func (c *skizzeClient) FindSimilar(ctx context.Context, in *FindSimilarRequest, opts ...grpc.CallOption) (*FindSimilarReply, error) {
+	out := new(FindSimilarReply)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/FindSimilar", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *skizzeClient) GetFrequency(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetFrequencyReply, error) {
-	out := new(GetFrequencyReply)
-	err := grpc.Invoke(ctx, "/protobuf.Skizze/GetFrequency", in, out, c.cc, opts...)
+func (c *skizzeClient) GetSample(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetSampleReply, error) {
+	out := new(GetSampleReply)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/GetSample", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *skizzeClient) GetCardinality(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetCardinalityReply, error) {
-	out := new(GetCardinalityReply)
-	err := grpc.Invoke(ctx, "/protobuf.Skizze/GetCardinality", in, out, c.cc, opts...)
+func (c *skizzeClient) GetGroups(ctx context.Context, in *GroupsRequest, opts ...grpc.CallOption) (*GetGroupsReply, error) {
+	out := new(GetGroupsReply)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/GetGroups", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *skizzeClient) GetRankings(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetRankingsReply, error) {
-	out := new(GetRankingsReply)
-	err := grpc.Invoke(ctx, "/protobuf.Skizze/GetRankings", in, out, c.cc, opts...)
+func (c *skizzeClient) QueryDomain(ctx context.Context, in *QueryDomainRequest, opts ...grpc.CallOption) (*QueryDomainReply, error) {
+	out := new(QueryDomainReply)
+	err := grpc.Invoke(ctx, "/protobuf.Skizze/QueryDomain", in, out, c.cc, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -910,210 +2155,591 @@ type SkizzeServer interface {
 	CreateDomain(context.Context, *Domain) (*Domain, error)
 	DeleteDomain(context.Context, *Domain) (*Empty, error)
 	GetDomain(context.Context, *Domain) (*Domain, error)
+	AttachSketch(context.Context, *AttachSketchRequest) (*Domain, error)
+	DetachSketch(context.Context, *DetachSketchRequest) (*Domain, error)
+	ClearDomain(context.Context, *Domain) (*Empty, error)
+	RenameDomain(context.Context, *DomainNameRequest) (*Domain, error)
+	CloneDomain(context.Context, *DomainNameRequest) (*Domain, error)
 	CreateSketch(context.Context, *Sketch) (*Sketch, error)
 	DeleteSketch(context.Context, *Sketch) (*Empty, error)
 	GetSketch(context.Context, *Sketch) (*Sketch, error)
+	ClearSketch(context.Context, *Sketch) (*Empty, error)
+	RenameSketch(context.Context, *SketchNameRequest) (*Sketch, error)
+	CloneSketch(context.Context, *SketchNameRequest) (*Sketch, error)
 	Add(context.Context, *AddRequest) (*AddReply, error)
+	AddIfAbsent(context.Context, *AddIfAbsentRequest) (*AddIfAbsentReply, error)
 	GetMembership(context.Context, *GetRequest) (*GetMembershipReply, error)
 	GetFrequency(context.Context, *GetRequest) (*GetFrequencyReply, error)
 	GetCardinality(context.Context, *GetRequest) (*GetCardinalityReply, error)
 	GetRankings(context.Context, *GetRequest) (*GetRankingsReply, error)
+	GetRangeCount(context.Context, *RangeCountRequest) (*GetRangeCountReply, error)
+	GetCardinalityExpression(context.Context, *CardinalityExpressionRequest) (*CardinalityExpressionReply, error)
+	FindSimilar(context.Context, *FindSimilarRequest) (*FindSimilarReply, error)
+	GetSample(context.Context, *GetRequest) (*GetSampleReply, error)
+	GetGroups(context.Context, *GroupsRequest) (*GetGroupsReply, error)
+	QueryDomain(context.Context, *QueryDomainRequest) (*QueryDomainReply, error)
 }
 
 func RegisterSkizzeServer(s *grpc.Server, srv SkizzeServer) {
 	s.RegisterService(&_Skizze_serviceDesc, srv)
 }
 
-func _Skizze_CreateSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+func _Skizze_CreateSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreateSnapshotRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(SkizzeServer).CreateSnapshot(ctx, in)
-	if err != nil {
-		return nil, err
+	if interceptor == nil {
+		return srv.(SkizzeServer).CreateSnapshot(ctx, in)
 	}
-	return out, nil
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/CreateSnapshot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).CreateSnapshot(ctx, req.(*CreateSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Skizze_GetSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+func _Skizze_GetSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetSnapshotRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(SkizzeServer).GetSnapshot(ctx, in)
-	if err != nil {
-		return nil, err
+	if interceptor == nil {
+		return srv.(SkizzeServer).GetSnapshot(ctx, in)
 	}
-	return out, nil
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/GetSnapshot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).GetSnapshot(ctx, req.(*GetSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Skizze_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+func _Skizze_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ListRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(SkizzeServer).List(ctx, in)
-	if err != nil {
-		return nil, err
+	if interceptor == nil {
+		return srv.(SkizzeServer).List(ctx, in)
 	}
-	return out, nil
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/List",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Skizze_ListAll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+func _Skizze_ListAll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(SkizzeServer).ListAll(ctx, in)
-	if err != nil {
-		return nil, err
+	if interceptor == nil {
+		return srv.(SkizzeServer).ListAll(ctx, in)
 	}
-	return out, nil
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/ListAll",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).ListAll(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Skizze_ListDomains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+func _Skizze_ListDomains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(SkizzeServer).ListDomains(ctx, in)
-	if err != nil {
-		return nil, err
+	if interceptor == nil {
+		return srv.(SkizzeServer).ListDomains(ctx, in)
 	}
-	return out, nil
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/ListDomains",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).ListDomains(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Skizze_CreateDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+func _Skizze_CreateDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Domain)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(SkizzeServer).CreateDomain(ctx, in)
-	if err != nil {
+	if interceptor == nil {
+		return srv.(SkizzeServer).CreateDomain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/CreateDomain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).CreateDomain(ctx, req.(*Domain))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Skizze_DeleteDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Domain)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(SkizzeServer).DeleteDomain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/DeleteDomain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).DeleteDomain(ctx, req.(*Domain))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Skizze_DeleteDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+func _Skizze_GetDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Domain)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(SkizzeServer).DeleteDomain(ctx, in)
-	if err != nil {
+	if interceptor == nil {
+		return srv.(SkizzeServer).GetDomain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/GetDomain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).GetDomain(ctx, req.(*Domain))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Skizze_AttachSketch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttachSketchRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(SkizzeServer).AttachSketch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/AttachSketch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).AttachSketch(ctx, req.(*AttachSketchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Skizze_DetachSketch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DetachSketchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SkizzeServer).DetachSketch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/DetachSketch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).DetachSketch(ctx, req.(*DetachSketchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Skizze_GetDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+func _Skizze_ClearDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Domain)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(SkizzeServer).GetDomain(ctx, in)
-	if err != nil {
+	if interceptor == nil {
+		return srv.(SkizzeServer).ClearDomain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/ClearDomain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).ClearDomain(ctx, req.(*Domain))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Skizze_RenameDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DomainNameRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(SkizzeServer).RenameDomain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/RenameDomain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).RenameDomain(ctx, req.(*DomainNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Skizze_CreateSketch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
-	in := new(Sketch)
+func _Skizze_CloneDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DomainNameRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(SkizzeServer).CreateSketch(ctx, in)
-	if err != nil {
+	if interceptor == nil {
+		return srv.(SkizzeServer).CloneDomain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/CloneDomain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).CloneDomain(ctx, req.(*DomainNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Skizze_CreateSketch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Sketch)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(SkizzeServer).CreateSketch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/CreateSketch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).CreateSketch(ctx, req.(*Sketch))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Skizze_DeleteSketch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+func _Skizze_DeleteSketch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Sketch)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(SkizzeServer).DeleteSketch(ctx, in)
-	if err != nil {
+	if interceptor == nil {
+		return srv.(SkizzeServer).DeleteSketch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/DeleteSketch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).DeleteSketch(ctx, req.(*Sketch))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Skizze_GetSketch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Sketch)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(SkizzeServer).GetSketch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/GetSketch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).GetSketch(ctx, req.(*Sketch))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Skizze_GetSketch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+func _Skizze_ClearSketch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Sketch)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(SkizzeServer).GetSketch(ctx, in)
-	if err != nil {
+	if interceptor == nil {
+		return srv.(SkizzeServer).ClearSketch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/ClearSketch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).ClearSketch(ctx, req.(*Sketch))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Skizze_RenameSketch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SketchNameRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(SkizzeServer).RenameSketch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/RenameSketch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).RenameSketch(ctx, req.(*SketchNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Skizze_CloneSketch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SketchNameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SkizzeServer).CloneSketch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/CloneSketch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).CloneSketch(ctx, req.(*SketchNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Skizze_Add_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+func _Skizze_Add_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(AddRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(SkizzeServer).Add(ctx, in)
-	if err != nil {
+	if interceptor == nil {
+		return srv.(SkizzeServer).Add(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/Add",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).Add(ctx, req.(*AddRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Skizze_AddIfAbsent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddIfAbsentRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(SkizzeServer).AddIfAbsent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/AddIfAbsent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).AddIfAbsent(ctx, req.(*AddIfAbsentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Skizze_GetMembership_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+func _Skizze_GetMembership_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(SkizzeServer).GetMembership(ctx, in)
-	if err != nil {
-		return nil, err
+	if interceptor == nil {
+		return srv.(SkizzeServer).GetMembership(ctx, in)
 	}
-	return out, nil
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/GetMembership",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).GetMembership(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Skizze_GetFrequency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+func _Skizze_GetFrequency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(SkizzeServer).GetFrequency(ctx, in)
-	if err != nil {
+	if interceptor == nil {
+		return srv.(SkizzeServer).GetFrequency(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/GetFrequency",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).GetFrequency(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Skizze_GetCardinality_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(SkizzeServer).GetCardinality(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/GetCardinality",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).GetCardinality(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Skizze_GetCardinality_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+func _Skizze_GetRankings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(SkizzeServer).GetCardinality(ctx, in)
-	if err != nil {
+	if interceptor == nil {
+		return srv.(SkizzeServer).GetRankings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/GetRankings",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).GetRankings(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Skizze_GetRangeCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RangeCountRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(SkizzeServer).GetRangeCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/GetRangeCount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).GetRangeCount(ctx, req.(*RangeCountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Skizze_GetCardinalityExpression_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CardinalityExpressionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SkizzeServer).GetCardinalityExpression(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/GetCardinalityExpression",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).GetCardinalityExpression(ctx, req.(*CardinalityExpressionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Skizze_FindSimilar_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindSimilarRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SkizzeServer).FindSimilar(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/FindSimilar",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).FindSimilar(ctx, req.(*FindSimilarRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _Skizze_GetRankings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error) (interface{}, error) {
+func _Skizze_GetSample_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
-	out, err := srv.(SkizzeServer).GetRankings(ctx, in)
-	if err != nil {
+	if interceptor == nil {
+		return srv.(SkizzeServer).GetSample(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/GetSample",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).GetSample(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Skizze_GetGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroupsRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(SkizzeServer).GetGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/GetGroups",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).GetGroups(ctx, req.(*GroupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Skizze_QueryDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryDomainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SkizzeServer).QueryDomain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protobuf.Skizze/QueryDomain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkizzeServer).QueryDomain(ctx, req.(*QueryDomainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
 var _Skizze_serviceDesc = grpc.ServiceDesc{
@@ -1152,6 +2778,26 @@ var _Skizze_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetDomain",
 			Handler:    _Skizze_GetDomain_Handler,
 		},
+		{
+			MethodName: "AttachSketch",
+			Handler:    _Skizze_AttachSketch_Handler,
+		},
+		{
+			MethodName: "DetachSketch",
+			Handler:    _Skizze_DetachSketch_Handler,
+		},
+		{
+			MethodName: "ClearDomain",
+			Handler:    _Skizze_ClearDomain_Handler,
+		},
+		{
+			MethodName: "RenameDomain",
+			Handler:    _Skizze_RenameDomain_Handler,
+		},
+		{
+			MethodName: "CloneDomain",
+			Handler:    _Skizze_CloneDomain_Handler,
+		},
 		{
 			MethodName: "CreateSketch",
 			Handler:    _Skizze_CreateSketch_Handler,
@@ -1164,10 +2810,26 @@ var _Skizze_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetSketch",
 			Handler:    _Skizze_GetSketch_Handler,
 		},
+		{
+			MethodName: "ClearSketch",
+			Handler:    _Skizze_ClearSketch_Handler,
+		},
+		{
+			MethodName: "RenameSketch",
+			Handler:    _Skizze_RenameSketch_Handler,
+		},
+		{
+			MethodName: "CloneSketch",
+			Handler:    _Skizze_CloneSketch_Handler,
+		},
 		{
 			MethodName: "Add",
 			Handler:    _Skizze_Add_Handler,
 		},
+		{
+			MethodName: "AddIfAbsent",
+			Handler:    _Skizze_AddIfAbsent_Handler,
+		},
 		{
 			MethodName: "GetMembership",
 			Handler:    _Skizze_GetMembership_Handler,
@@ -1184,75 +2846,179 @@ var _Skizze_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetRankings",
 			Handler:    _Skizze_GetRankings_Handler,
 		},
+		{
+			MethodName: "GetRangeCount",
+			Handler:    _Skizze_GetRangeCount_Handler,
+		},
+		{
+			MethodName: "GetCardinalityExpression",
+			Handler:    _Skizze_GetCardinalityExpression_Handler,
+		},
+		{
+			MethodName: "FindSimilar",
+			Handler:    _Skizze_FindSimilar_Handler,
+		},
+		{
+			MethodName: "GetSample",
+			Handler:    _Skizze_GetSample_Handler,
+		},
+		{
+			MethodName: "GetGroups",
+			Handler:    _Skizze_GetGroups_Handler,
+		},
+		{
+			MethodName: "QueryDomain",
+			Handler:    _Skizze_QueryDomain_Handler,
+		},
 	},
-	Streams: []grpc.StreamDesc{},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "src/datamodel/protobuf/skizze.proto",
 }
 
+func init() { proto.RegisterFile("src/datamodel/protobuf/skizze.proto", fileDescriptor0) }
+
 var fileDescriptor0 = []byte{
-	// 1037 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xb4, 0x56, 0xed, 0x72, 0xdb, 0x44,
-	0x14, 0xad, 0x3f, 0xe2, 0x8f, 0xab, 0xd4, 0x71, 0x37, 0x29, 0x18, 0xb5, 0x1d, 0x3a, 0x0b, 0xc3,
-	0x78, 0x02, 0x93, 0x50, 0x37, 0x81, 0x81, 0xe9, 0x30, 0x63, 0x1c, 0x3b, 0x38, 0xc4, 0x21, 0xac,
-	0xc9, 0x6f, 0x46, 0xb5, 0x37, 0x8d, 0x26, 0x92, 0x6d, 0xb4, 0x32, 0xd3, 0xe4, 0x09, 0x78, 0x1b,
-	0x9e, 0x86, 0xf7, 0x61, 0x3f, 0x24, 0xed, 0xae, 0x6c, 0xd3, 0xc9, 0x0f, 0xfe, 0x69, 0xef, 0xde,
-	0x73, 0xee, 0xd5, 0xd9, 0xbd, 0x47, 0x82, 0xcf, 0x58, 0x34, 0x39, 0x9c, 0x7a, 0xb1, 0x17, 0xce,
-	0xa7, 0x34, 0x38, 0x5c, 0x44, 0xf3, 0x78, 0xfe, 0x76, 0x79, 0x7d, 0xc8, 0x6e, 0xfd, 0xfb, 0x7b,
-	0x7a, 0x20, 0xd7, 0xa8, 0x96, 0x86, 0x71, 0x15, 0xb6, 0xfa, 0xe1, 0x22, 0xbe, 0xc3, 0x01, 0x34,
-	0xc7, 0xb7, 0x34, 0x9e, 0xdc, 0x5c, 0x46, 0xf3, 0x05, 0x8d, 0x62, 0x9f, 0x32, 0xf4, 0x05, 0x34,
-	0x42, 0xef, 0xfd, 0xd5, 0xcc, 0xff, 0x63, 0x49, 0x87, 0x31, 0x0d, 0x59, 0xab, 0xf0, 0xb2, 0xd0,
-	0x2e, 0x91, 0x5c, 0x14, 0x3d, 0x87, 0x3a, 0x8d, 0xa2, 0x79, 0x44, 0xbc, 0x98, 0xb6, 0x8a, 0x3c,
-	0xa5, 0x48, 0x74, 0x00, 0x21, 0x28, 0x33, 0xff, 0x9e, 0xb6, 0x4a, 0x12, 0x2b, 0x9f, 0xf1, 0x08,
-	0x1c, 0x55, 0x6d, 0x1c, 0x8b, 0x14, 0x17, 0x6a, 0xd7, 0x7e, 0x10, 0x48, 0x7c, 0x41, 0xe2, 0xb3,
-	0x35, 0xc2, 0xb0, 0x1d, 0x78, 0x2c, 0x1e, 0xcf, 0xbc, 0x05, 0xbb, 0x99, 0xc7, 0x92, 0xbf, 0x44,
-	0xac, 0x18, 0x3e, 0x83, 0xca, 0xc9, 0x3c, 0xf4, 0xfc, 0x99, 0x28, 0x36, 0xf3, 0x42, 0xc1, 0x52,
-	0x6c, 0xd7, 0x89, 0x7c, 0x46, 0x5f, 0x41, 0x8d, 0xc9, 0x62, 0x94, 0x71, 0x74, 0xa9, 0xed, 0x74,
-	0x9a, 0x07, 0xa9, 0x00, 0x07, 0xaa, 0x0d, 0x92, 0x65, 0xe0, 0xbf, 0x0b, 0x50, 0x51, 0xc1, 0xb5,
-	0x64, 0x6d, 0x28, 0xc7, 0x77, 0x0b, 0xf1, 0x9a, 0xc5, 0x76, 0xa3, 0xb3, 0x97, 0x27, 0xfa, 0x8d,
-	0xef, 0x11, 0x99, 0x81, 0xbe, 0x07, 0x58, 0x64, 0x5a, 0xca, 0xb7, 0x77, 0x3a, 0x6e, 0x3e, 0x5f,
-	0xab, 0x4d, 0x8c, 0x6c, 0xf4, 0x25, 0x6c, 0x31, 0xa1, 0x4c, 0xab, 0x2c, 0x61, 0x4f, 0xf3, 0x30,
-	0x29, 0x1b, 0x51, 0x39, 0xf8, 0x07, 0x80, 0x11, 0x0d, 0xdf, 0xd2, 0x88, 0xdd, 0xf8, 0x0b, 0xb4,
-	0x07, 0x5b, 0x7f, 0x7a, 0xc1, 0x32, 0xed, 0x5a, 0x2d, 0x84, 0xc2, 0x3e, 0x53, 0x59, 0xb2, 0xf5,
-	0x1a, 0xc9, 0xd6, 0xf8, 0x5b, 0xa8, 0x0f, 0x22, 0xca, 0x4f, 0x73, 0x36, 0xb9, 0xdb, 0x00, 0xe7,
-	0xd1, 0xc9, 0x7c, 0x39, 0x8b, 0x25, 0xb6, 0x44, 0xd4, 0x02, 0x77, 0xa0, 0x4c, 0xbc, 0xd9, 0xed,
-	0x83, 0x30, 0x1f, 0xc3, 0xd3, 0x5e, 0x44, 0x79, 0xdb, 0xe9, 0xe1, 0x11, 0x51, 0x99, 0xc5, 0x38,
-	0x84, 0xdd, 0xfc, 0xc6, 0x22, 0xb8, 0x43, 0x5f, 0x43, 0x45, 0xbc, 0xe5, 0x92, 0x49, 0xf2, 0x46,
-	0xa7, 0x65, 0x48, 0x91, 0x24, 0x8e, 0xe5, 0x3e, 0x49, 0xf2, 0xd0, 0xe7, 0xf0, 0x58, 0x3d, 0x8d,
-	0x28, 0x63, 0xde, 0x3b, 0x75, 0x23, 0xeb, 0xc4, 0x0e, 0xe2, 0x3d, 0x40, 0xa7, 0x34, 0xce, 0x37,
-	0xf1, 0x57, 0x01, 0x9a, 0x56, 0xf8, 0x7f, 0x6c, 0x41, 0x8c, 0x4d, 0xec, 0x87, 0xbc, 0xac, 0x17,
-	0x2e, 0x92, 0xe9, 0xd0, 0x01, 0x7e, 0x2a, 0xce, 0xb9, 0xcf, 0xd2, 0xce, 0xb2, 0x7b, 0x57, 0xf8,
-	0xd0, 0xbd, 0xc3, 0xdf, 0x41, 0x5d, 0x01, 0x45, 0xef, 0xe6, 0xdd, 0x2f, 0x7c, 0xf0, 0xee, 0xb7,
-	0xa1, 0x29, 0xa0, 0x6a, 0x96, 0x98, 0x62, 0xe0, 0xc7, 0x28, 0x2e, 0xbe, 0x82, 0xf3, 0xc3, 0x95,
-	0x0b, 0xfc, 0x1e, 0xa0, 0x3b, 0x9d, 0xea, 0xe6, 0x2a, 0x53, 0x89, 0x91, 0xd3, 0x6b, 0xd5, 0x50,
-	0x5c, 0x24, 0xd9, 0x17, 0x99, 0xaa, 0x9a, 0x94, 0x64, 0x5d, 0x37, 0xc9, 0x3e, 0xfa, 0x08, 0x2a,
-	0xf2, 0x1e, 0x89, 0xd1, 0x11, 0x85, 0x93, 0x15, 0x06, 0xa8, 0xc9, 0xca, 0xbc, 0x37, 0x4c, 0x00,
-	0xf8, 0x69, 0xa5, 0x5d, 0x3c, 0xe8, 0x5d, 0x0d, 0xfe, 0xa2, 0xc5, 0x7f, 0x06, 0x4d, 0x3d, 0x4d,
-	0x84, 0xb2, 0x65, 0x10, 0xa3, 0x6f, 0xc0, 0x09, 0xb3, 0x58, 0x4a, 0x6e, 0x9c, 0x81, 0x01, 0x30,
-	0x13, 0xf1, 0x4f, 0xb0, 0x93, 0x4d, 0x56, 0x42, 0x75, 0x0c, 0xce, 0x75, 0x12, 0xf2, 0x33, 0x3f,
-	0xda, 0xd5, 0x54, 0x3a, 0xdf, 0xcc, 0xc3, 0xc7, 0xf0, 0xa4, 0xe7, 0x45, 0x53, 0x7f, 0xe6, 0x05,
-	0x7e, 0x9c, 0x72, 0xbd, 0x04, 0x67, 0xa2, 0x83, 0xf2, 0x6a, 0x94, 0x88, 0x19, 0xc2, 0x6f, 0xa0,
-	0x21, 0x26, 0xd4, 0x9f, 0xbd, 0x63, 0x09, 0x66, 0x1f, 0x6a, 0x51, 0x12, 0x49, 0xde, 0xa3, 0xa1,
-	0x8b, 0x8b, 0x5c, 0x92, 0xed, 0x73, 0x29, 0xc4, 0x8c, 0x98, 0x6a, 0x88, 0x0b, 0x71, 0x04, 0xd5,
-	0x48, 0x72, 0xa5, 0x04, 0xee, 0x5a, 0x21, 0x64, 0x0a, 0x49, 0x53, 0xb9, 0x14, 0x4f, 0x38, 0x97,
-	0xa1, 0x86, 0xa0, 0x7a, 0x9d, 0xa7, 0xfa, 0x64, 0x9d, 0x10, 0x39, 0xa6, 0x73, 0xd8, 0xe5, 0x4c,
-	0x96, 0x1a, 0x82, 0xeb, 0x38, 0xcf, 0xf5, 0x4c, 0x73, 0xad, 0x48, 0xa7, 0xd9, 0x06, 0x72, 0xe0,
-	0xb5, 0x48, 0x82, 0xaa, 0x93, 0xa7, 0x6a, 0xd9, 0x12, 0x69, 0x39, 0x33, 0x9e, 0xfd, 0x23, 0x00,
-	0x3d, 0x89, 0xa8, 0x06, 0xe5, 0x51, 0x7f, 0xf4, 0x63, 0xb3, 0x20, 0x9e, 0x06, 0xa4, 0xff, 0x6b,
-	0xb3, 0x28, 0x9e, 0x48, 0xf7, 0xe2, 0xe7, 0x66, 0x49, 0x3c, 0xf5, 0xba, 0xe4, 0xa4, 0x59, 0xde,
-	0x3f, 0x83, 0x86, 0x6d, 0x21, 0xc8, 0x81, 0xea, 0x65, 0xff, 0xe2, 0x64, 0x78, 0x71, 0xca, 0xc1,
-	0x3b, 0xe0, 0x0c, 0x2f, 0x7e, 0xbf, 0x24, 0xbf, 0x9c, 0x92, 0xfe, 0x78, 0xcc, 0x39, 0x1a, 0xbc,
-	0xca, 0x55, 0xaf, 0xc7, 0x17, 0x83, 0xab, 0x73, 0xce, 0x04, 0x50, 0x19, 0x74, 0x87, 0xe7, 0x7d,
-	0xce, 0xd5, 0xf9, 0xa7, 0x2a, 0x3e, 0x5c, 0xe2, 0x2b, 0x8f, 0x08, 0x34, 0x6c, 0x2f, 0x45, 0x9f,
-	0x1a, 0x62, 0xac, 0xb3, 0x5f, 0xf7, 0xc5, 0xe6, 0x04, 0x31, 0x69, 0x8f, 0xd0, 0x10, 0x1c, 0xc3,
-	0x19, 0xd1, 0x73, 0x9d, 0xbf, 0xea, 0xa3, 0xae, 0xbb, 0x61, 0x57, 0x51, 0x1d, 0x41, 0x59, 0xd8,
-	0x0c, 0x32, 0x3e, 0x6b, 0x86, 0xd5, 0xb9, 0xbb, 0xf9, 0xb0, 0x42, 0xbd, 0x82, 0xaa, 0x58, 0x76,
-	0x83, 0x00, 0xed, 0xe8, 0x0c, 0xf9, 0xf7, 0xb2, 0x09, 0xf2, 0x46, 0x79, 0x68, 0xe2, 0x67, 0xab,
-	0x30, 0xd7, 0x86, 0x99, 0xbe, 0x27, 0xdb, 0xdc, 0x56, 0x52, 0x24, 0xff, 0x16, 0x2b, 0xae, 0xe6,
-	0xae, 0x44, 0x38, 0xea, 0x35, 0x6c, 0x9f, 0xd0, 0x80, 0xfe, 0x07, 0x2a, 0xdf, 0x86, 0x7c, 0xb7,
-	0x3a, 0xd7, 0xe9, 0x41, 0x75, 0xb2, 0xee, 0x92, 0x9f, 0x95, 0x15, 0xaf, 0x73, 0x57, 0x22, 0x66,
-	0x77, 0x1b, 0x51, 0x1b, 0xbb, 0x7b, 0x50, 0x9d, 0x57, 0x50, 0xe2, 0x2e, 0x8d, 0x0c, 0x8f, 0xd4,
-	0x9f, 0x0b, 0x17, 0xe5, 0xa2, 0x4a, 0xee, 0x3e, 0x3c, 0xb6, 0xdc, 0xc6, 0x04, 0x6b, 0x97, 0x77,
-	0xed, 0x8b, 0x97, 0x33, 0x27, 0x4e, 0xd3, 0x83, 0x6d, 0xd3, 0x68, 0x36, 0xb0, 0x3c, 0xb3, 0xa2,
-	0xb6, 0x2d, 0x71, 0x92, 0x53, 0x68, 0xd8, 0x1e, 0xb3, 0x81, 0xe6, 0x85, 0x15, 0xcd, 0x7b, 0x12,
-	0x27, 0xea, 0xca, 0xa9, 0x49, 0x4d, 0x63, 0x03, 0x8b, 0x3d, 0x2d, 0x96, 0x17, 0xe1, 0x47, 0xff,
-	0x06, 0x00, 0x00, 0xff, 0xff, 0xc7, 0x64, 0x21, 0x77, 0xd2, 0x0b, 0x00, 0x00,
+	// 2266 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x18, 0x5d, 0x73, 0xdb, 0xc6,
+	0x91, 0x00, 0x3f, 0x44, 0x2e, 0x65, 0x1a, 0x3e, 0xcb, 0x0e, 0x4b, 0x3b, 0xa9, 0x07, 0xf5, 0x78,
+	0x34, 0x4e, 0x2a, 0x27, 0x8a, 0xd3, 0x4c, 0x1c, 0xa7, 0x29, 0x4d, 0x42, 0x14, 0x6d, 0x89, 0x52,
+	0x8e, 0x52, 0x32, 0x7d, 0x69, 0x0b, 0x13, 0x47, 0x11, 0x15, 0x08, 0x20, 0x00, 0x18, 0x99, 0x7e,
+	0xeb, 0xf4, 0xa5, 0x3f, 0xa0, 0xbf, 0xa2, 0xd3, 0x99, 0xfe, 0x81, 0x4e, 0xff, 0x43, 0xdf, 0xfb,
+	0xd2, 0x7f, 0xd2, 0xb9, 0x0f, 0x00, 0x07, 0xf0, 0x43, 0xa5, 0xa7, 0x99, 0xbe, 0xdd, 0xee, 0xed,
+	0xee, 0xed, 0xed, 0xc7, 0xed, 0xde, 0xc2, 0xcf, 0xc2, 0x60, 0xf4, 0xc4, 0x32, 0x23, 0x73, 0xea,
+	0x59, 0xc4, 0x79, 0xe2, 0x07, 0x5e, 0xe4, 0xbd, 0x9e, 0x8d, 0x9f, 0x84, 0x97, 0xf6, 0xdb, 0xb7,
+	0x64, 0x8f, 0xc1, 0xa8, 0x1a, 0xa3, 0xf5, 0x2d, 0x28, 0x1b, 0x53, 0x3f, 0x9a, 0xeb, 0xff, 0x52,
+	0x40, 0x1b, 0x5e, 0x92, 0x68, 0x34, 0x39, 0x0d, 0x3c, 0x9f, 0x04, 0x91, 0x4d, 0x42, 0xf4, 0x08,
+	0x1a, 0x53, 0xf3, 0xcd, 0xb9, 0x6b, 0x7f, 0x3f, 0x23, 0xfd, 0x88, 0x4c, 0xc3, 0xa6, 0xf2, 0x40,
+	0xd9, 0x2d, 0xe2, 0x1c, 0x16, 0xdd, 0x87, 0x1a, 0x09, 0x02, 0x2f, 0xc0, 0x66, 0x44, 0x9a, 0xea,
+	0x03, 0x65, 0x57, 0xc5, 0x29, 0x02, 0x21, 0x28, 0x85, 0xf6, 0x5b, 0xd2, 0x2c, 0x32, 0x5e, 0xb6,
+	0x46, 0x2d, 0xa8, 0x4e, 0x4c, 0x67, 0x7c, 0x64, 0x8f, 0x49, 0xb3, 0xc4, 0xf0, 0x09, 0x4c, 0xf7,
+	0xa6, 0xb6, 0xfb, 0xad, 0xe9, 0xcc, 0x48, 0xb3, 0xcc, 0xf7, 0x62, 0x98, 0xed, 0x99, 0x6f, 0xf8,
+	0x5e, 0x45, 0xec, 0x09, 0x98, 0xee, 0x5d, 0x11, 0xfb, 0x62, 0x12, 0x11, 0xab, 0xb9, 0xf5, 0x40,
+	0xd9, 0xad, 0xe2, 0x04, 0xd6, 0x8f, 0xa1, 0xce, 0x6f, 0x37, 0x8c, 0xa8, 0x4a, 0x2d, 0xa8, 0x8e,
+	0x6d, 0xc7, 0x61, 0xfa, 0x2a, 0x4c, 0xdf, 0x04, 0x46, 0x3a, 0x6c, 0x3b, 0x66, 0x18, 0x0d, 0x5d,
+	0xd3, 0x0f, 0x27, 0x5e, 0xc4, 0xee, 0x53, 0xc4, 0x19, 0x9c, 0xfe, 0x12, 0x2a, 0x5d, 0x6f, 0x6a,
+	0xda, 0x2e, 0xbd, 0x9c, 0x6b, 0x4e, 0xa9, 0x14, 0x75, 0xb7, 0x86, 0xd9, 0x1a, 0x7d, 0x04, 0xd5,
+	0x90, 0x1d, 0x46, 0xc2, 0xa6, 0xfa, 0xa0, 0xb8, 0x5b, 0xdf, 0xd7, 0xf6, 0x62, 0x8b, 0xef, 0x71,
+	0x35, 0x70, 0x42, 0xa1, 0xff, 0x4d, 0x81, 0x0a, 0x47, 0x2e, 0x15, 0xb6, 0x0b, 0xa5, 0x68, 0xee,
+	0x53, 0xb3, 0xaa, 0xbb, 0x8d, 0xfd, 0x9d, 0xbc, 0xa0, 0xb3, 0xb9, 0x4f, 0x30, 0xa3, 0x40, 0xcf,
+	0x00, 0xfc, 0xc4, 0x77, 0xcc, 0xda, 0xf5, 0xfd, 0x56, 0x9e, 0x3e, 0xf5, 0x2e, 0x96, 0xa8, 0xd1,
+	0x87, 0x50, 0x0e, 0xa9, 0x65, 0x98, 0x33, 0xea, 0xfb, 0x77, 0xf2, 0x6c, 0xcc, 0x6c, 0x98, 0xd3,
+	0xe8, 0x0e, 0xc0, 0x31, 0x99, 0xbe, 0x26, 0x41, 0x38, 0xb1, 0x7d, 0xb4, 0x03, 0xe5, 0x1f, 0x98,
+	0x3f, 0xb8, 0xd6, 0x1c, 0xa0, 0x16, 0xb6, 0x43, 0x4e, 0xc5, 0x54, 0xaf, 0xe2, 0x04, 0x46, 0x1f,
+	0xc1, 0xad, 0xb1, 0xe9, 0x84, 0xe4, 0xd4, 0x0b, 0xed, 0xc8, 0xfe, 0x81, 0x30, 0x37, 0x14, 0x99,
+	0x1b, 0x16, 0x37, 0xf4, 0x63, 0xa8, 0x1d, 0x04, 0xe4, 0xfb, 0x19, 0x71, 0x47, 0xf3, 0x15, 0x87,
+	0xed, 0x40, 0x79, 0xe4, 0xcd, 0xdc, 0x88, 0x9d, 0x54, 0xc4, 0x1c, 0xa0, 0x58, 0x16, 0x84, 0x22,
+	0xf0, 0x38, 0xa0, 0x1f, 0x42, 0x09, 0x9b, 0xee, 0xe5, 0xff, 0x40, 0xd2, 0x7b, 0x70, 0xa7, 0x13,
+	0x10, 0x33, 0x22, 0x71, 0x58, 0x60, 0xaa, 0x65, 0x18, 0xe9, 0x53, 0xb8, 0x9d, 0xdf, 0xf0, 0x9d,
+	0x39, 0xfa, 0x18, 0x2a, 0xd4, 0x7e, 0xb3, 0x90, 0x1d, 0xd9, 0xd8, 0x6f, 0x4a, 0x46, 0x16, 0x84,
+	0x43, 0xb6, 0x8f, 0x05, 0x1d, 0x7a, 0x08, 0x37, 0xf8, 0xea, 0x98, 0x84, 0xa1, 0x79, 0xc1, 0x73,
+	0xab, 0x86, 0xb3, 0x48, 0x7d, 0x07, 0x50, 0x8f, 0x44, 0x79, 0x25, 0xfe, 0xa4, 0x80, 0x96, 0x41,
+	0xff, 0x88, 0x2a, 0xd0, 0x07, 0x20, 0xb2, 0xa7, 0x24, 0x8c, 0xcc, 0xa9, 0x2f, 0x8c, 0x94, 0x22,
+	0xf4, 0xcf, 0xa1, 0x7e, 0x64, 0x87, 0xb1, 0x66, 0x49, 0x44, 0x2b, 0xd7, 0x45, 0xb4, 0xfe, 0x05,
+	0xd4, 0x38, 0x23, 0xd5, 0x5d, 0xce, 0x2a, 0xe5, 0xda, 0xac, 0xda, 0x05, 0x8d, 0xb2, 0xf2, 0x2c,
+	0x0d, 0xb9, 0x84, 0x1d, 0x28, 0xd3, 0x94, 0xe2, 0xec, 0x35, 0xcc, 0x01, 0xfd, 0x3b, 0xb8, 0xdd,
+	0x8e, 0x22, 0x73, 0x34, 0x11, 0x32, 0x84, 0x96, 0x77, 0xa1, 0x62, 0x31, 0x66, 0x11, 0x20, 0x02,
+	0x42, 0xbb, 0x50, 0xe1, 0x87, 0xb0, 0x10, 0x59, 0xa6, 0x84, 0xd8, 0xa7, 0x82, 0xbb, 0xe4, 0xc7,
+	0x11, 0x7c, 0x8b, 0xdf, 0x6b, 0x60, 0x4e, 0x49, 0x6a, 0x55, 0x59, 0x6c, 0x86, 0x9d, 0x13, 0x27,
+	0x07, 0x35, 0x61, 0xcb, 0x25, 0x57, 0x94, 0x97, 0x9d, 0x54, 0xc3, 0x31, 0x48, 0x05, 0xf3, 0xa3,
+	0x72, 0x82, 0x85, 0x5e, 0xca, 0x7a, 0xbd, 0xd6, 0x08, 0xfe, 0x8b, 0x02, 0xd0, 0xb6, 0xac, 0x65,
+	0xba, 0x2a, 0x6b, 0x75, 0x95, 0x8d, 0xa2, 0xac, 0x3d, 0xfc, 0x2e, 0x54, 0x58, 0x0a, 0xd3, 0x97,
+	0x8f, 0x7a, 0x57, 0x40, 0xd9, 0xd0, 0x2c, 0xe5, 0x42, 0x93, 0xbe, 0xb8, 0x97, 0x64, 0x1e, 0x36,
+	0xcb, 0x8c, 0x87, 0xad, 0xf5, 0xbf, 0x2a, 0x50, 0x63, 0xca, 0x86, 0x33, 0x67, 0xc3, 0xeb, 0x87,
+	0xb3, 0xd1, 0x88, 0x84, 0xa1, 0x78, 0xf1, 0x62, 0x90, 0xee, 0x98, 0xbe, 0xef, 0xd8, 0xc4, 0x6a,
+	0x16, 0xd9, 0xbb, 0x12, 0x83, 0xe8, 0xc3, 0x24, 0x21, 0x4b, 0x2c, 0x1b, 0x6e, 0xa7, 0xd2, 0xdb,
+	0x96, 0x95, 0xcb, 0xc5, 0xe4, 0x19, 0x2a, 0xb3, 0x1c, 0x14, 0xcf, 0xd0, 0x17, 0x50, 0x65, 0xda,
+	0xd2, 0x08, 0xff, 0x39, 0x6c, 0x05, 0x4c, 0xed, 0x38, 0x45, 0xb2, 0xf2, 0xf8, 0x95, 0x70, 0x4c,
+	0xa3, 0x7f, 0x0b, 0xa8, 0x6d, 0x59, 0xfd, 0x71, 0xfb, 0x75, 0x48, 0xdc, 0x68, 0x73, 0x87, 0xa7,
+	0x36, 0x57, 0x65, 0x9b, 0xeb, 0x2f, 0x40, 0xcb, 0xc8, 0xa5, 0xaa, 0xed, 0xe5, 0x55, 0x93, 0x12,
+	0x3f, 0xad, 0x26, 0xa9, 0x6e, 0x7f, 0x57, 0x00, 0x7a, 0x24, 0x51, 0x6a, 0xa3, 0xec, 0x5f, 0xa5,
+	0x18, 0xfa, 0x00, 0x60, 0xe4, 0xb9, 0x63, 0xdb, 0x22, 0xee, 0x28, 0x2e, 0x39, 0x12, 0x86, 0x5a,
+	0xd8, 0xb1, 0xa7, 0x76, 0xc4, 0x02, 0xa5, 0x8c, 0x39, 0x40, 0xa5, 0x79, 0xe3, 0x71, 0x48, 0x22,
+	0x66, 0xf8, 0x32, 0x16, 0x90, 0x68, 0x54, 0x3a, 0xac, 0x5e, 0x54, 0x92, 0x46, 0x85, 0xc1, 0xfa,
+	0x4b, 0xd0, 0xa4, 0x5b, 0xf1, 0x50, 0xfa, 0x05, 0xd4, 0xa7, 0x09, 0x6e, 0xbd, 0x19, 0x64, 0x42,
+	0xfd, 0x10, 0x6e, 0x26, 0x15, 0x50, 0x88, 0xfa, 0x0c, 0xea, 0x63, 0x81, 0xb2, 0x93, 0x2e, 0x43,
+	0x72, 0x76, 0x4a, 0x2f, 0xd3, 0xe9, 0x7f, 0x50, 0xe0, 0x56, 0xc7, 0x0c, 0x2c, 0xdb, 0x35, 0x1d,
+	0x3b, 0x8a, 0x85, 0x3d, 0x80, 0xfa, 0x28, 0x45, 0x32, 0xaf, 0x17, 0xb1, 0x8c, 0x62, 0x76, 0xf1,
+	0xae, 0x58, 0x29, 0x67, 0x05, 0x90, 0x01, 0x14, 0x3b, 0xf3, 0x7d, 0x92, 0x94, 0x45, 0x06, 0xe4,
+	0x6c, 0x5c, 0xca, 0xdb, 0x58, 0x7f, 0x0e, 0x0d, 0x5a, 0x80, 0x6d, 0xf7, 0x22, 0x14, 0xe7, 0x3f,
+	0x86, 0x6a, 0x20, 0x30, 0xc2, 0x28, 0x8d, 0xf4, 0x26, 0x94, 0x16, 0x27, 0xfb, 0xfa, 0x4b, 0x56,
+	0xec, 0x64, 0xd3, 0xd2, 0xe0, 0x7a, 0x9a, 0x0f, 0xae, 0xd6, 0x52, 0xab, 0xe6, 0xc2, 0xff, 0x10,
+	0x6e, 0xf5, 0x48, 0x24, 0x99, 0x96, 0x8a, 0xfa, 0x34, 0x2f, 0xea, 0x27, 0xcb, 0xac, 0x9a, 0x93,
+	0x74, 0x04, 0xb7, 0x7b, 0x24, 0xca, 0x58, 0x96, 0xca, 0xfa, 0x2c, 0x2f, 0xeb, 0x5e, 0x2a, 0x6b,
+	0xc1, 0x0d, 0xa9, 0xb4, 0x03, 0x56, 0xb9, 0x53, 0x23, 0x51, 0x51, 0xfb, 0x79, 0x51, 0xcd, 0xac,
+	0x89, 0x52, 0x73, 0xa6, 0x72, 0x4c, 0xb8, 0x85, 0x4d, 0xf7, 0x82, 0xb0, 0x88, 0x7c, 0xb7, 0x44,
+	0x6a, 0x80, 0xea, 0x78, 0xa2, 0x19, 0x52, 0x1d, 0x8f, 0xc2, 0x13, 0x5b, 0x3c, 0x62, 0xea, 0xc4,
+	0xd6, 0x7f, 0x03, 0x9a, 0x7c, 0x04, 0x73, 0x27, 0xe7, 0xe1, 0x3f, 0x85, 0x94, 0x87, 0x47, 0x8e,
+	0x3a, 0xb1, 0xd3, 0x1e, 0x4b, 0x84, 0x4d, 0xae, 0xc7, 0x2a, 0xc9, 0x3d, 0x16, 0x77, 0xb7, 0x7c,
+	0xc4, 0x75, 0xee, 0xce, 0xab, 0x93, 0x9a, 0xe3, 0x1f, 0x0a, 0xdc, 0x91, 0xac, 0x6e, 0xbc, 0xf1,
+	0x03, 0x12, 0x86, 0xb6, 0xe7, 0x66, 0x5e, 0xbc, 0x6b, 0xab, 0x8c, 0xa8, 0x5c, 0xbc, 0x0f, 0x8a,
+	0xeb, 0xd4, 0x23, 0x50, 0x3d, 0xde, 0xf9, 0x34, 0xf6, 0xef, 0x4a, 0xdc, 0x24, 0x3a, 0xf1, 0x49,
+	0x60, 0x46, 0xb6, 0xe7, 0x62, 0xd5, 0xf3, 0xd1, 0x97, 0x50, 0xa5, 0x3d, 0xb7, 0xe9, 0x5a, 0xf4,
+	0xc5, 0xa7, 0xaa, 0xff, 0x74, 0x69, 0x48, 0xa4, 0xca, 0xe1, 0x84, 0x81, 0xfe, 0x14, 0xee, 0x2f,
+	0xa7, 0x11, 0xbe, 0xfd, 0x1a, 0x80, 0x24, 0x48, 0xf1, 0x7a, 0x5f, 0x2b, 0x5f, 0x62, 0xc9, 0xe5,
+	0xae, 0xba, 0xf0, 0x3e, 0xee, 0x89, 0xd6, 0x8d, 0x5f, 0x74, 0x69, 0xeb, 0xf6, 0xac, 0xd4, 0x69,
+	0xe3, 0xae, 0x68, 0xe0, 0x2e, 0xa1, 0xb5, 0x42, 0x61, 0x9e, 0x6a, 0x15, 0xee, 0x1b, 0xa1, 0xea,
+	0xda, 0xec, 0x10, 0xa4, 0xb4, 0x96, 0x5e, 0x99, 0x81, 0x6b, 0xbb, 0x17, 0xc2, 0x05, 0x31, 0x48,
+	0xfd, 0x8b, 0x0e, 0x6c, 0xd7, 0x1a, 0xda, 0x53, 0xdb, 0x31, 0x83, 0xcd, 0xcb, 0xd9, 0xc7, 0x00,
+	0x23, 0xd3, 0xb5, 0x6c, 0xcb, 0x8c, 0xd6, 0xfc, 0xdc, 0x24, 0x9a, 0xb4, 0x5e, 0x14, 0xe5, 0x7a,
+	0xf1, 0x10, 0x6e, 0x4c, 0x6d, 0x57, 0xa8, 0x41, 0x5f, 0x54, 0xfe, 0x08, 0x66, 0x91, 0x48, 0x83,
+	0xa2, 0x13, 0x4e, 0x58, 0x49, 0xa9, 0x62, 0xba, 0xa4, 0xff, 0x2a, 0x69, 0xff, 0xbf, 0xd7, 0xfb,
+	0x03, 0x80, 0x30, 0x3d, 0x8c, 0x26, 0xab, 0x8a, 0x25, 0x4c, 0xf6, 0xfb, 0xa2, 0xc6, 0xa9, 0xf5,
+	0x02, 0xb4, 0x8c, 0xb5, 0xae, 0x2b, 0xd2, 0xa9, 0x6a, 0x69, 0x4a, 0x9d, 0xc1, 0xf6, 0xd0, 0x9c,
+	0xfa, 0x0e, 0x11, 0xa9, 0xbf, 0x97, 0xd4, 0x5d, 0xce, 0x2e, 0xa7, 0x02, 0xa3, 0xb3, 0xd8, 0x57,
+	0x3d, 0xa9, 0xc7, 0xd2, 0x77, 0x2b, 0x7d, 0x0a, 0xf4, 0xe7, 0xb1, 0x54, 0x4e, 0xbd, 0xe2, 0xab,
+	0x76, 0x17, 0x2a, 0xfc, 0x7b, 0x2f, 0xe2, 0x54, 0x40, 0xfa, 0x0b, 0x68, 0xd0, 0x7f, 0x8f, 0x50,
+	0x8b, 0xff, 0x7a, 0x72, 0xb7, 0x5a, 0x50, 0x2b, 0xff, 0x54, 0xfc, 0x51, 0x81, 0x1b, 0xbd, 0xc0,
+	0x9b, 0xf9, 0xe1, 0xe6, 0x51, 0x14, 0xb7, 0x94, 0x6a, 0xda, 0x52, 0xae, 0x88, 0x93, 0xeb, 0x2a,
+	0xe5, 0x9f, 0x15, 0xa8, 0x33, 0x2d, 0x84, 0x75, 0x35, 0x28, 0x5e, 0x92, 0xb9, 0xb0, 0x02, 0x5d,
+	0xa2, 0xaf, 0xb2, 0x95, 0x9b, 0xf7, 0xc8, 0x6b, 0xd3, 0x28, 0x53, 0xd6, 0x9f, 0x4a, 0x85, 0x97,
+	0xcf, 0x0b, 0x56, 0x57, 0x95, 0xb4, 0x04, 0xff, 0x9a, 0x19, 0x38, 0x36, 0x0f, 0x35, 0xf0, 0x93,
+	0xbc, 0x81, 0xa5, 0xf9, 0x81, 0x74, 0x81, 0xc4, 0xbe, 0xd4, 0x77, 0x17, 0x8c, 0x5f, 0x38, 0x5e,
+	0x40, 0xfa, 0xef, 0x00, 0x7d, 0x33, 0x23, 0xc1, 0x5c, 0xfc, 0x02, 0x84, 0xed, 0x97, 0x8d, 0x45,
+	0xde, 0xb1, 0xc3, 0xd3, 0xff, 0xa9, 0x42, 0x9d, 0x1d, 0xb1, 0x71, 0x7b, 0xff, 0xff, 0xb0, 0x35,
+	0xfa, 0x1c, 0x6a, 0x71, 0xff, 0x36, 0x17, 0xb3, 0x99, 0x35, 0xfd, 0x48, 0x4a, 0x8b, 0x9e, 0x01,
+	0xa4, 0x2d, 0x24, 0x7b, 0x64, 0xd6, 0x37, 0x45, 0x12, 0x35, 0xcd, 0xe2, 0x90, 0xa5, 0x05, 0xeb,
+	0x6a, 0x57, 0xa7, 0x8b, 0xa0, 0xd2, 0xbf, 0x03, 0x2d, 0xe3, 0x35, 0x1a, 0x12, 0xcb, 0x7c, 0x26,
+	0x85, 0x89, 0x9a, 0x0f, 0x13, 0xc9, 0x27, 0x49, 0x98, 0x3c, 0xbe, 0x02, 0x48, 0x0b, 0x0b, 0xaa,
+	0x42, 0xe9, 0xd8, 0x38, 0x7e, 0xa1, 0x29, 0x74, 0x75, 0x80, 0x8d, 0x6f, 0x34, 0x95, 0xae, 0x70,
+	0x7b, 0xf0, 0x4a, 0x2b, 0xd2, 0x15, 0x2d, 0x3c, 0x5a, 0x09, 0xd5, 0xa0, 0x8c, 0xdb, 0x83, 0x9e,
+	0xa1, 0x95, 0xe9, 0xf2, 0xec, 0xd0, 0x38, 0x6b, 0x6b, 0x15, 0x54, 0x87, 0xad, 0xe3, 0xfe, 0xe0,
+	0xb0, 0x3d, 0x3c, 0xd4, 0xb6, 0x28, 0xf1, 0xb0, 0x7d, 0x7c, 0xaa, 0x55, 0x29, 0xc5, 0x2b, 0xc6,
+	0x57, 0x63, 0x4b, 0x26, 0x0c, 0x1e, 0x7f, 0x09, 0xdb, 0x72, 0xe9, 0xa6, 0x5b, 0xe7, 0x83, 0xfe,
+	0xc9, 0x40, 0x53, 0x90, 0x06, 0xdb, 0xfd, 0xc1, 0x99, 0x81, 0x87, 0x46, 0xe7, 0x8c, 0x62, 0x54,
+	0xd4, 0x00, 0xe8, 0xf6, 0x0f, 0x0e, 0x0c, 0x6c, 0x0c, 0x3a, 0x86, 0x56, 0x7c, 0xfc, 0x12, 0x1a,
+	0xd9, 0x61, 0x0a, 0x3d, 0xfb, 0xd4, 0x18, 0x74, 0xfb, 0x83, 0x9e, 0xa6, 0xa0, 0x9b, 0x50, 0xef,
+	0x0f, 0x7e, 0x7b, 0x8a, 0x4f, 0x7a, 0xd8, 0x18, 0x0e, 0x39, 0xff, 0xf0, 0xbc, 0xd3, 0x31, 0x86,
+	0xc3, 0x83, 0xf3, 0x23, 0xad, 0x88, 0x00, 0x2a, 0x07, 0xed, 0xfe, 0x91, 0xd1, 0xd5, 0x4a, 0x8f,
+	0xfb, 0xec, 0x2b, 0x2a, 0xc4, 0xd4, 0xa0, 0xdc, 0xee, 0x76, 0x8d, 0xae, 0xa6, 0x50, 0x9a, 0xa3,
+	0x93, 0xce, 0x2b, 0xa3, 0xab, 0xa9, 0xe8, 0x06, 0xd4, 0x86, 0xed, 0xb3, 0x73, 0xdc, 0x3e, 0x33,
+	0xba, 0x5a, 0x91, 0x5f, 0x74, 0x38, 0xa4, 0x87, 0x31, 0x5b, 0x18, 0x18, 0x9f, 0x60, 0xad, 0xbc,
+	0xff, 0xef, 0x9b, 0x50, 0x19, 0xb2, 0x29, 0x30, 0xc2, 0xd0, 0xc8, 0x0e, 0xa8, 0x90, 0xdc, 0x25,
+	0x2c, 0x9b, 0x69, 0xb5, 0xde, 0x5f, 0x4d, 0xe0, 0x3b, 0x73, 0xbd, 0x80, 0xfa, 0x50, 0x97, 0xc6,
+	0x4d, 0xe8, 0xbe, 0xf4, 0x02, 0x2c, 0x0c, 0xa7, 0x5a, 0xad, 0x15, 0xbb, 0x5c, 0xd4, 0x53, 0x28,
+	0x1d, 0xd9, 0x61, 0x84, 0xa4, 0xf0, 0x90, 0xe6, 0x47, 0xad, 0xdb, 0x79, 0x34, 0xe7, 0xfa, 0x04,
+	0xb6, 0x28, 0xd8, 0x76, 0x1c, 0x74, 0x33, 0xa5, 0x60, 0xd3, 0xed, 0x55, 0x2c, 0xcf, 0xf9, 0x60,
+	0x4a, 0x0c, 0x89, 0x16, 0xd9, 0x5a, 0x59, 0x36, 0x79, 0x98, 0xc4, 0xd4, 0xdc, 0xe6, 0xa6, 0x10,
+	0xa3, 0xe0, 0x85, 0x29, 0x46, 0x6b, 0x01, 0xa3, 0x17, 0xd0, 0xa7, 0xb0, 0xdd, 0x25, 0x0e, 0x59,
+	0xc3, 0x95, 0x57, 0x83, 0xdd, 0xad, 0xd6, 0x23, 0xd1, 0x46, 0xe7, 0xb4, 0x61, 0x5b, 0x1e, 0x6b,
+	0x21, 0xc9, 0x81, 0x4b, 0xc6, 0x5d, 0xab, 0x44, 0xc8, 0x03, 0x2c, 0x59, 0xc4, 0x92, 0xc1, 0xd6,
+	0x52, 0x11, 0xfb, 0x50, 0xef, 0x38, 0xc4, 0x0c, 0x36, 0xb9, 0xec, 0xd7, 0xb0, 0x8d, 0x09, 0x7d,
+	0x30, 0x04, 0xd3, 0xbd, 0x3c, 0x93, 0x34, 0x9d, 0x5a, 0x7a, 0xe8, 0x2f, 0xe9, 0xa1, 0x9e, 0xfb,
+	0xce, 0xfc, 0x89, 0x63, 0xc5, 0xbd, 0x17, 0x6a, 0x42, 0x6b, 0x01, 0x23, 0x3b, 0x76, 0x25, 0xd7,
+	0x4a, 0xc7, 0x6e, 0x74, 0x4e, 0x6c, 0xd2, 0x4d, 0x8e, 0x49, 0x4c, 0x2a, 0x98, 0xee, 0xe5, 0x99,
+	0x56, 0x98, 0x24, 0x39, 0x34, 0x36, 0xe9, 0xbb, 0xf2, 0x7f, 0x02, 0xc5, 0xb6, 0x65, 0xa1, 0x9d,
+	0xdc, 0x38, 0x8a, 0x33, 0xa0, 0x1c, 0x36, 0x79, 0x50, 0xa4, 0x21, 0x92, 0xfc, 0xa0, 0x2c, 0xce,
+	0xac, 0xe4, 0x4c, 0xcd, 0x4f, 0x9e, 0xf4, 0x02, 0x32, 0xe0, 0x46, 0x66, 0x68, 0x20, 0xeb, 0x91,
+	0xce, 0x98, 0x5a, 0xd9, 0x37, 0x2b, 0x37, 0x63, 0xd0, 0x0b, 0xa8, 0x03, 0xdb, 0xf2, 0xbc, 0x60,
+	0x85, 0x94, 0x7b, 0x19, 0x6c, 0x76, 0xba, 0xa0, 0x17, 0x50, 0x8f, 0x75, 0x4f, 0x1d, 0x79, 0xb8,
+	0xb2, 0x54, 0xcc, 0xfb, 0x19, 0x6c, 0x7e, 0xb4, 0xc0, 0xb2, 0xb3, 0x2e, 0x4d, 0x09, 0x56, 0x48,
+	0xc9, 0x3e, 0xb4, 0x99, 0x91, 0x82, 0x5e, 0x40, 0x47, 0xcc, 0x2e, 0xe9, 0x8f, 0x59, 0xf6, 0xeb,
+	0xc2, 0xe4, 0x20, 0x67, 0x9e, 0xdc, 0x9f, 0x5c, 0x2f, 0xa0, 0xdf, 0x43, 0x33, 0xab, 0xa9, 0xf4,
+	0xc3, 0x7e, 0x74, 0xdd, 0x2f, 0x54, 0x9c, 0xf1, 0xf0, 0x5a, 0xba, 0x24, 0x38, 0xa4, 0xcf, 0x8b,
+	0x1c, 0x1c, 0x8b, 0x3f, 0x40, 0xd9, 0x08, 0xf9, 0x1f, 0x8f, 0x5e, 0x40, 0x5f, 0xf1, 0x14, 0x64,
+	0xad, 0xcc, 0x0a, 0x2b, 0x36, 0xb3, 0xe5, 0x2a, 0xfd, 0x5a, 0xe8, 0x05, 0xf4, 0x2b, 0xc6, 0xce,
+	0xbb, 0x61, 0xf4, 0x5e, 0xae, 0xef, 0x0d, 0x97, 0x4b, 0x90, 0x7a, 0x67, 0x7e, 0x17, 0xa9, 0x7d,
+	0x92, 0xef, 0xb2, 0xd8, 0x0b, 0xcb, 0x77, 0xc9, 0xf7, 0x5c, 0x7a, 0xe1, 0x3f, 0x01, 0x00, 0x00,
+	0xff, 0xff, 0x17, 0x91, 0x44, 0x4a, 0xfe, 0x1d, 0x00, 0x00,
 }
@@ -0,0 +1,158 @@
+package datamodel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	pb "datamodel/protobuf"
+)
+
+// Capability is a kind of query a sketch type can answer
+type Capability uint
+
+// Capabilities of sketch types, they match the capability interfaces of the
+// sketches. Decay marks the types that accept a halfLife property, SetQuery
+// the ones that intersect and subtract sketches of their type natively and
+// Weighting the ones that accept a weighted property.
+const (
+	CardinalityQuery Capability = 1 << iota
+	FrequencyQuery
+	MembershipQuery
+	RankingsQuery
+	AddIfAbsentQuery
+	Decay
+	RangeQuery
+	SetQuery
+	SimilarityQuery
+	SampleQuery
+	Weighting
+	GroupQuery
+)
+
+// SketchType describes a type of sketch. Every type registers itself once
+// with Register, the server and the CLI find everything they need to know
+// about a type in the registry.
+type SketchType struct {
+	// Type is the protobuf enum value of the type. Types that are not part
+	// of skizze.proto pick a value that is not used by it, they are named
+	// by the registry (see TypeName).
+	Type pb.SketchType
+	// Name is the lower case name of the type, as typed in the CLI
+	Name string
+	// New creates an empty sketch described by info
+	New func(info *Info) (Sketcher, error)
+	// Defaults are used for the properties that are left unset when a
+	// domain creates a sketch of this type, nil if the type has no
+	// properties
+	Defaults *pb.SketchProperties
+	// Capabilities are the queries the sketches of this type answer
+	Capabilities Capability
+	// Optional types are left out of domains created without a list of
+	// sketches, e.g. because they only take some kinds of values
+	Optional bool
+}
+
+// Can reports whether the sketches of this type answer queries of kind c
+func (t *SketchType) Can(c Capability) bool {
+	return t.Capabilities&c != 0
+}
+
+var registry = struct {
+	sync.RWMutex
+	byType map[pb.SketchType]*SketchType
+	byName map[string]*SketchType
+}{
+	byType: make(map[pb.SketchType]*SketchType),
+	byName: make(map[string]*SketchType),
+}
+
+// Register adds a sketch type to the registry, it panics if the type or its
+// name is already taken.
+func Register(t *SketchType) {
+	if t.New == nil || len(t.Name) == 0 {
+		panic(fmt.Sprintf("Sketch type %d needs a name and a constructor", t.Type))
+	}
+	t.Name = strings.ToLower(t.Name)
+
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.byType[t.Type]; ok {
+		panic(fmt.Sprintf("Sketch type %s is already registered", t.Type))
+	}
+	if _, ok := registry.byName[t.Name]; ok || t.Name == DOM {
+		panic(fmt.Sprintf("Sketch type name %s is already registered", t.Name))
+	}
+	registry.byType[t.Type] = t
+	registry.byName[t.Name] = t
+}
+
+// Unregister removes a sketch type from the registry, e.g. one registered by
+// a test. Sketches of the type that still exist can not be restored.
+func Unregister(typ pb.SketchType) {
+	registry.Lock()
+	defer registry.Unlock()
+	if t, ok := registry.byType[typ]; ok {
+		delete(registry.byType, typ)
+		delete(registry.byName, t.Name)
+	}
+}
+
+// LookupType returns the registered sketch type with the given enum value
+func LookupType(typ pb.SketchType) (*SketchType, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	t, ok := registry.byType[typ]
+	return t, ok
+}
+
+// LookupName returns the registered sketch type with the given name, the
+// name is case insensitive
+func LookupName(name string) (*SketchType, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	t, ok := registry.byName[strings.ToLower(name)]
+	return t, ok
+}
+
+// TypeName returns the upper case name of a sketch type as it appears in
+// sketch IDs: the enum name for the types of skizze.proto, the registered
+// name for the others
+func TypeName(typ pb.SketchType) string {
+	if _, ok := pb.SketchType_name[int32(typ)]; !ok {
+		if t, ok := LookupType(typ); ok {
+			return strings.ToUpper(t.Name)
+		}
+	}
+	return typ.String()
+}
+
+// ParseTypeName returns the sketch type named name by TypeName
+func ParseTypeName(name string) (pb.SketchType, bool) {
+	if v, ok := pb.SketchType_value[name]; ok {
+		return pb.SketchType(v), true
+	}
+	if t, ok := LookupName(name); ok && strings.ToUpper(t.Name) == name {
+		return t.Type, true
+	}
+	return 0, false
+}
+
+// Types returns every registered sketch type ordered by enum value
+func Types() []*SketchType {
+	registry.RLock()
+	defer registry.RUnlock()
+	types := make([]*SketchType, 0, len(registry.byType))
+	for _, t := range registry.byType {
+		types = append(types, t)
+	}
+	sort.Sort(byType(types))
+	return types
+}
+
+type byType []*SketchType
+
+func (s byType) Len() int           { return len(s) }
+func (s byType) Less(i, j int) bool { return s[i].Type < s[j].Type }
+func (s byType) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -1,7 +1,138 @@
 package datamodel
 
-// Sketcher ...
+import (
+	"time"
+
+	pb "datamodel/protobuf"
+)
+
+// Sketcher is implemented by every sketch. The queries a sketch can answer
+// are given by the capability interfaces it implements.
 type Sketcher interface {
-	Add([][]byte) (bool, error)
-	Get(interface{}) (interface{}, error)
+	// Add returns the number of values that were applied to the sketch
+	Add([][]byte) (int, error)
+}
+
+// Cardinality is implemented by sketches estimating the number of distinct
+// values added, with bounds at the given confidence in ]0, 1[
+type Cardinality interface {
+	Cardinality(confidence float64) (*pb.CardinalityResult, error)
+}
+
+// Frequency is implemented by sketches estimating how often values were added
+type Frequency interface {
+	Frequency([][]byte) (*pb.FrequencyResult, error)
+}
+
+// Membership is implemented by sketches testing whether values were added
+type Membership interface {
+	Membership([][]byte) (*pb.MembershipResult, error)
+}
+
+// AddIfAbsenter is implemented by sketches that can test and add values in
+// a single step. The result tells for every value whether it was (probably)
+// present before it was added.
+type AddIfAbsenter interface {
+	AddIfAbsent([][]byte) ([]bool, error)
+}
+
+// RankingsOptions select which rankings a query returns. Rankings counted
+// less than MinCount are left out, then Offset rankings are skipped and at
+// most Limit are returned. A Limit of 0 leaves the number of rankings up to
+// the sketch.
+type RankingsOptions struct {
+	Limit    int
+	Offset   int
+	MinCount int64
+}
+
+// TimedAdder is implemented by sketches whose counts depend on when values
+// were added, such as the ones that decay. The time comes from the request
+// so that replaying the AOF rebuilds the same counts.
+type TimedAdder interface {
+	AddAt(values [][]byte, at time.Time) (int, error)
+}
+
+// Ranking is implemented by sketches keeping the most frequent values
+type Ranking interface {
+	Rankings(RankingsOptions) (*pb.RankingsResult, error)
+}
+
+// RangeCounter is implemented by sketches of ordered numeric values that
+// count the values between lo and hi, both included
+type RangeCounter interface {
+	RangeCount(lo, hi int64) (*pb.RangeCountResult, error)
+}
+
+// Mergeable is implemented by sketches that can absorb another sketch of the
+// same type and properties
+type Mergeable interface {
+	Merge(Sketcher) error
+}
+
+// SetOperable is implemented by sketches that can, besides merging, keep
+// only the values they share with another sketch of the same type or drop
+// the ones found in it
+type SetOperable interface {
+	Mergeable
+	Intersect(Sketcher) error
+	Subtract(Sketcher) error
+}
+
+// Similar is implemented by sketches estimating the Jaccard similarity of
+// the values added to two sketches of the same type and size
+type Similar interface {
+	Similarity(Sketcher) (*pb.Similarity, error)
+	// SharesBand tells whether the signatures of the sketches agree on all
+	// the rows of at least one band, which is likely for similar sketches
+	// and unlikely for others (locality sensitive hashing)
+	SharesBand(Sketcher) (bool, error)
+	// Bands returns the keys of the bands of the signature, nil while the
+	// sketch is empty. Two sketches share a band if they have the same key
+	// at the same position.
+	Bands() []uint64
+}
+
+// SimilarityOptions select which sketches a similarity search returns. At
+// most Limit sketches at least MinSimilarity similar are returned, with LSH
+// only the sketches sharing a band are compared.
+type SimilarityOptions struct {
+	Limit         int
+	MinSimilarity float64
+	LSH           bool
+}
+
+// Sampler is implemented by sketches keeping a sample of the values added
+type Sampler interface {
+	Sample() (*pb.SampleResult, error)
+}
+
+// GroupOptions select what a group query returns: the Limit groups with
+// the largest cardinality when no keys are given, cardinalities with bounds
+// at Confidence
+type GroupOptions struct {
+	Limit      int
+	Confidence float64
+}
+
+// Grouped is implemented by families of sketches that keep a sketch per
+// group key, created when the first value is added under the key
+type Grouped interface {
+	// AddKeyed adds values[i] to the group of keys[i]
+	AddKeyed(keys, values [][]byte) (int, error)
+	// Groups returns the results of the groups of the given keys, the ones
+	// no value was added under are empty
+	Groups(keys []string, opts GroupOptions) ([]*pb.GroupResult, error)
+	// TopGroups returns the results of the groups with the largest
+	// cardinality, from the largest down
+	TopGroups(opts GroupOptions) ([]*pb.GroupResult, error)
+	// GroupCount returns the number of groups
+	GroupCount() int
+}
+
+// Serializable is implemented by sketches whose state can be written to and
+// restored from bytes
+type Serializable interface {
+	Marshal() ([]byte, error)
+	Unmarshal([]byte) error
 }
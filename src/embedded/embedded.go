@@ -0,0 +1,364 @@
+// Package embedded runs Skizze in-process, without a gRPC server. It shares
+// the manager and the AOF with the server, so a data directory written by
+// one can be opened by the other. The AOF is its only persistence.
+package embedded
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/context"
+
+	"datamodel"
+	pb "datamodel/protobuf"
+	"manager"
+	"storage"
+	"utils"
+)
+
+// Options configure an embedded Skizze
+type Options struct {
+	// DataDir is where the AOF is kept. Every change is appended to the AOF
+	// and replayed by Open. Persistence is disabled if DataDir is empty.
+	// Snapshots are not supported, as in the server: not every sketch type
+	// can be serialized yet.
+	DataDir string
+}
+
+// Skizze is an in-process Skizze, it is safe for concurrent use
+type Skizze struct {
+	lock    sync.RWMutex
+	manager *manager.Manager
+	aof     *storage.AOF
+}
+
+// Open creates an embedded Skizze, restoring its state from the AOF in
+// opts.DataDir if any
+func Open(opts Options) (*Skizze, error) {
+	s := &Skizze{manager: manager.NewManager()}
+	if len(opts.DataDir) == 0 {
+		return s, nil
+	}
+	if err := os.MkdirAll(opts.DataDir, 0700); err != nil {
+		return nil, err
+	}
+	s.aof = storage.NewAOF(filepath.Join(opts.DataDir, "skizze.aof"))
+	if err := s.manager.Replay(s.aof); err != nil {
+		_ = s.aof.Close()
+		return nil, err
+	}
+	s.aof.Run()
+	return s, nil
+}
+
+// Close flushes the AOF to disk
+func (s *Skizze) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.aof == nil {
+		return nil
+	}
+	err := s.aof.Close()
+	s.aof = nil
+	return err
+}
+
+// apply records a change in the AOF before applying it with fn
+func (s *Skizze) apply(ctx context.Context, op uint8, msg proto.Message, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.aof != nil {
+		if err := s.aof.Append(op, msg); err != nil {
+			return err
+		}
+	}
+	return fn()
+}
+
+// read runs fn under the read lock unless ctx is done
+func (s *Skizze) read(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return fn()
+}
+
+// id returns the id of the sketch called name
+func id(name string, typ pb.SketchType) string {
+	info := &datamodel.Info{Sketch: newSketch(name, typ, nil)}
+	return info.ID()
+}
+
+func newSketch(name string, typ pb.SketchType, props *pb.SketchProperties) *pb.Sketch {
+	return &pb.Sketch{
+		Name:       utils.Stringp(name),
+		Type:       &typ,
+		Properties: props,
+	}
+}
+
+// CreateDomain creates a domain with the given sketches, or with one sketch
+// of every type and the default properties if none are given. The names of
+// the sketches default to the name of the domain.
+func (s *Skizze) CreateDomain(ctx context.Context, name string, sketches ...*pb.Sketch) error {
+	for _, sketch := range sketches {
+		if sketch.Name == nil {
+			sketch.Name = utils.Stringp(name)
+		}
+	}
+	dom := &pb.Domain{Name: utils.Stringp(name), Sketches: sketches}
+	return s.apply(ctx, storage.CreateDom, dom, func() error {
+		return s.manager.CreateDomain(dom)
+	})
+}
+
+// DeleteDomain deletes a domain and its sketches
+func (s *Skizze) DeleteDomain(ctx context.Context, name string) error {
+	dom := &pb.Domain{Name: utils.Stringp(name)}
+	return s.apply(ctx, storage.DeleteDom, dom, func() error {
+		return s.manager.DeleteDomain(name)
+	})
+}
+
+// CreateSketch creates a standalone sketch
+func (s *Skizze) CreateSketch(ctx context.Context, name string, typ pb.SketchType, props *pb.SketchProperties) error {
+	sketch := newSketch(name, typ, props)
+	return s.apply(ctx, storage.CreateSketch, sketch, func() error {
+		return s.manager.CreateSketch(&datamodel.Info{Sketch: sketch})
+	})
+}
+
+// DeleteSketch deletes a sketch
+func (s *Skizze) DeleteSketch(ctx context.Context, name string, typ pb.SketchType) error {
+	sketch := newSketch(name, typ, nil)
+	return s.apply(ctx, storage.DeleteSketch, sketch, func() error {
+		return s.manager.DeleteSketch(id(name, typ))
+	})
+}
+
+// Add adds values to every sketch of a domain and reports the outcome per
+// sketch
+func (s *Skizze) Add(ctx context.Context, domain string, values ...string) ([]*pb.AddResult, error) {
+	at := time.Now()
+	req := &pb.AddRequest{
+		Domain:    &pb.Domain{Name: utils.Stringp(domain)},
+		Values:    values,
+		Timestamp: utils.Int64p(at.UnixNano()),
+	}
+	var results []*pb.AddResult
+	err := s.apply(ctx, storage.Add, req, func() error {
+		var err error
+		results, err = s.manager.AddToDomainAt(domain, values, at)
+		return err
+	})
+	return results, err
+}
+
+// AddToSketch adds values to a single sketch
+func (s *Skizze) AddToSketch(ctx context.Context, name string, typ pb.SketchType, values ...string) (*pb.AddResult, error) {
+	sketch := newSketch(name, typ, nil)
+	at := time.Now()
+	req := &pb.AddRequest{Sketch: sketch, Values: values, Timestamp: utils.Int64p(at.UnixNano())}
+	var result *pb.AddResult
+	err := s.apply(ctx, storage.Add, req, func() error {
+		var err error
+		result, err = s.manager.AddToSketchAt(id(name, typ), values, at)
+		return err
+	})
+	return result, err
+}
+
+// AddToGroups adds every value to the group of its key in the KCARD or
+// KRANK sketch called name, keys[i] being the key of values[i]
+func (s *Skizze) AddToGroups(ctx context.Context, name string, typ pb.SketchType, keys []string, values []string) (*pb.AddResult, error) {
+	req := &pb.AddRequest{Sketch: newSketch(name, typ, nil), Keys: keys, Values: values}
+	var result *pb.AddResult
+	err := s.apply(ctx, storage.Add, req, func() error {
+		var err error
+		result, err = s.manager.AddToGroups(id(name, typ), keys, values)
+		return err
+	})
+	return result, err
+}
+
+// AddIfAbsent adds the values that are not in the MEMB sketch called name yet
+// and returns for every value whether it was already present
+func (s *Skizze) AddIfAbsent(ctx context.Context, name string, values ...string) ([]bool, error) {
+	req := &pb.AddIfAbsentRequest{Sketch: newSketch(name, pb.SketchType_MEMB, nil), Values: values}
+	var present []bool
+	err := s.apply(ctx, storage.AddIfAbsent, req, func() error {
+		res, err := s.manager.AddIfAbsent(id(name, pb.SketchType_MEMB), values)
+		for _, v := range res {
+			present = append(present, v.GetIsMember())
+		}
+		return err
+	})
+	return present, err
+}
+
+// Cardinality returns the number of distinct values added to the CARD
+// sketch called name
+func (s *Skizze) Cardinality(ctx context.Context, name string) (int64, error) {
+	res, err := s.CardinalityBounds(ctx, name, 0)
+	return res.GetCardinality(), err
+}
+
+// CardinalityBounds returns the cardinality of the CARD sketch called name
+// together with its lower and upper bounds at the given confidence, 0 uses
+// the default confidence
+func (s *Skizze) CardinalityBounds(ctx context.Context, name string, confidence float64) (*pb.CardinalityResult, error) {
+	var res *pb.CardinalityResult
+	err := s.read(ctx, func() error {
+		var err error
+		res, err = s.manager.GetCardinality(id(name, pb.SketchType_CARD), confidence)
+		return err
+	})
+	return res, err
+}
+
+// Frequency returns how often each of values was added to the FREQ sketch
+// called name
+func (s *Skizze) Frequency(ctx context.Context, name string, values ...string) (map[string]int64, error) {
+	var res *pb.FrequencyResult
+	err := s.read(ctx, func() error {
+		var err error
+		res, err = s.manager.GetFrequency(id(name, pb.SketchType_FREQ), values)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	freqs := make(map[string]int64, len(values))
+	for _, v := range res.GetFrequencies() {
+		freqs[v.GetValue()] = v.GetCount()
+	}
+	return freqs, nil
+}
+
+// Membership returns whether each of values was added to the MEMB sketch
+// called name
+func (s *Skizze) Membership(ctx context.Context, name string, values ...string) (map[string]bool, error) {
+	var res *pb.MembershipResult
+	err := s.read(ctx, func() error {
+		var err error
+		res, err = s.manager.GetMembership(id(name, pb.SketchType_MEMB), values)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	members := make(map[string]bool, len(values))
+	for _, v := range res.GetMemberships() {
+		members[v.GetValue()] = v.GetIsMember()
+	}
+	return members, nil
+}
+
+// TopK returns the top ranking values of the RANK sketch called name
+func (s *Skizze) TopK(ctx context.Context, name string) ([]*pb.Rank, error) {
+	var res *pb.RankingsResult
+	err := s.read(ctx, func() error {
+		var err error
+		res, err = s.manager.GetRankings(id(name, pb.SketchType_RANK), datamodel.RankingsOptions{})
+		return err
+	})
+	return res.GetRankings(), err
+}
+
+// RangeCount returns the number of values between lo and hi, both included,
+// in the RANGE sketch called name
+func (s *Skizze) RangeCount(ctx context.Context, name string, lo, hi int64) (*pb.RangeCountResult, error) {
+	var res *pb.RangeCountResult
+	err := s.read(ctx, func() error {
+		var err error
+		res, err = s.manager.GetRangeCount(id(name, pb.SketchType_RANGE), lo, hi)
+		return err
+	})
+	return res, err
+}
+
+// Sample returns the values sampled by the SAMP sketch called name
+func (s *Skizze) Sample(ctx context.Context, name string) (*pb.SampleResult, error) {
+	var res *pb.SampleResult
+	err := s.read(ctx, func() error {
+		var err error
+		res, err = s.manager.GetSample(id(name, pb.SketchType_SAMP))
+		return err
+	})
+	return res, err
+}
+
+// Groups returns the results of the groups of the given keys in the KCARD
+// or KRANK sketch called name
+func (s *Skizze) Groups(ctx context.Context, name string, typ pb.SketchType, keys ...string) ([]*pb.GroupResult, error) {
+	var res []*pb.GroupResult
+	err := s.read(ctx, func() error {
+		var err error
+		res, _, err = s.manager.GetGroups(id(name, typ), keys, datamodel.GroupOptions{})
+		return err
+	})
+	return res, err
+}
+
+// TopGroups returns up to limit groups (10 if limit is 0) with the largest
+// cardinality in the KCARD sketch called name
+func (s *Skizze) TopGroups(ctx context.Context, name string, limit int) ([]*pb.GroupResult, error) {
+	var res []*pb.GroupResult
+	err := s.read(ctx, func() error {
+		var err error
+		res, _, err = s.manager.GetGroups(id(name, pb.SketchType_KCARD), nil, datamodel.GroupOptions{Limit: limit})
+		return err
+	})
+	return res, err
+}
+
+// CardinalityExpression estimates the cardinality of unions, intersections
+// and differences of sketches of type typ (CARD or THETA) and domains, with
+// bounds at the given confidence (0 for the default)
+func (s *Skizze) CardinalityExpression(ctx context.Context, typ pb.SketchType, expr *pb.CardinalityExpression, confidence float64) (*pb.CardinalityExpressionReply, error) {
+	var reply *pb.CardinalityExpressionReply
+	err := s.read(ctx, func() error {
+		var err error
+		reply, err = s.manager.GetCardinalityExpression(expr, typ, confidence)
+		return err
+	})
+	return reply, err
+}
+
+// FindSimilar returns up to limit MINHASH sketches (10 if limit is 0) ranked
+// by the similarity of their values to those of the MINHASH sketch called
+// name. With lsh only the sketches likely to be similar are compared.
+func (s *Skizze) FindSimilar(ctx context.Context, name string, limit int, lsh bool) ([]*pb.Similarity, error) {
+	var res []*pb.Similarity
+	err := s.read(ctx, func() error {
+		var err error
+		res, err = s.manager.FindSimilar(id(name, pb.SketchType_MINHASH), nil,
+			datamodel.SimilarityOptions{Limit: limit, LSH: lsh})
+		return err
+	})
+	return res, err
+}
+
+// Similarity returns the Jaccard similarity of the values of the MINHASH
+// sketches called name and other
+func (s *Skizze) Similarity(ctx context.Context, name string, other string) (*pb.Similarity, error) {
+	var res []*pb.Similarity
+	err := s.read(ctx, func() error {
+		var err error
+		res, err = s.manager.FindSimilar(id(name, pb.SketchType_MINHASH),
+			[]string{id(other, pb.SketchType_MINHASH)}, datamodel.SimilarityOptions{})
+		return err
+	})
+	if err != nil || len(res) == 0 {
+		return nil, err
+	}
+	return res[0], nil
+}
@@ -0,0 +1,157 @@
+package embedded
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"config"
+	pb "datamodel/protobuf"
+	"manager"
+	"testutils"
+	"utils"
+)
+
+func TestDomain(t *testing.T) {
+	s, err := Open(Options{})
+	if err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+
+	if err := s.CreateDomain(ctx, "marvel"); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	results, err := s.Add(ctx, "marvel", "hulk", "thor", "hulk")
+	if err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if len(results) != 4 {
+		t.Error("Expected 4 add results, got", results)
+	}
+
+	if card, err := s.Cardinality(ctx, "marvel"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if card != 2 {
+		t.Error("Expected cardinality 2, got", card)
+	}
+	if freqs, err := s.Frequency(ctx, "marvel", "hulk", "loki"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if freqs["hulk"] != 2 || freqs["loki"] != 0 {
+		t.Error("Expected hulk == 2 and loki == 0, got", freqs)
+	}
+	if members, err := s.Membership(ctx, "marvel", "thor", "loki"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if !members["thor"] || members["loki"] {
+		t.Error("Expected thor to be a member and loki not, got", members)
+	}
+	if ranks, err := s.TopK(ctx, "marvel"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if len(ranks) != 2 || ranks[0].GetValue() != "hulk" {
+		t.Error("Expected [hulk thor], got", ranks)
+	}
+
+	if err := s.DeleteDomain(ctx, "marvel"); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if _, err := s.Cardinality(ctx, "marvel"); err == nil {
+		t.Error("Expected error querying a deleted domain, got", err)
+	} else if _, ok := err.(*manager.NotFoundError); !ok {
+		t.Errorf("Expected NotFoundError, got %T", err)
+	}
+}
+
+func TestSketch(t *testing.T) {
+	s, err := Open(Options{})
+	if err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+
+	props := &pb.SketchProperties{MaxUniqueItems: utils.Int64p(1000)}
+	if err := s.CreateSketch(ctx, "marvel", pb.SketchType_CARD, props); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if res, err := s.AddToSketch(ctx, "marvel", pb.SketchType_CARD, "hulk", "thor"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if !res.GetSuccess() {
+		t.Error("Expected success, got", res)
+	}
+	if _, err := s.AddToSketch(ctx, "marvel", pb.SketchType_FREQ, "hulk"); err == nil {
+		t.Error("Expected error adding to a non-existing sketch, got", err)
+	}
+	if err := s.DeleteSketch(ctx, "marvel", pb.SketchType_CARD); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+}
+
+func TestCanceledContext(t *testing.T) {
+	s, err := Open(Options{})
+	if err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := s.CreateDomain(ctx, "marvel"); err != context.Canceled {
+		t.Error("Expected context.Canceled, got", err)
+	}
+	if _, err := s.Cardinality(ctx, "marvel"); err != context.Canceled {
+		t.Error("Expected context.Canceled, got", err)
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+	ctx := context.Background()
+
+	s, err := Open(Options{DataDir: config.DataDir})
+	if err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if err := s.CreateDomain(ctx, "marvel"); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if _, err := s.Add(ctx, "marvel", "hulk", "thor", "hulk"); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if err := s.CreateSketch(ctx, "dc", pb.SketchType_CARD, nil); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	props := &pb.SketchProperties{MaxUniqueItems: utils.Int64p(1000)}
+	if err := s.CreateSketch(ctx, "seen", pb.SketchType_MEMB, props); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if present, err := s.AddIfAbsent(ctx, "seen", "hulk"); err != nil || present[0] {
+		t.Error("Expected hulk to be absent, got", present, err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+
+	s, err = Open(Options{DataDir: config.DataDir})
+	if err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	defer func() { _ = s.Close() }()
+	if freqs, err := s.Frequency(ctx, "marvel", "hulk"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if freqs["hulk"] != 2 {
+		t.Error("Expected hulk == 2, got", freqs)
+	}
+	if present, err := s.AddIfAbsent(ctx, "seen", "hulk", "thor"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if !present[0] || present[1] {
+		t.Error("Expected hulk to be present and thor not, got", present)
+	}
+	if card, err := s.Cardinality(ctx, "dc"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if card != 0 {
+		t.Error("Expected cardinality 0, got", card)
+	}
+}
@@ -0,0 +1,102 @@
+package manager
+
+import (
+	"sort"
+	"sync"
+
+	"datamodel"
+	pb "datamodel/protobuf"
+	"sketches"
+)
+
+// bandKey is a band of the signature of a similarity sketch: its type, its
+// position and the key of its rows. Sketches of different sizes may share a
+// bandKey, they are told apart when compared.
+type bandKey struct {
+	typ  pb.SketchType
+	band int
+	key  uint64
+}
+
+// bandIndex maps the bands of the similarity sketches to the IDs of the
+// sketches holding them, so that a search with LSH only compares the
+// sketches sharing a band with the target instead of walking all of them
+type bandIndex struct {
+	lock sync.Mutex
+	ids  map[bandKey]map[string]struct{}
+	byID map[string][]bandKey
+}
+
+func newBandIndex() *bandIndex {
+	return &bandIndex{
+		ids:  make(map[bandKey]map[string]struct{}),
+		byID: make(map[string][]bandKey),
+	}
+}
+
+// update indexes the current bands of the sketch id, the ones of sketches
+// that do not answer similarity queries are not indexed
+func (idx *bandIndex) update(id string, sketch *sketches.SketchProxy) {
+	if t, ok := datamodel.LookupType(sketch.GetType()); !ok || !t.Can(datamodel.SimilarityQuery) {
+		return
+	}
+	// The bands are read under the index lock, so that of two concurrent
+	// adds the one updating the index last leaves the bands of both
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+	idx.removeLocked(id)
+	bands, err := sketch.Bands()
+	if err != nil {
+		return
+	}
+	keys := make([]bandKey, len(bands))
+	for i, key := range bands {
+		keys[i] = bandKey{sketch.GetType(), i, key}
+		ids, ok := idx.ids[keys[i]]
+		if !ok {
+			ids = make(map[string]struct{})
+			idx.ids[keys[i]] = ids
+		}
+		ids[id] = struct{}{}
+	}
+	if len(keys) > 0 {
+		idx.byID[id] = keys
+	}
+}
+
+func (idx *bandIndex) remove(id string) {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+	idx.removeLocked(id)
+}
+
+func (idx *bandIndex) removeLocked(id string) {
+	for _, key := range idx.byID[id] {
+		ids := idx.ids[key]
+		delete(ids, id)
+		if len(ids) == 0 {
+			delete(idx.ids, key)
+		}
+	}
+	delete(idx.byID, id)
+}
+
+// candidates returns the IDs of the sketches other than id sharing at least
+// one band with bands, sorted
+func (idx *bandIndex) candidates(id string, typ pb.SketchType, bands []uint64) []string {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+	seen := make(map[string]struct{})
+	var candidates []string
+	for i, key := range bands {
+		for cid := range idx.ids[bandKey{typ, i, key}] {
+			if _, ok := seen[cid]; ok || cid == id {
+				continue
+			}
+			seen[cid] = struct{}{}
+			candidates = append(candidates, cid)
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
@@ -1,8 +1,10 @@
 package manager
 
 import (
-	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/gogo/protobuf/proto"
 
@@ -10,116 +12,424 @@ import (
 	pb "datamodel/protobuf"
 )
 
+// domain holds the ids of the sketches of a domain
+type domain struct {
+	sketches []string
+	// lock keeps queries of the domain from interleaving with its adds,
+	// adds to other domains are not held up
+	lock sync.RWMutex
+}
+
 type domainManager struct {
-	domains  map[string][]string
+	domains  map[string]*domain
 	sketches *sketchManager
 	info     *infoManager
 }
 
 func newDomainManager(info *infoManager, sketches *sketchManager) *domainManager {
 	return &domainManager{
-		domains:  make(map[string][]string),
+		domains:  make(map[string]*domain),
 		info:     info,
 		sketches: sketches,
 	}
 }
 
-func (m *domainManager) create(id string, infos map[string]*datamodel.Info) error {
+func (m *domainManager) create(id string, infos []*datamodel.Info) error {
 	if _, ok := m.domains[id]; ok {
-		return fmt.Errorf(`Domain with name "%s" already exists`, id)
+		return errAlreadyExists(`Domain with name "%s" already exists`, id)
 	}
 
-	var err error
-	var ids []string
-	tmpInfos := make(map[string]*datamodel.Info)
-	tmpSketches := make(map[string]*datamodel.Info)
-	for id, info := range infos {
-		if err = m.info.create(info); err != nil {
-			break
+	ids := make([]string, 0, len(infos))
+	for _, info := range infos {
+		if err := m.info.create(info); err != nil {
+			m.rollback(ids)
+			return err
 		}
-		tmpInfos[id] = info
-		if err = m.sketches.create(info); err != nil {
-			break
+		if err := m.sketches.create(info); err != nil {
+			if err2 := m.info.delete(info.ID()); err2 != nil {
+				logger.Errorf("%q\n", err2)
+			}
+			m.rollback(ids)
+			return err
 		}
-		tmpSketches[id] = info
 		ids = append(ids, info.ID())
 	}
 
-	if len(tmpInfos) != len(infos) {
-		for _, v := range tmpInfos {
-			if err := m.info.delete(v.ID()); err != nil {
-				// TODO: print out something
+	m.domains[id] = &domain{sketches: ids}
+	return nil
+}
+
+// rollback removes the sketches of a domain that could not be fully created
+func (m *domainManager) rollback(ids []string) {
+	for _, id := range ids {
+		if err := m.sketches.delete(id); err != nil {
+			logger.Errorf("%q\n", err)
+		}
+		if err := m.info.delete(id); err != nil {
+			logger.Errorf("%q\n", err)
+		}
+	}
+}
+
+// attach adds a sketch to an existing domain. The sketch is adopted if it
+// already exists, otherwise a new one named after the domain is created.
+func (m *domainManager) attach(id string, info *datamodel.Info) error {
+	dom, ok := m.domains[id]
+	if !ok {
+		return errNotFound(`Domain "%s" does not exists`, id)
+	}
+	dom.lock.Lock()
+	defer dom.lock.Unlock()
+	for _, sid := range dom.sketches {
+		if s := m.info.get(sid); s != nil && s.GetType() == info.GetType() {
+			return errAlreadyExists(`Domain "%s" already has a sketch of type %s`, id, info.GetType())
+		}
+	}
+	if owner, ok := m.owner(info.ID()); ok {
+		return errAlreadyExists(`Sketch "%s" already belongs to domain "%s"`, info.ID(), owner)
+	}
+
+	if m.info.get(info.ID()) == nil {
+		if info.GetName() != id {
+			return errNotFound(`Sketch "%s" does not exists`, info.ID())
+		}
+		if err := m.info.create(info); err != nil {
+			return err
+		}
+		if err := m.sketches.create(info); err != nil {
+			if err2 := m.info.delete(info.ID()); err2 != nil {
+				logger.Errorf("%q\n", err2)
+			}
+			return err
+		}
+	}
+	dom.sketches = append(dom.sketches, info.ID())
+	return nil
+}
+
+// detach removes a sketch from a domain, leaving it as a standalone sketch
+func (m *domainManager) detach(id string, sketchID string) error {
+	dom, ok := m.domains[id]
+	if !ok {
+		return errNotFound(`Domain "%s" does not exists`, id)
+	}
+	dom.lock.Lock()
+	defer dom.lock.Unlock()
+	for i, sid := range dom.sketches {
+		if sid == sketchID {
+			dom.sketches = append(dom.sketches[:i:i], dom.sketches[i+1:]...)
+			return nil
+		}
+	}
+	return errNotFound(`Sketch "%s" is not attached to domain "%s"`, sketchID, id)
+}
+
+// owner returns the name of the domain the sketch belongs to
+func (m *domainManager) owner(sketchID string) (string, bool) {
+	for id, dom := range m.domains {
+		for _, sid := range dom.sketches {
+			if sid == sketchID {
+				return id, true
 			}
 		}
 	}
-	if len(tmpSketches) != len(infos) {
-		for _, v := range tmpSketches {
-			if err := m.sketches.delete(v.ID()); err != nil {
-				// TODO: print out something
+	return "", false
+}
+
+// renamedID returns the ID a sketch would have under a different name
+func renamedID(info *datamodel.Info, name string) string {
+	renamed := info.Copy()
+	renamed.Name = proto.String(name)
+	return renamed.ID()
+}
+
+// renameSketch re-keys a sketch under a new name, keeping its domain (if any)
+// pointing at it
+func (m *domainManager) renameSketch(id string, name string) error {
+	info := m.info.get(id)
+	if info == nil {
+		return errNotFound(`Sketch "%s" does not exists`, id)
+	}
+	newID := renamedID(info, name)
+	if m.info.get(newID) != nil {
+		return errAlreadyExists(`Sketch "%s" already exists`, newID)
+	}
+	if err := m.sketches.rename(id, newID); err != nil {
+		return err
+	}
+	if err := m.info.rename(id, newID); err != nil {
+		return err
+	}
+	info.Rename(name)
+	if owner, ok := m.owner(id); ok {
+		dom := m.domains[owner]
+		dom.lock.Lock()
+		defer dom.lock.Unlock()
+		for i, sid := range dom.sketches {
+			if sid == id {
+				dom.sketches[i] = newID
+			}
+		}
+	}
+	return nil
+}
+
+// ownSketches returns the infos of the domain's sketches that are named after
+// it, as opposed to the ones that were adopted
+func (m *domainManager) ownSketches(id string) []*datamodel.Info {
+	var infos []*datamodel.Info
+	for _, sid := range m.domains[id].sketches {
+		if info := m.info.get(sid); info != nil && info.GetName() == id {
+			infos = append(infos, info)
+		}
+	}
+	return infos
+}
+
+// rename re-keys a domain and the sketches named after it. All new names are
+// checked before anything is renamed.
+func (m *domainManager) rename(id string, name string) error {
+	dom, ok := m.domains[id]
+	if !ok {
+		return errNotFound(`Domain "%s" does not exists`, id)
+	}
+	if _, ok := m.domains[name]; ok {
+		return errAlreadyExists(`Domain with name "%s" already exists`, name)
+	}
+	infos := m.ownSketches(id)
+	for _, info := range infos {
+		if newID := renamedID(info, name); m.info.get(newID) != nil {
+			return errAlreadyExists(`Sketch "%s" already exists`, newID)
+		}
+	}
+	// renameSketch locks the domain of every sketch it renames
+	for _, info := range infos {
+		if err := m.renameSketch(info.ID(), name); err != nil {
+			return err
+		}
+	}
+	m.domains[name] = dom
+	delete(m.domains, id)
+	return nil
+}
+
+// clone deep-copies every sketch of a domain into a new domain, naming the
+// copies after it
+func (m *domainManager) clone(id string, name string) error {
+	dom, ok := m.domains[id]
+	if !ok {
+		return errNotFound(`Domain "%s" does not exists`, id)
+	}
+	if _, ok := m.domains[name]; ok {
+		return errAlreadyExists(`Domain with name "%s" already exists`, name)
+	}
+	dom.lock.RLock()
+	defer dom.lock.RUnlock()
+
+	clones := make(map[string]*datamodel.Info)
+	for _, sid := range dom.sketches {
+		info := m.info.get(sid)
+		if info == nil {
+			continue
+		}
+		clone := info.Copy()
+		clone.Name = proto.String(name)
+		if m.info.get(clone.ID()) != nil {
+			return errAlreadyExists(`Sketch "%s" already exists`, clone.ID())
+		}
+		clones[sid] = clone
+	}
+
+	newIDs := make([]string, 0, len(clones))
+	for sid, info := range clones {
+		if err := m.info.create(info); err != nil {
+			m.rollback(newIDs)
+			return err
+		}
+		if err := m.sketches.clone(sid, info); err != nil {
+			if err2 := m.info.delete(info.ID()); err2 != nil {
+				logger.Errorf("%q\n", err2)
 			}
+			m.rollback(newIDs)
+			return err
 		}
+		newIDs = append(newIDs, info.ID())
 	}
+	m.domains[name] = &domain{sketches: newIDs}
+	return nil
+}
 
-	if len(ids) < len(datamodel.GetTypes()) {
-		return fmt.Errorf("Not enough sketches")
+// clear resets the state of every sketch of a domain
+func (m *domainManager) clear(id string) error {
+	dom, ok := m.domains[id]
+	if !ok {
+		return errNotFound(`Domain "%s" does not exists`, id)
+	}
+	dom.lock.Lock()
+	defer dom.lock.Unlock()
+	for _, sid := range dom.sketches {
+		if err := m.sketches.clear(sid); err != nil {
+			return err
+		}
 	}
-	m.domains[id] = ids
 	return nil
 }
 
 // FIXME: maybe return a list of errors?
 func (m *domainManager) delete(id string) error {
+	dom, ok := m.domains[id]
+	if !ok {
+		return errNotFound(`Domain "%s" does not exists`, id)
+	}
+	dom.lock.Lock()
+	defer dom.lock.Unlock()
 	var lastErr error
-	if ids, ok := m.domains[id]; ok {
-		for _, id := range ids {
-			if info := m.info.get(id); info != nil {
-				if err := m.sketches.delete(info.ID()); err != nil {
-					// TODO: print something ?
-					lastErr = err
-				}
-				if err := m.info.delete(info.ID()); err != nil {
-					// TODO: print something ?
-					lastErr = err
-				}
+	for _, id := range dom.sketches {
+		if info := m.info.get(id); info != nil {
+			if err := m.sketches.delete(info.ID()); err != nil {
+				// TODO: print something ?
+				lastErr = err
+			}
+			if err := m.info.delete(info.ID()); err != nil {
+				// TODO: print something ?
+				lastErr = err
 			}
 		}
 	}
 	delete(m.domains, id)
-	// FIXME: return error if not exist ?
 	return lastErr
 }
 
-func (m *domainManager) add(id string, values []string) error {
-	sketches, ok := m.domains[id]
+// add adds values to every sketch of a domain and reports the outcome per sketch
+func (m *domainManager) add(id string, values []string, at time.Time) ([]*pb.AddResult, error) {
+	dom, ok := m.domains[id]
 
 	if !ok {
-		return fmt.Errorf(`Domain "%s" does not exists`, id)
+		return nil, errNotFound(`Domain "%s" does not exists`, id)
 	}
 
+	dom.lock.Lock()
+	defer dom.lock.Unlock()
+	sketches := dom.sketches
+
 	var wg sync.WaitGroup
 	wg.Add(len(sketches))
 
-	for _, sketch := range sketches {
-		go func(sk string) {
-			if err := m.sketches.add(sk, values); err != nil {
+	results := make([]*pb.AddResult, len(sketches))
+	for i, sketch := range sketches {
+		go func(i int, sk string) {
+			applied, err := m.sketches.add(sk, values, at)
+			if err != nil {
 				logger.Errorf("%q\n", err)
 			}
+			results[i] = newAddResult(m.sketch(sk), applied, err)
 			wg.Done()
-		}(sketch)
+		}(i, sketch)
 	}
 
 	wg.Wait()
-	return nil
+	return results, nil
+}
+
+// query reads every sketch of a domain. No add to the domain can happen in
+// between the reads, so all results reflect the same set of values.
+func (m *domainManager) query(id string, values []string, confidence float64) ([]*pb.QueryResult, error) {
+	dom, ok := m.domains[id]
+	if !ok {
+		return nil, errNotFound(`Domain "%s" does not exists`, id)
+	}
+
+	dom.lock.RLock()
+	defer dom.lock.RUnlock()
+	sketches := dom.sketches
+
+	results := make([]*pb.QueryResult, 0, len(sketches))
+	for _, sid := range sketches {
+		info := m.info.get(sid)
+		if info == nil {
+			continue
+		}
+		result, err := m.sketches.query(sid, values, confidence)
+		if err != nil {
+			return nil, err
+		}
+		result.Sketch = info.Sketch
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// rlock holds the lock of every named domain that exists for reading, so
+// that no add to them happens until the returned function is called
+func (m *domainManager) rlock(ids []string) func() {
+	sorted := append([]string(nil), ids...)
+	sort.Strings(sorted)
+	var held []*domain
+	for i, id := range sorted {
+		dom, ok := m.domains[id]
+		if !ok || i > 0 && sorted[i-1] == id {
+			continue
+		}
+		dom.lock.RLock()
+		held = append(held, dom)
+	}
+	return func() {
+		for _, dom := range held {
+			dom.lock.RUnlock()
+		}
+	}
+}
+
+// typedSketch returns the id of the sketch of type typ of a domain,
+// preferring the one named after it over adopted ones
+func (m *domainManager) typedSketch(id string, typ pb.SketchType) (string, error) {
+	dom, ok := m.domains[id]
+	if !ok {
+		return "", errNotFound(`Domain "%s" does not exists`, id)
+	}
+	found := ""
+	for _, sid := range dom.sketches {
+		info := m.info.get(sid)
+		if info == nil || info.GetType() != typ {
+			continue
+		}
+		if info.GetName() == id {
+			return sid, nil
+		}
+		if found == "" {
+			found = sid
+		}
+	}
+	if found == "" {
+		return "", errInvalidArgument(`Domain "%s" has no %s sketch`, id, typ)
+	}
+	return found, nil
+}
+
+// sketch returns the sketch with the given id, rebuilding it from the id if
+// its info is gone
+func (m *domainManager) sketch(id string) *pb.Sketch {
+	if info := m.info.get(id); info != nil {
+		return info.Sketch
+	}
+	sketch := &pb.Sketch{Name: proto.String(id)}
+	if i := strings.LastIndex(id, "."); i >= 0 {
+		if typ, ok := datamodel.ParseTypeName(id[i+1:]); ok {
+			sketch.Name = proto.String(id[:i])
+			sketch.Type = &typ
+		}
+	}
+	return sketch
 }
 
 // FIXME: return all sketches with domain
 func (m *domainManager) get(id string) (*pb.Domain, error) {
-	sketchIds, ok := m.domains[id]
+	dom, ok := m.domains[id]
 	if !ok {
-		return nil, fmt.Errorf("Could not find domain %s", id)
+		return nil, errNotFound("Could not find domain %s", id)
 	}
 	var sketches []*pb.Sketch
-	for _, id := range sketchIds {
+	for _, id := range dom.sketches {
 		s := m.info.get(id)
 		if s != nil {
 			sketches = append(sketches, s.Sketch)
@@ -0,0 +1,69 @@
+package manager
+
+import "fmt"
+
+// NotFoundError is returned when a sketch or domain does not exist
+type NotFoundError struct {
+	msg string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.msg
+}
+
+// AlreadyExistsError is returned when a sketch or domain name is already taken
+type AlreadyExistsError struct {
+	msg string
+}
+
+func (e *AlreadyExistsError) Error() string {
+	return e.msg
+}
+
+// InvalidArgumentError is returned when a request carries an invalid type or
+// invalid properties
+type InvalidArgumentError struct {
+	msg string
+}
+
+func (e *InvalidArgumentError) Error() string {
+	return e.msg
+}
+
+// LockedError is returned when a sketch is locked and can not be modified
+type LockedError struct {
+	msg string
+}
+
+func (e *LockedError) Error() string {
+	return e.msg
+}
+
+// ResourceExhaustedError is returned when a sketch could not be allocated
+type ResourceExhaustedError struct {
+	msg string
+}
+
+func (e *ResourceExhaustedError) Error() string {
+	return e.msg
+}
+
+func errNotFound(format string, args ...interface{}) error {
+	return &NotFoundError{fmt.Sprintf(format, args...)}
+}
+
+func errAlreadyExists(format string, args ...interface{}) error {
+	return &AlreadyExistsError{fmt.Sprintf(format, args...)}
+}
+
+func errInvalidArgument(format string, args ...interface{}) error {
+	return &InvalidArgumentError{fmt.Sprintf(format, args...)}
+}
+
+func errLocked(format string, args ...interface{}) error {
+	return &LockedError{fmt.Sprintf(format, args...)}
+}
+
+func errResourceExhausted(format string, args ...interface{}) error {
+	return &ResourceExhaustedError{fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,325 @@
+package manager
+
+import (
+	"fmt"
+	"math"
+
+	"datamodel"
+	pb "datamodel/protobuf"
+	"sketches"
+	"utils"
+)
+
+// maxExpressionSketches bounds the number of distinct CARD sketches an
+// expression with intersections or differences can use, it takes the
+// cardinality of up to 2^n - 1 unions of them
+const maxExpressionSketches = 8
+
+// maxExpressionRelativeError is the relative width of the bounds above which
+// a warning is returned with the estimate
+const maxExpressionRelativeError = 0.25
+
+// expression is a validated set expression over sketches of one type, every
+// leaf refers to one of sketches by its index
+type expression struct {
+	typ      *datamodel.SketchType
+	sketches []string
+	root     *expressionNode
+	// unionOnly is true when the expression is a union of all its sketches
+	unionOnly bool
+}
+
+type expressionNode struct {
+	op       pb.SetOperation
+	sketch   int
+	operands []*expressionNode
+}
+
+// contains tells whether the values in exactly the sketches of the bitmask
+// set belong to the expression
+func (n *expressionNode) contains(set uint) bool {
+	if n.operands == nil {
+		return set&(1<<uint(n.sketch)) != 0
+	}
+	switch n.op {
+	case pb.SetOperation_UNION:
+		for _, o := range n.operands {
+			if o.contains(set) {
+				return true
+			}
+		}
+		return false
+	case pb.SetOperation_INTERSECTION:
+		for _, o := range n.operands {
+			if !o.contains(set) {
+				return false
+			}
+		}
+		return true
+	default: // DIFFERENCE
+		for _, o := range n.operands[1:] {
+			if o.contains(set) {
+				return false
+			}
+		}
+		return n.operands[0].contains(set)
+	}
+}
+
+// parseExpression resolves the leaves of an expression to sketches of the
+// given type
+func (m *Manager) parseExpression(in *pb.CardinalityExpression, typ pb.SketchType) (*expression, error) {
+	t, ok := datamodel.LookupType(typ)
+	if !ok || !t.Can(datamodel.CardinalityQuery) {
+		return nil, errInvalidArgument("Sketches of type %s can not be used in a cardinality expression", typ)
+	}
+	expr := &expression{typ: t, unionOnly: true}
+	index := make(map[string]int)
+	var parse func(in *pb.CardinalityExpression) (*expressionNode, error)
+	parse = func(in *pb.CardinalityExpression) (*expressionNode, error) {
+		if in == nil {
+			return nil, errInvalidArgument("Missing expression")
+		}
+		if in.Sketch != nil || in.Domain != nil {
+			if in.Sketch != nil && in.Domain != nil || in.Op != nil || len(in.Operands) > 0 {
+				return nil, errInvalidArgument("An expression must either name a sketch or a domain, or apply an operation")
+			}
+			id, err := m.expressionSketch(in, typ)
+			if err != nil {
+				return nil, err
+			}
+			i, ok := index[id]
+			if !ok {
+				i = len(expr.sketches)
+				index[id] = i
+				expr.sketches = append(expr.sketches, id)
+			}
+			return &expressionNode{sketch: i}, nil
+		}
+		if in.Op == nil || len(in.Operands) == 0 {
+			return nil, errInvalidArgument("An expression must either name a sketch or a domain, or apply an operation to operands")
+		}
+		if in.GetOp() != pb.SetOperation_UNION {
+			expr.unionOnly = false
+		}
+		node := &expressionNode{op: in.GetOp(), operands: make([]*expressionNode, len(in.Operands))}
+		for i, o := range in.Operands {
+			operand, err := parse(o)
+			if err != nil {
+				return nil, err
+			}
+			node.operands[i] = operand
+		}
+		return node, nil
+	}
+	root, err := parse(in)
+	if err != nil {
+		return nil, err
+	}
+	expr.root = root
+	if !expr.unionOnly && !t.Can(datamodel.SetQuery) && len(expr.sketches) > maxExpressionSketches {
+		return nil, errInvalidArgument("Expression uses %d sketches, intersections and differences support up to %d",
+			len(expr.sketches), maxExpressionSketches)
+	}
+	return expr, nil
+}
+
+// expressionDomains returns the names of the domains an expression refers to
+func expressionDomains(in *pb.CardinalityExpression) []string {
+	if in == nil {
+		return nil
+	}
+	if in.Domain != nil {
+		return []string{in.GetDomain()}
+	}
+	var names []string
+	for _, o := range in.Operands {
+		names = append(names, expressionDomains(o)...)
+	}
+	return names
+}
+
+// expressionSketch returns the id of the sketch of the given type a leaf
+// refers to
+func (m *Manager) expressionSketch(in *pb.CardinalityExpression, typ pb.SketchType) (string, error) {
+	if in.Domain != nil {
+		return m.domains.typedSketch(in.GetDomain(), typ)
+	}
+	sketch := in.GetSketch()
+	if sketch.GetType() != typ {
+		return "", errInvalidArgument("Sketch %s of type %s can not be used in a cardinality expression",
+			sketch.GetName(), sketch.GetType())
+	}
+	id := (&datamodel.Info{Sketch: sketch}).ID()
+	if m.infos.get(id) == nil {
+		return "", errNotFound("No such sketch %s", id)
+	}
+	return id, nil
+}
+
+// coefficients writes the cardinality of the expression as a sum of the
+// cardinalities of unions of its sketches, keyed by the bitmask of the
+// sketches in the union. Every set of sketches T the expression contains
+// adds the values in exactly the sketches of T, which by inclusion-exclusion
+// over the subsets Q of T is
+//
+//	sum (-1)^|Q| (|U(all)| - |U(not T + Q)|)
+//
+// with U(s) the union of the sketches in s.
+func (e *expression) coefficients() map[uint]int {
+	coefs := make(map[uint]int)
+	all := uint(1)<<uint(len(e.sketches)) - 1
+	if e.unionOnly {
+		coefs[all] = 1
+		return coefs
+	}
+	for set := uint(1); set <= all; set++ {
+		if !e.root.contains(set) {
+			continue
+		}
+		// Enumerate the subsets of set, down to and including the empty one
+		for sub := set; ; sub = (sub - 1) & set {
+			sign := 1
+			if bitCount(sub)%2 == 1 {
+				sign = -1
+			}
+			coefs[all] += sign
+			if rest := all&^set | sub; rest != 0 {
+				coefs[rest] -= sign
+			}
+			if sub == 0 {
+				break
+			}
+		}
+	}
+	for s, c := range coefs {
+		if c == 0 {
+			delete(coefs, s)
+		}
+	}
+	return coefs
+}
+
+func bitCount(x uint) int {
+	n := 0
+	for ; x != 0; x &= x - 1 {
+		n++
+	}
+	return n
+}
+
+// union returns the cardinality of the union of the sketches in set
+func (m *Manager) union(e *expression, set uint, confidence float64) (*pb.CardinalityResult, error) {
+	var proxies []*sketches.SketchProxy
+	for i, id := range e.sketches {
+		if set&(1<<uint(i)) == 0 {
+			continue
+		}
+		sketch, err := m.sketches.get(id)
+		if err != nil {
+			return nil, err
+		}
+		proxies = append(proxies, sketch)
+	}
+	if len(proxies) == 1 {
+		return proxies[0].Cardinality(confidence)
+	}
+	merged, err := sketches.Combine(proxies[0].Info, pb.SetOperation_UNION, proxies...)
+	if err != nil {
+		return nil, unsupported(err)
+	}
+	return merged.Cardinality(confidence)
+}
+
+// inclusionExclusion estimates the cardinality of an expression from the
+// cardinalities of unions of its sketches
+func (m *Manager) inclusionExclusion(e *expression, confidence float64) (*pb.CardinalityResult, error) {
+	var estimate, variance float64
+	for set, coef := range e.coefficients() {
+		res, err := m.union(e, set, confidence)
+		if err != nil {
+			return nil, err
+		}
+		if e.unionOnly {
+			return res, nil
+		}
+		// The unions are estimated independently, their margins add up in
+		// quadrature
+		margin := float64(res.GetUpper()-res.GetLower()) / 2
+		estimate += float64(coef) * float64(res.GetCardinality())
+		variance += math.Pow(float64(coef)*margin, 2)
+	}
+
+	estimate = math.Max(0, math.Floor(estimate+0.5))
+	margin := math.Sqrt(variance)
+	return &pb.CardinalityResult{
+		Cardinality: utils.Int64p(int64(estimate)),
+		Lower:       utils.Int64p(int64(math.Max(0, math.Floor(estimate-margin)))),
+		Upper:       utils.Int64p(int64(math.Ceil(estimate + margin))),
+		Confidence:  utils.Float32p(float32(confidence)),
+	}, nil
+}
+
+// combine evaluates an expression over sketches that intersect and subtract
+// natively, into a new sketch
+func (m *Manager) combine(e *expression, n *expressionNode) (*sketches.SketchProxy, error) {
+	if n.operands == nil {
+		return m.sketches.get(e.sketches[n.sketch])
+	}
+	operands := make([]*sketches.SketchProxy, len(n.operands))
+	for i, o := range n.operands {
+		operand, err := m.combine(e, o)
+		if err != nil {
+			return nil, err
+		}
+		operands[i] = operand
+	}
+	combined, err := sketches.Combine(operands[0].Info, n.op, operands...)
+	return combined, unsupported(err)
+}
+
+// GetCardinalityExpression estimates the cardinality of unions,
+// intersections and differences of sketches of type typ and of the sketches
+// of that type of domains. Unions are merged exactly. Types that support set
+// operations, such as THETA, intersect and subtract sketches natively, for
+// CARD sketches intersections and differences are derived from the
+// cardinalities of unions by inclusion-exclusion, which adds up their
+// errors. The reply carries a warning when the bounds at the requested
+// confidence (0 for the default) are wide compared to the estimate.
+func (m *Manager) GetCardinalityExpression(in *pb.CardinalityExpression, typ pb.SketchType, confidence float64) (*pb.CardinalityExpressionReply, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	confidence, err := validateConfidence(confidence)
+	if err != nil {
+		return nil, err
+	}
+	defer m.domains.rlock(expressionDomains(in))()
+
+	expr, err := m.parseExpression(in, typ)
+	if err != nil {
+		return nil, err
+	}
+
+	var res *pb.CardinalityResult
+	if expr.typ.Can(datamodel.SetQuery) {
+		var combined *sketches.SketchProxy
+		if combined, err = m.combine(expr, expr.root); err == nil {
+			res, err = combined.Cardinality(confidence)
+		}
+	} else {
+		res, err = m.inclusionExclusion(expr, confidence)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reply := &pb.CardinalityExpressionReply{Result: res}
+	estimate := float64(res.GetCardinality())
+	margin := float64(res.GetUpper()-res.GetLower()) / 2
+	if margin > maxExpressionRelativeError*estimate {
+		reply.Warning = utils.Stringp(fmt.Sprintf(
+			"Estimate %d is imprecise, it may be off by %d at %.0f%% confidence: the result is small compared to the sketches it derives from",
+			int64(estimate), int64(math.Ceil(margin)), confidence*100))
+	}
+	return reply, nil
+}
@@ -1,10 +1,6 @@
 package manager
 
-import (
-	"fmt"
-
-	"datamodel"
-)
+import "datamodel"
 
 type infoManager struct {
 	info map[string]*datamodel.Info
@@ -23,7 +19,7 @@ func (m *infoManager) get(id string) *datamodel.Info {
 
 func (m *infoManager) create(info *datamodel.Info) error {
 	if _, ok := m.info[info.ID()]; ok {
-		return fmt.Errorf(`Sketch of type "%s" with name "%s" already exists`,
+		return errAlreadyExists(`Sketch of type "%s" with name "%s" already exists`,
 			info.GetType(), info.GetName())
 	}
 	m.info[info.ID()] = info
@@ -33,9 +29,21 @@ func (m *infoManager) create(info *datamodel.Info) error {
 // FIXME: should take array or map instead?
 func (m *infoManager) delete(id string) error {
 	if _, ok := m.info[id]; !ok {
-		return fmt.Errorf(`Sketch "%s" already exists`, id)
+		return errNotFound(`Sketch "%s" does not exists`, id)
+	}
+	delete(m.info, id)
+	return nil
+}
+
+func (m *infoManager) rename(id string, newID string) error {
+	info, ok := m.info[id]
+	if !ok {
+		return errNotFound(`Sketch "%s" does not exists`, id)
+	}
+	if _, ok := m.info[newID]; ok {
+		return errAlreadyExists(`Sketch "%s" already exists`, newID)
 	}
-	// FIXME: return error if not exist
 	delete(m.info, id)
+	m.info[newID] = info
 	return nil
 }
@@ -1,12 +1,15 @@
 package manager
 
 import (
-	"fmt"
+	"math"
 	"sort"
 	"strconv"
+	"sync"
+	"time"
 
 	"datamodel"
 	pb "datamodel/protobuf"
+	"utils"
 
 	"github.com/njpatel/loggo"
 )
@@ -17,7 +20,8 @@ func isValidType(info *datamodel.Info) bool {
 	if info.Type == nil {
 		return false
 	}
-	return len(datamodel.GetTypeString(info.GetType())) != 0
+	_, ok := datamodel.LookupType(info.GetType())
+	return ok
 }
 
 // Manager is responsible for manipulating the sketches and syncing to disk
@@ -25,6 +29,10 @@ type Manager struct {
 	infos    *infoManager
 	sketches *sketchManager
 	domains  *domainManager
+	// lock guards the maps of infos, sketches and domains. Operations that
+	// create, delete or re-key sketches and domains hold it for writing, the
+	// others for reading; sketches and domains lock their own state.
+	lock sync.RWMutex
 }
 
 // NewManager ...
@@ -44,8 +52,13 @@ func NewManager() *Manager {
 
 // CreateSketch ...
 func (m *Manager) CreateSketch(info *datamodel.Info) error {
-	if !isValidType(info) {
-		return fmt.Errorf("Can not create sketch of type %s, invalid type.", info.Type)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if err := validateProperties(info); err != nil {
+		return err
+	}
+	if err := validateRange(info); err != nil {
+		return err
 	}
 	if err := m.infos.create(info); err != nil {
 		return err
@@ -53,37 +66,291 @@ func (m *Manager) CreateSketch(info *datamodel.Info) error {
 	if err := m.sketches.create(info); err != nil {
 		// If error occurred during creation of sketch, delete info
 		if err2 := m.infos.delete(info.ID()); err2 != nil {
-			return fmt.Errorf("%q\n%q ", err, err2)
+			logger.Errorf("%q\n", err2)
 		}
 		return err
 	}
 	return nil
 }
 
-// CreateDomain ...
-func (m *Manager) CreateDomain(info *datamodel.Info) error {
-	infos := make(map[string]*datamodel.Info)
-	for _, typ := range datamodel.GetTypesPb() {
-		styp := typ
-		tmpInfo := info.Copy()
-		tmpInfo.Type = &styp
-		infos[tmpInfo.ID()] = tmpInfo
+// CreateDomain creates a sketch for every entry of dom.Sketches, each with its
+// own properties. A domain without sketches gets one sketch of every type that
+// is not optional, with the default properties.
+func (m *Manager) CreateDomain(dom *pb.Domain) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	sketches := dom.GetSketches()
+	if len(sketches) == 0 {
+		for _, t := range datamodel.Types() {
+			if t.Optional {
+				continue
+			}
+			styp := t.Type
+			sketches = append(sketches, &pb.Sketch{Type: &styp})
+		}
+	}
+
+	infos := make([]*datamodel.Info, len(sketches))
+	seen := make(map[pb.SketchType]bool)
+	for i, sketch := range sketches {
+		info := newDomainSketchInfo(dom.GetName(), sketch)
+		if err := validateDomainSketch(info); err != nil {
+			return err
+		}
+		if seen[info.GetType()] {
+			return errInvalidArgument(`Domain "%s" can not have more than one sketch of type %s`,
+				dom.GetName(), info.GetType())
+		}
+		seen[info.GetType()] = true
+		infos[i] = info
+	}
+	return m.domains.create(dom.GetName(), infos)
+}
+
+// newDomainSketchInfo copies the type and properties of a domain sketch into a
+// new info named after the domain
+func newDomainSketchInfo(name string, sketch *pb.Sketch) *datamodel.Info {
+	info := datamodel.NewEmptyInfo()
+	info.Name = utils.Stringp(name)
+	if sketch.Type != nil {
+		typ := sketch.GetType()
+		info.Type = &typ
+	}
+	if props := sketch.GetProperties(); props != nil {
+		info.Properties.MaxUniqueItems = utils.Int64p(props.GetMaxUniqueItems())
+		info.Properties.ErrorRate = utils.Float32p(props.GetErrorRate())
+		info.Properties.Size = utils.Int64p(props.GetSize())
+		if props.HalfLife != nil {
+			info.Properties.HalfLife = utils.Int64p(props.GetHalfLife())
+		}
+		if props.MinValue != nil {
+			info.Properties.MinValue = utils.Int64p(props.GetMinValue())
+		}
+		if props.MaxValue != nil {
+			info.Properties.MaxValue = utils.Int64p(props.GetMaxValue())
+		}
+		if props.Weighted != nil {
+			info.Properties.Weighted = utils.Boolp(props.GetWeighted())
+		}
+	}
+	return info
+}
+
+// validateDomainSketch checks the type and properties of a single domain
+// sketch and fills in the defaults of its type for the properties that were
+// left unset.
+func validateDomainSketch(info *datamodel.Info) error {
+	if err := validateProperties(info); err != nil {
+		return err
+	}
+	props := info.Properties
+	t, _ := datamodel.LookupType(info.GetType())
+	if defaults := t.Defaults; defaults != nil {
+		if props.GetMaxUniqueItems() == 0 && defaults.MaxUniqueItems != nil {
+			props.MaxUniqueItems = utils.Int64p(defaults.GetMaxUniqueItems())
+		}
+		if props.GetErrorRate() == 0 && defaults.ErrorRate != nil {
+			props.ErrorRate = utils.Float32p(defaults.GetErrorRate())
+		}
+		if props.GetSize() == 0 && defaults.Size != nil {
+			props.Size = utils.Int64p(defaults.GetSize())
+		}
+		if props.MaxValue == nil && defaults.MaxValue != nil {
+			props.MaxValue = utils.Int64p(defaults.GetMaxValue())
+		}
+	}
+	return validateRange(info)
+}
+
+// validateProperties checks the type of a sketch and the properties that are
+// common to all types, standalone and domain sketches alike
+func validateProperties(info *datamodel.Info) error {
+	if !isValidType(info) {
+		return errInvalidArgument("Can not create sketch of type %s, invalid type.", info.Type)
+	}
+	props := info.Properties
+	if props.GetMaxUniqueItems() < 0 {
+		return errInvalidArgument("Invalid maxUniqueItems %d for sketch of type %s",
+			props.GetMaxUniqueItems(), info.GetType())
+	}
+	if props.GetSize() < 0 {
+		return errInvalidArgument("Invalid size %d for sketch of type %s",
+			props.GetSize(), info.GetType())
+	}
+	// An unset errorRate is 0, NaN fails both comparisons
+	if e := props.GetErrorRate(); !(e >= 0 && e < 1) {
+		return errInvalidArgument("Invalid errorRate %f for sketch of type %s",
+			props.GetErrorRate(), info.GetType())
+	}
+	if err := validateHalfLife(info); err != nil {
+		return err
+	}
+	return validateWeighted(info)
+}
+
+// validateHalfLife checks that only sketches of types that decay are given a
+// half-life
+func validateHalfLife(info *datamodel.Info) error {
+	halfLife := info.Properties.GetHalfLife()
+	if halfLife < 0 {
+		return errInvalidArgument("Invalid halfLife %d for sketch of type %s",
+			halfLife, info.GetType())
+	}
+	if t, _ := datamodel.LookupType(info.GetType()); halfLife > 0 && !t.Can(datamodel.Decay) {
+		return errInvalidArgument("Sketch of type %s does not decay, it can not have a halfLife",
+			info.GetType())
+	}
+	return nil
+}
+
+// validateWeighted checks that only sketches of types that sample by weight
+// are weighted
+func validateWeighted(info *datamodel.Info) error {
+	if t, _ := datamodel.LookupType(info.GetType()); info.Properties.GetWeighted() && !t.Can(datamodel.Weighting) {
+		return errInvalidArgument("Sketch of type %s does not sample by weight, it can not be weighted",
+			info.GetType())
+	}
+	return nil
+}
+
+// validateRange checks the bounds of the values of RANGE sketches
+func validateRange(info *datamodel.Info) error {
+	props := info.Properties
+	if t, _ := datamodel.LookupType(info.GetType()); !t.Can(datamodel.RangeQuery) {
+		return nil
+	}
+	if props.GetMaxValue() < props.GetMinValue() {
+		return errInvalidArgument("Invalid range [%d, %d] for sketch of type %s, maxValue is smaller than minValue",
+			props.GetMinValue(), props.GetMaxValue(), info.GetType())
+	}
+	// The offsets of the values are unsigned, the full int64 span has one too many
+	if uint64(props.GetMaxValue()-props.GetMinValue()) == math.MaxUint64 {
+		return errInvalidArgument("Invalid range [%d, %d] for sketch of type %s, it can hold at most %d values",
+			props.GetMinValue(), props.GetMaxValue(), info.GetType(), uint64(math.MaxUint64))
+	}
+	return nil
+}
+
+// AttachSketch adds a sketch to an existing domain. A sketch without a name is
+// named after the domain; it is adopted if it already exists and created with
+// its own properties otherwise.
+func (m *Manager) AttachSketch(domain string, sketch *pb.Sketch) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	name := sketch.GetName()
+	if len(name) == 0 {
+		name = domain
+	}
+	info := newDomainSketchInfo(name, sketch)
+	if err := validateDomainSketch(info); err != nil {
+		return err
+	}
+	return m.domains.attach(domain, info)
+}
+
+// DetachSketch removes a sketch from a domain without deleting it
+func (m *Manager) DetachSketch(domain string, sketch *pb.Sketch) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	name := sketch.GetName()
+	if len(name) == 0 {
+		name = domain
+	}
+	info := newDomainSketchInfo(name, sketch)
+	return m.domains.detach(domain, info.ID())
+}
+
+// AddToSketch adds values to a sketch and reports whether and how many of
+// them were applied
+func (m *Manager) AddToSketch(id string, values []string) (*pb.AddResult, error) {
+	return m.AddToSketchAt(id, values, time.Now())
+}
+
+// AddToSketchAt adds values seen at the given time to a sketch, the time
+// weighs the values of decaying sketches
+func (m *Manager) AddToSketchAt(id string, values []string, at time.Time) (*pb.AddResult, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	info := m.infos.get(id)
+	if info == nil {
+		return nil, errNotFound(`Sketch "%s" does not exists`, id)
+	}
+	applied, err := m.sketches.add(id, values, at)
+	return newAddResult(info.Sketch, applied, err), nil
+}
+
+// AddToGroups adds every value to the group of its key in a keyed sketch,
+// keys[i] being the key of values[i]
+func (m *Manager) AddToGroups(id string, keys, values []string) (*pb.AddResult, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if len(keys) != len(values) {
+		return nil, errInvalidArgument("Expected a group key per value, got %d keys for %d values",
+			len(keys), len(values))
+	}
+	info := m.infos.get(id)
+	if info == nil {
+		return nil, errNotFound(`Sketch "%s" does not exists`, id)
 	}
-	return m.domains.create(info.GetName(), infos)
+	if t, _ := datamodel.LookupType(info.GetType()); !t.Can(datamodel.GroupQuery) {
+		return nil, errInvalidArgument("Sketch of type %s has no groups, values can not be added with a key",
+			info.GetType())
+	}
+	applied, err := m.sketches.addKeyed(id, keys, values)
+	return newAddResult(info.Sketch, applied, err), nil
+}
+
+// AddToDomain adds values to every sketch of a domain and reports the outcome
+// per sketch
+func (m *Manager) AddToDomain(id string, values []string) ([]*pb.AddResult, error) {
+	return m.AddToDomainAt(id, values, time.Now())
+}
+
+// AddToDomainAt adds values seen at the given time to every sketch of a
+// domain
+func (m *Manager) AddToDomainAt(id string, values []string, at time.Time) ([]*pb.AddResult, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.domains.add(id, values, at)
 }
 
-// AddToSketch ...
-func (m *Manager) AddToSketch(id string, values []string) error {
-	return m.sketches.add(id, values)
+func newAddResult(sketch *pb.Sketch, applied int, err error) *pb.AddResult {
+	status := pb.AddStatus_ADDED
+	switch err.(type) {
+	case nil:
+	case *LockedError:
+		status = pb.AddStatus_LOCKED
+	case *ResourceExhaustedError:
+		status = pb.AddStatus_SATURATED
+	case *NotFoundError:
+		status = pb.AddStatus_MISSING
+	default:
+		status = pb.AddStatus_ERROR
+	}
+	res := &pb.AddResult{
+		Sketch:  sketch,
+		Success: utils.Boolp(err == nil),
+		Applied: utils.Int64p(int64(applied)),
+		Status:  &status,
+	}
+	if err != nil {
+		res.Error = utils.Stringp(err.Error())
+	}
+	return res
 }
 
-// AddToDomain ...
-func (m *Manager) AddToDomain(id string, values []string) error {
-	return m.domains.add(id, values)
+// AddIfAbsent adds the values that are absent from a MEMB sketch, reporting
+// for every value whether it was already present
+func (m *Manager) AddIfAbsent(id string, values []string) ([]*pb.Membership, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.sketches.addIfAbsent(id, values)
 }
 
 // DeleteSketch ...
 func (m *Manager) DeleteSketch(id string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
 	if err := m.infos.delete(id); err != nil {
 		return err
 	}
@@ -92,9 +359,68 @@ func (m *Manager) DeleteSketch(id string) error {
 
 // DeleteDomain ...
 func (m *Manager) DeleteDomain(id string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
 	return m.domains.delete(id)
 }
 
+// ClearSketch resets the state of a sketch, keeping its properties
+func (m *Manager) ClearSketch(id string) error {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.sketches.clear(id)
+}
+
+// RenameSketch re-keys a sketch under a new name
+func (m *Manager) RenameSketch(id string, name string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.domains.renameSketch(id, name)
+}
+
+// CloneSketch deep-copies a sketch to a new name
+func (m *Manager) CloneSketch(id string, name string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	info := m.infos.get(id)
+	if info == nil {
+		return errNotFound(`Sketch "%s" does not exists`, id)
+	}
+	clone := info.Copy()
+	clone.Name = utils.Stringp(name)
+	if err := m.infos.create(clone); err != nil {
+		return err
+	}
+	if err := m.sketches.clone(id, clone); err != nil {
+		if err2 := m.infos.delete(clone.ID()); err2 != nil {
+			logger.Errorf("%q\n", err2)
+		}
+		return err
+	}
+	return nil
+}
+
+// ClearDomain resets the state of every sketch of a domain
+func (m *Manager) ClearDomain(id string) error {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.domains.clear(id)
+}
+
+// RenameDomain re-keys a domain and the sketches named after it
+func (m *Manager) RenameDomain(id string, name string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.domains.rename(id, name)
+}
+
+// CloneDomain deep-copies a domain and its sketches to a new name
+func (m *Manager) CloneDomain(id string, name string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.domains.clone(id, name)
+}
+
 type tupleResult [][2]string
 
 func (slice tupleResult) Len() int {
@@ -114,6 +440,8 @@ func (slice tupleResult) Swap(i, j int) {
 
 // GetSketches return a list of sketch tuples [name, type]
 func (m *Manager) GetSketches() [][2]string {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
 	sketches := tupleResult{}
 	for _, v := range m.infos.info {
 		sketches = append(sketches,
@@ -126,9 +454,11 @@ func (m *Manager) GetSketches() [][2]string {
 
 // GetDomains return a list of sketch tuples [name, type]
 func (m *Manager) GetDomains() [][2]string {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
 	domains := tupleResult{}
 	for k, v := range m.domains.domains {
-		domains = append(domains, [2]string{k, strconv.Itoa(len(v))})
+		domains = append(domains, [2]string{k, strconv.Itoa(len(v.sketches))})
 	}
 	sort.Sort(domains)
 	return domains
@@ -136,21 +466,123 @@ func (m *Manager) GetDomains() [][2]string {
 
 // GetSketch ...
 func (m *Manager) GetSketch(id string) (*datamodel.Info, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
 	info := m.infos.get(id)
 	if info == nil {
-		return nil, fmt.Errorf("No such sketch %s", id)
+		return nil, errNotFound("No such sketch %s", id)
 	}
 	return info, nil
 }
 
 // GetDomain ...
 func (m *Manager) GetDomain(id string) (*pb.Domain, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
 	return m.domains.get(id)
 }
 
-// GetFromSketch ...
-func (m *Manager) GetFromSketch(id string, data interface{}) (interface{}, error) {
-	return m.sketches.get(id, data)
+// QueryDomain returns the results of every sketch of a domain, cardinality
+// bounds are given at the requested confidence (0 for the default)
+func (m *Manager) QueryDomain(id string, values []string, confidence float64) ([]*pb.QueryResult, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	confidence, err := validateConfidence(confidence)
+	if err != nil {
+		return nil, err
+	}
+	return m.domains.query(id, values, confidence)
+}
+
+// GetCardinality returns the cardinality of a CARD sketch together with its
+// bounds at the requested confidence (0 for the default), other sketch types
+// fail with an InvalidArgumentError
+func (m *Manager) GetCardinality(id string, confidence float64) (*pb.CardinalityResult, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	confidence, err := validateConfidence(confidence)
+	if err != nil {
+		return nil, err
+	}
+	return m.sketches.cardinality(id, confidence)
+}
+
+func validateConfidence(confidence float64) (float64, error) {
+	if confidence == 0 {
+		return datamodel.DefaultConfidence, nil
+	}
+	if confidence <= 0 || confidence >= 1 {
+		return 0, errInvalidArgument("Invalid confidence %v, must be between 0 and 1", confidence)
+	}
+	return confidence, nil
+}
+
+// GetFrequency returns the frequencies of values in a FREQ sketch
+func (m *Manager) GetFrequency(id string, values []string) (*pb.FrequencyResult, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.sketches.frequency(id, values)
+}
+
+// GetMembership returns the memberships of values in a MEMB sketch
+func (m *Manager) GetMembership(id string, values []string) (*pb.MembershipResult, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.sketches.membership(id, values)
+}
+
+// GetRankings returns the rankings of a RANK sketch, opts select a page of
+// them
+func (m *Manager) GetRankings(id string, opts datamodel.RankingsOptions) (*pb.RankingsResult, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if opts.Limit < 0 || opts.Offset < 0 || opts.MinCount < 0 {
+		return nil, errInvalidArgument("Invalid rankings limit %d, offset %d or minimum count %d",
+			opts.Limit, opts.Offset, opts.MinCount)
+	}
+	return m.sketches.rankings(id, opts)
+}
+
+// GetRangeCount returns the number of values between lo and hi, both
+// included, in a RANGE sketch
+func (m *Manager) GetRangeCount(id string, lo, hi int64) (*pb.RangeCountResult, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if lo > hi {
+		return nil, errInvalidArgument("Invalid range [%d, %d], lo is larger than hi", lo, hi)
+	}
+	return m.sketches.rangeCount(id, lo, hi)
+}
+
+// GetSample returns the values sampled by the sketch
+func (m *Manager) GetSample(id string) (*pb.SampleResult, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.sketches.sample(id)
+}
+
+// defaultTopGroups is the number of groups a query for the top groups
+// returns when it does not ask for a limit
+const defaultTopGroups = 10
+
+// GetGroups returns the results of the groups of the given keys of a keyed
+// sketch, or of the groups with the largest cardinality if there are no
+// keys, and the number of groups of the sketch
+func (m *Manager) GetGroups(id string, keys []string, opts datamodel.GroupOptions) ([]*pb.GroupResult, int, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if opts.Limit < 0 {
+		return nil, 0, errInvalidArgument("Invalid limit %d, must be positive", opts.Limit)
+	}
+	if opts.Limit == 0 {
+		opts.Limit = defaultTopGroups
+	}
+	confidence, err := validateConfidence(opts.Confidence)
+	if err != nil {
+		return nil, 0, err
+	}
+	opts.Confidence = confidence
+	return m.sketches.groups(id, keys, opts)
 }
 
 // Destroy ...
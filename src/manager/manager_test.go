@@ -2,11 +2,20 @@ package manager
 
 import (
 	"fmt"
+	"math"
+	"path/filepath"
+	"reflect"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
 
 	"config"
 	"datamodel"
 	pb "datamodel/protobuf"
+	"sketches"
+	"storage"
 	"utils"
 	"testutils"
 )
@@ -119,6 +128,8 @@ func TestCreateDuplicateSketch(t *testing.T) {
 	}
 	if err := m.CreateSketch(info); err == nil {
 		t.Error("Expected error (duplicate sketch), got", err)
+	} else if _, ok := err.(*AlreadyExistsError); !ok {
+		t.Errorf("Expected AlreadyExistsError, got %T", err)
 	}
 	if sketches := m.GetSketches(); len(sketches) != 1 {
 		t.Error("Expected 1 sketches, got", len(sketches))
@@ -139,6 +150,28 @@ func TestCreateInvalidSketch(t *testing.T) {
 	info.Type = nil
 	if err := m.CreateSketch(info); err == nil {
 		t.Error("Expected error invalid sketch, got", err)
+	} else if _, ok := err.(*InvalidArgumentError); !ok {
+		t.Errorf("Expected InvalidArgumentError, got %T", err)
+	}
+
+	// Standalone sketches are checked like domain sketches
+	memb := pb.SketchType_MEMB
+	for _, props := range []*pb.SketchProperties{
+		{ErrorRate: utils.Float32p(1.5)},
+		{ErrorRate: utils.Float32p(1)},
+		{ErrorRate: utils.Float32p(float32(math.NaN()))},
+		{ErrorRate: utils.Float32p(-0.1)},
+		{MaxUniqueItems: utils.Int64p(-1)},
+		{Size: utils.Int64p(-1)},
+		// Rejected by the sketch itself, the filter can not be sized
+		{MaxUniqueItems: utils.Int64p(1 << 50), ErrorRate: utils.Float32p(0.001)},
+	} {
+		info := &datamodel.Info{Sketch: &pb.Sketch{Name: utils.Stringp("avengers"), Type: &memb, Properties: props}}
+		if err := m.CreateSketch(info); err == nil {
+			t.Error("Expected error creating a sketch with", props, "got", err)
+		} else if _, ok := err.(*InvalidArgumentError); !ok {
+			t.Errorf("Expected InvalidArgumentError, got %T", err)
+		}
 	}
 	if sketches := m.GetSketches(); len(sketches) != 0 {
 		t.Error("Expected 0 sketches, got", len(sketches))
@@ -159,6 +192,8 @@ func TestDeleteNonExistingSketch(t *testing.T) {
 
 	if err := m.DeleteSketch(info.ID()); err == nil {
 		t.Error("Expected errors deleting non-existing sketch, got", err)
+	} else if _, ok := err.(*NotFoundError); !ok {
+		t.Errorf("Expected NotFoundError, got %T", err)
 	}
 	if sketches := m.GetSketches(); len(sketches) != 0 {
 		t.Error("Expected 0 sketches, got", len(sketches))
@@ -215,18 +250,18 @@ func TestCardSaveLoad(t *testing.T) {
 		t.Error("Expected [[marvel card]], got", sketches)
 	}
 
-	if err := m.AddToSketch(info.ID(), []string{"hulk", "thor", "iron man", "hawk-eye"}); err != nil {
+	if _, err := m.AddToSketch(info.ID(), []string{"hulk", "thor", "iron man", "hawk-eye"}); err != nil {
 		t.Error("Expected no errors, got", err)
 	}
 
-	if err := m.AddToSketch(info.ID(), []string{"hulk", "black widow"}); err != nil {
+	if _, err := m.AddToSketch(info.ID(), []string{"hulk", "black widow"}); err != nil {
 		t.Error("Expected no errors, got", err)
 	}
 
-	if res, err := m.GetFromSketch(info.ID(), nil); err != nil {
+	if res, err := m.GetCardinality(info.ID(), 0); err != nil {
 		t.Error("Expected no errors, got", err)
-	} else if res.(*pb.CardinalityResult).GetCardinality() != 5 {
-		t.Error("Expected res = 5, got", res.(*pb.CardinalityResult).GetCardinality())
+	} else if res.GetCardinality() != 5 {
+		t.Error("Expected res = 5, got", res.GetCardinality())
 	}
 }
 
@@ -252,18 +287,18 @@ func TestFreqSaveLoad(t *testing.T) {
 		t.Error("Expected [[marvel freq]], got", sketches)
 	}
 
-	if err := m.AddToSketch(info.ID(), []string{"hulk", "thor", "iron man", "hawk-eye"}); err != nil {
+	if _, err := m.AddToSketch(info.ID(), []string{"hulk", "thor", "iron man", "hawk-eye"}); err != nil {
 		t.Error("Expected no errors, got", err)
 	}
 
-	if err := m.AddToSketch(info.ID(), []string{"hulk", "black widow"}); err != nil {
+	if _, err := m.AddToSketch(info.ID(), []string{"hulk", "black widow"}); err != nil {
 		t.Error("Expected no errors, got", err)
 	}
 
-	if res, err := m.GetFromSketch(info.ID(), []string{"hulk", "thor", "iron man", "hawk-eye"}); err != nil {
+	if res, err := m.GetFrequency(info.ID(), []string{"hulk", "thor", "iron man", "hawk-eye"}); err != nil {
 		t.Error("Expected no errors, got", err)
-	} else if res.(*pb.FrequencyResult).GetFrequencies()[0].GetCount() != 2 {
-		t.Error("Expected res = 2, got", res.(*pb.FrequencyResult).GetFrequencies()[0].GetCount())
+	} else if res.GetFrequencies()[0].GetCount() != 2 {
+		t.Error("Expected res = 2, got", res.GetFrequencies()[0].GetCount())
 	}
 }
 
@@ -289,20 +324,32 @@ func TestRankSaveLoad(t *testing.T) {
 		t.Error("Expected [[marvel rank]], got", sketches)
 	}
 
-	if err := m.AddToSketch(info.ID(), []string{"hulk", "hulk", "thor", "iron man", "hawk-eye"}); err != nil {
+	if _, err := m.AddToSketch(info.ID(), []string{"hulk", "hulk", "thor", "iron man", "hawk-eye"}); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+
+	if _, err := m.AddToSketch(info.ID(), []string{"hulk", "black widow", "black widow", "black widow", "black widow"}); err != nil {
 		t.Error("Expected no errors, got", err)
 	}
 
-	if err := m.AddToSketch(info.ID(), []string{"hulk", "black widow", "black widow", "black widow", "black widow"}); err != nil {
+	if res, err := m.GetRankings(info.ID(), datamodel.RankingsOptions{}); err != nil {
 		t.Error("Expected no errors, got", err)
+	} else if len(res.GetRankings()) != 5 {
+		t.Error("Expected len(res) = 5, got", len(res.GetRankings()))
+	} else if res.GetRankings()[0].GetValue() != "black widow" {
+		t.Error("Expected 'black widow', got", res.GetRankings()[0].GetValue())
 	}
 
-	if res, err := m.GetFromSketch(info.ID(), nil); err != nil {
+	opts := datamodel.RankingsOptions{Limit: 2, Offset: 1}
+	if res, err := m.GetRankings(info.ID(), opts); err != nil {
 		t.Error("Expected no errors, got", err)
-	} else if len(res.(*pb.RankingsResult).GetRankings()) != 5 {
-		t.Error("Expected len(res) = 5, got", len(res.(*pb.RankingsResult).GetRankings()))
-	} else if res.(*pb.RankingsResult).GetRankings()[0].GetValue() != "black widow" {
-		t.Error("Expected 'black widow', got", res.(*pb.RankingsResult).GetRankings()[0].GetValue())
+	} else if len(res.GetRankings()) != 2 || res.GetRankings()[0].GetValue() != "hulk" {
+		t.Error("Expected hulk to lead the second page, got", res.GetRankings())
+	}
+	if _, err := m.GetRankings(info.ID(), datamodel.RankingsOptions{Offset: -1}); err == nil {
+		t.Error("Expected error on a negative offset, got", err)
+	} else if _, ok := err.(*InvalidArgumentError); !ok {
+		t.Errorf("Expected InvalidArgumentError, got %T", err)
 	}
 }
 
@@ -328,23 +375,23 @@ func TestMembershipSaveLoad(t *testing.T) {
 		t.Error("Expected [[marvel memb]], got", sketches)
 	}
 
-	if err := m.AddToSketch(info.ID(), []string{"hulk", "hulk", "thor", "iron man", "hawk-eye"}); err != nil {
+	if _, err := m.AddToSketch(info.ID(), []string{"hulk", "hulk", "thor", "iron man", "hawk-eye"}); err != nil {
 		t.Error("Expected no errors, got", err)
 	}
 
-	if err := m.AddToSketch(info.ID(), []string{"hulk", "black widow", "black widow", "black widow", "black widow"}); err != nil {
+	if _, err := m.AddToSketch(info.ID(), []string{"hulk", "black widow", "black widow", "black widow", "black widow"}); err != nil {
 		t.Error("Expected no errors, got", err)
 	}
 
-	if res, err := m.GetFromSketch(info.ID(), []string{"hulk", "captian america", "black widow"}); err != nil {
+	if res, err := m.GetMembership(info.ID(), []string{"hulk", "captian america", "black widow"}); err != nil {
 		t.Error("Expected no errors, got", err)
-	} else if len(res.(*pb.MembershipResult).GetMemberships()) != 3 {
-		t.Error("Expected len(res) = 3, got", len(res.(*pb.MembershipResult).GetMemberships()))
-	} else if v := res.(*pb.MembershipResult).GetMemberships()[0].GetIsMember(); !v {
+	} else if len(res.GetMemberships()) != 3 {
+		t.Error("Expected len(res) = 3, got", len(res.GetMemberships()))
+	} else if v := res.GetMemberships()[0].GetIsMember(); !v {
 		t.Error("Expected 'hulk' == true , got", v)
-	} else if v := res.(*pb.MembershipResult).GetMemberships()[1].GetIsMember(); v {
+	} else if v := res.GetMemberships()[1].GetIsMember(); v {
 		t.Error("Expected 'captian america' == false , got", v)
-	} else if v := res.(*pb.MembershipResult).GetMemberships()[2].GetIsMember(); !v {
+	} else if v := res.GetMemberships()[2].GetIsMember(); !v {
 		t.Error("Expected 'captian america' == true , got", v)
 	}
 }
@@ -355,12 +402,9 @@ func TestCreateDeleteDomain(t *testing.T) {
 	defer testutils.TearDownTests()
 
 	m := NewManager()
-	info := datamodel.NewEmptyInfo()
-	info.Properties.MaxUniqueItems = utils.Int64p(10000)
-	info.Properties.Size = utils.Int64p(10000)
-	info.Name = utils.Stringp(fmt.Sprintf("marvel"))
+	dom := &pb.Domain{Name: utils.Stringp("marvel")}
 
-	if err := m.CreateDomain(info); err != nil {
+	if err := m.CreateDomain(dom); err != nil {
 		t.Error("Expected no errors, got", err)
 	}
 	if sketches := m.GetSketches(); len(sketches) != 4 {
@@ -370,10 +414,8 @@ func TestCreateDeleteDomain(t *testing.T) {
 	}
 
 	// Create a second Sketch
-	info2 := datamodel.NewEmptyInfo()
-	info2.Properties.MaxUniqueItems = utils.Int64p(10000)
-	info2.Name = utils.Stringp("dc")
-	if err := m.CreateDomain(info2); err != nil {
+	dom2 := &pb.Domain{Name: utils.Stringp("dc")}
+	if err := m.CreateDomain(dom2); err != nil {
 		t.Error("Expected no errors, got", err)
 	}
 	if sketches := m.GetSketches(); len(sketches) != 8 {
@@ -384,3 +426,1317 @@ func TestCreateDeleteDomain(t *testing.T) {
 		t.Error("Expected [[dc freq]], got", sketches[1][0], sketches[1][1])
 	}
 }
+
+func TestCreateDomainWithSketches(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	m := NewManager()
+	rank := pb.SketchType_RANK
+	memb := pb.SketchType_MEMB
+	dom := &pb.Domain{
+		Name: utils.Stringp("marvel"),
+		Sketches: []*pb.Sketch{
+			{
+				Type:       &rank,
+				Properties: &pb.SketchProperties{Size: utils.Int64p(10)},
+			},
+			{
+				Type:       &memb,
+				Properties: &pb.SketchProperties{MaxUniqueItems: utils.Int64p(1000)},
+			},
+		},
+	}
+
+	if err := m.CreateDomain(dom); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if sketches := m.GetSketches(); len(sketches) != 2 {
+		t.Error("Expected 2 sketches, got", len(sketches))
+	} else if sketches[0][1] != "memb" || sketches[1][1] != "rank" {
+		t.Error("Expected [[marvel memb] [marvel rank]], got", sketches)
+	}
+
+	if res, err := m.GetDomain("marvel"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else {
+		for _, sketch := range res.GetSketches() {
+			switch sketch.GetType() {
+			case pb.SketchType_RANK:
+				if size := sketch.GetProperties().GetSize(); size != 10 {
+					t.Error("Expected size 10, got", size)
+				}
+			case pb.SketchType_MEMB:
+				if max := sketch.GetProperties().GetMaxUniqueItems(); max != 1000 {
+					t.Error("Expected maxUniqueItems 1000, got", max)
+				}
+			}
+		}
+	}
+}
+
+func TestCreateDomainInvalidSketch(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	m := NewManager()
+	card := pb.SketchType_CARD
+	rank := pb.SketchType_RANK
+	dom := &pb.Domain{
+		Name: utils.Stringp("marvel"),
+		Sketches: []*pb.Sketch{
+			{Type: &card},
+			{
+				Type:       &rank,
+				Properties: &pb.SketchProperties{Size: utils.Int64p(-1)},
+			},
+		},
+	}
+
+	if err := m.CreateDomain(dom); err == nil {
+		t.Error("Expected error (invalid size), got", err)
+	}
+	if sketches := m.GetSketches(); len(sketches) != 0 {
+		t.Error("Expected 0 sketches, got", len(sketches))
+	}
+
+	dom.Sketches[1] = &pb.Sketch{Type: &card}
+	if err := m.CreateDomain(dom); err == nil {
+		t.Error("Expected error (duplicate type), got", err)
+	}
+	if domains := m.GetDomains(); len(domains) != 0 {
+		t.Error("Expected 0 domains, got", len(domains))
+	}
+}
+
+func TestAttachDetachSketch(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	m := NewManager()
+	card := pb.SketchType_CARD
+	freq := pb.SketchType_FREQ
+	memb := pb.SketchType_MEMB
+	dom := &pb.Domain{
+		Name:     utils.Stringp("marvel"),
+		Sketches: []*pb.Sketch{{Type: &card}},
+	}
+	if err := m.CreateDomain(dom); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+
+	// Attach a new sketch
+	if err := m.AttachSketch("marvel", &pb.Sketch{Type: &freq}); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if err := m.AttachSketch("marvel", &pb.Sketch{Type: &freq}); err == nil {
+		t.Error("Expected error (duplicate type), got", err)
+	}
+
+	// Adopt an existing standalone sketch
+	info := datamodel.NewEmptyInfo()
+	info.Properties.MaxUniqueItems = utils.Int64p(1000)
+	info.Name = utils.Stringp("avengers")
+	info.Type = &memb
+	if err := m.CreateSketch(info); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if err := m.AttachSketch("marvel", &pb.Sketch{Name: utils.Stringp("avengers"), Type: &memb}); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if err := m.AttachSketch("marvel", &pb.Sketch{Name: utils.Stringp("x-men"), Type: &card}); err == nil {
+		t.Error("Expected error (no such sketch), got", err)
+	}
+
+	if _, err := m.AddToDomain("marvel", []string{"hulk", "thor", "hulk"}); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if res, err := m.GetMembership(info.ID(), []string{"hulk"}); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if !res.GetMemberships()[0].GetIsMember() {
+		t.Error("Expected 'hulk' == true, got false")
+	}
+
+	if res, err := m.GetDomain("marvel"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if len(res.GetSketches()) != 3 {
+		t.Error("Expected 3 sketches, got", len(res.GetSketches()))
+	}
+
+	// Detach the adopted sketch, it should survive as a standalone sketch
+	if err := m.DetachSketch("marvel", &pb.Sketch{Name: utils.Stringp("avengers"), Type: &memb}); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if err := m.DetachSketch("marvel", &pb.Sketch{Name: utils.Stringp("avengers"), Type: &memb}); err == nil {
+		t.Error("Expected error (not attached), got", err)
+	}
+	if res, err := m.GetDomain("marvel"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if len(res.GetSketches()) != 2 {
+		t.Error("Expected 2 sketches, got", len(res.GetSketches()))
+	}
+	if _, err := m.GetSketch(info.ID()); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+}
+
+func TestClearRenameCloneSketch(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	m := NewManager()
+	info := datamodel.NewEmptyInfo()
+	typ := pb.SketchType_CARD
+	info.Name = utils.Stringp("marvel")
+	info.Type = &typ
+	if err := m.CreateSketch(info); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if _, err := m.AddToSketch(info.ID(), []string{"hulk", "thor", "iron man"}); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+
+	if err := m.CloneSketch("marvel.CARD", "marvel-backup"); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if err := m.CloneSketch("marvel.CARD", "marvel-backup"); err == nil {
+		t.Error("Expected error (already exists), got", err)
+	}
+	if err := m.ClearSketch("marvel.CARD"); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+
+	if res, err := m.GetCardinality("marvel.CARD", 0); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if c := res.GetCardinality(); c != 0 {
+		t.Error("Expected res = 0, got", c)
+	}
+	if res, err := m.GetCardinality("marvel-backup.CARD", 0); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if c := res.GetCardinality(); c != 3 {
+		t.Error("Expected res = 3, got", c)
+	}
+
+	if err := m.RenameSketch("marvel-backup.CARD", "marvel"); err == nil {
+		t.Error("Expected error (already exists), got", err)
+	}
+	if err := m.RenameSketch("marvel-backup.CARD", "avengers"); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if _, err := m.GetSketch("marvel-backup.CARD"); err == nil {
+		t.Error("Expected error (no such sketch), got", err)
+	}
+	if res, err := m.GetCardinality("avengers.CARD", 0); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if c := res.GetCardinality(); c != 3 {
+		t.Error("Expected res = 3, got", c)
+	}
+	if sketches := m.GetSketches(); len(sketches) != 2 {
+		t.Error("Expected 2 sketches, got", len(sketches))
+	} else if sketches[0][0] != "avengers" {
+		t.Error("Expected [[avengers card] [marvel card]], got", sketches)
+	}
+}
+
+func TestClearRenameCloneDomain(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	m := NewManager()
+	if err := m.CreateDomain(&pb.Domain{Name: utils.Stringp("today")}); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if _, err := m.AddToDomain("today", []string{"hulk", "thor", "hulk"}); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+
+	if err := m.CloneDomain("today", "yesterday"); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if err := m.ClearDomain("today"); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if res, err := m.GetFrequency("today.FREQ", []string{"hulk"}); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if c := res.GetFrequencies()[0].GetCount(); c != 0 {
+		t.Error("Expected res = 0, got", c)
+	}
+	if res, err := m.GetFrequency("yesterday.FREQ", []string{"hulk"}); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if c := res.GetFrequencies()[0].GetCount(); c != 2 {
+		t.Error("Expected res = 2, got", c)
+	}
+
+	if err := m.RenameDomain("yesterday", "today"); err == nil {
+		t.Error("Expected error (already exists), got", err)
+	}
+	if err := m.RenameDomain("yesterday", "backup"); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if _, err := m.GetDomain("yesterday"); err == nil {
+		t.Error("Expected error (no such domain), got", err)
+	}
+	if dom, err := m.GetDomain("backup"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if len(dom.GetSketches()) != 4 {
+		t.Error("Expected 4 sketches, got", len(dom.GetSketches()))
+	} else if name := dom.GetSketches()[0].GetName(); name != "backup" {
+		t.Error("Expected sketch name backup, got", name)
+	}
+	if _, err := m.AddToDomain("backup", []string{"hulk"}); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if res, err := m.GetFrequency("backup.FREQ", []string{"hulk"}); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if c := res.GetFrequencies()[0].GetCount(); c != 3 {
+		t.Error("Expected res = 3, got", c)
+	}
+}
+
+func TestAddResults(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	m := NewManager()
+	card := pb.SketchType_CARD
+	rank := pb.SketchType_RANK
+	dom := &pb.Domain{
+		Name:     utils.Stringp("marvel"),
+		Sketches: []*pb.Sketch{{Type: &card}, {Type: &rank}},
+	}
+	if err := m.CreateDomain(dom); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+
+	values := []string{"hulk", "thor", "hulk"}
+	if res, err := m.AddToSketch("marvel.CARD", values); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if !res.GetSuccess() || res.GetApplied() != 3 || res.GetStatus() != pb.AddStatus_ADDED {
+		t.Error("Expected 3 values added, got", res)
+	}
+	if _, err := m.AddToSketch("x-men.CARD", values); err == nil {
+		t.Error("Expected error (no such sketch), got", err)
+	}
+
+	// A sketch deleted from under the domain is reported as missing
+	if err := m.DeleteSketch("marvel.RANK"); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	results, err := m.AddToDomain("marvel", values)
+	if err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if len(results) != 2 {
+		t.Fatal("Expected 2 results, got", len(results))
+	}
+	if res := results[0]; !res.GetSuccess() || res.GetApplied() != 3 ||
+		res.GetSketch().GetType() != card {
+		t.Error("Expected 3 values added to marvel.CARD, got", res)
+	}
+	if res := results[1]; res.GetSuccess() || res.GetApplied() != 0 ||
+		res.GetStatus() != pb.AddStatus_MISSING || res.GetSketch().GetName() != "marvel" ||
+		res.GetSketch().GetType() != rank || res.GetError() == "" {
+		t.Error("Expected marvel.RANK to be missing, got", res)
+	}
+	if _, err := m.AddToDomain("x-men", values); err == nil {
+		t.Error("Expected error (no such domain), got", err)
+	}
+}
+
+func TestQueryDomain(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	m := NewManager()
+	dom := &pb.Domain{Name: utils.Stringp("marvel")}
+	if err := m.CreateDomain(dom); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if _, err := m.AddToDomain("marvel", []string{"hulk", "thor", "hulk"}); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+
+	results, err := m.QueryDomain("marvel", []string{"hulk", "loki"}, 0)
+	if err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if len(results) != 4 {
+		t.Fatal("Expected 4 results, got", len(results))
+	}
+	for _, res := range results {
+		switch res.GetSketch().GetType() {
+		case pb.SketchType_CARD:
+			if c := res.GetCardinality().GetCardinality(); c != 2 {
+				t.Error("Expected cardinality 2, got", c)
+			}
+		case pb.SketchType_RANK:
+			if r := res.GetRankings().GetRankings(); len(r) != 2 || r[0].GetValue() != "hulk" {
+				t.Error("Expected [hulk thor], got", r)
+			}
+		case pb.SketchType_FREQ:
+			if f := res.GetFrequency().GetFrequencies(); len(f) != 2 ||
+				f[0].GetCount() != 2 || f[1].GetCount() != 0 {
+				t.Error("Expected hulk == 2 and loki == 0, got", f)
+			}
+		case pb.SketchType_MEMB:
+			if mb := res.GetMembership().GetMemberships(); len(mb) != 2 ||
+				!mb[0].GetIsMember() || mb[1].GetIsMember() {
+				t.Error("Expected hulk == true and loki == false, got", mb)
+			}
+		}
+	}
+
+	if _, err := m.QueryDomain("x-men", nil, 0); err == nil {
+		t.Error("Expected error (no such domain), got", err)
+	}
+}
+
+func TestDomainLocks(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	m := NewManager()
+	for _, name := range []string{"marvel", "dc"} {
+		if err := m.CreateDomain(&pb.Domain{Name: utils.Stringp(name)}); err != nil {
+			t.Fatal("Expected no errors, got", err)
+		}
+	}
+
+	// A query holding marvel does not hold up adds to dc
+	unlock := m.domains.rlock([]string{"marvel", "marvel", "x-men"})
+	done := make(chan error, 2)
+	go func() {
+		_, err := m.AddToDomain("dc", []string{"batman"})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error("Expected no errors, got", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the add to dc not to wait for marvel")
+	}
+	go func() {
+		_, err := m.AddToDomain("marvel", []string{"hulk"})
+		done <- err
+	}()
+	select {
+	case <-done:
+		t.Error("Expected the add to marvel to wait for the query")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	if err := <-done; err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+
+	// Clearing a domain waits for its queries too
+	unlock = m.domains.rlock([]string{"marvel"})
+	go func() { done <- m.ClearDomain("marvel") }()
+	select {
+	case <-done:
+		t.Error("Expected the clear of marvel to wait for the query")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	if err := <-done; err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+
+	// Creating and deleting sketches and domains races neither with adds nor
+	// with queries (run with -race)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("x-men%d", i)
+			for j := 0; j < 20; j++ {
+				if err := m.CreateDomain(&pb.Domain{Name: utils.Stringp(name)}); err != nil {
+					t.Error("Expected no errors, got", err)
+				}
+				if err := m.DeleteDomain(name); err != nil {
+					t.Error("Expected no errors, got", err)
+				}
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if _, err := m.AddToDomain("dc", []string{"batman"}); err != nil {
+					t.Error("Expected no errors, got", err)
+				}
+				if _, err := m.QueryDomain("dc", nil, 0); err != nil {
+					t.Error("Expected no errors, got", err)
+				}
+				m.GetDomains()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRegisteredSketchType(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	// A type outside skizze.proto, counting distinct values with a HLL++
+	typ := pb.SketchType(100)
+	datamodel.Register(&datamodel.SketchType{
+		Type: typ,
+		Name: "uniq",
+		New: func(info *datamodel.Info) (datamodel.Sketcher, error) {
+			return sketches.NewHLLPPSketch(info)
+		},
+		Capabilities: datamodel.CardinalityQuery,
+	})
+	defer datamodel.Unregister(typ)
+	if name := datamodel.TypeName(typ); name != "UNIQ" {
+		t.Error("Expected UNIQ, got", name)
+	}
+	if _, ok := pb.SketchType_name[int32(typ)]; ok {
+		t.Error("Expected the generated enum names to be left alone, got", pb.SketchType_name[int32(typ)])
+	}
+	if parsed, ok := datamodel.ParseTypeName("UNIQ"); !ok || parsed != typ {
+		t.Error("Expected UNIQ to parse as", typ, "got", parsed, ok)
+	}
+
+	m := NewManager()
+	dom := &pb.Domain{
+		Name:     utils.Stringp("marvel"),
+		Sketches: []*pb.Sketch{{Name: utils.Stringp("marvel"), Type: &typ}},
+	}
+	if err := m.CreateDomain(dom); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if _, err := m.AddToDomain("marvel", []string{"hulk", "thor", "hulk"}); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if res, err := m.GetCardinality("marvel.UNIQ", 0); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if res.GetCardinality() != 2 {
+		t.Error("Expected res = 2, got", res.GetCardinality())
+	}
+	if res, err := m.QueryDomain("marvel", nil, 0); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if len(res) != 1 || res[0].GetCardinality().GetCardinality() != 2 {
+		t.Error("Expected 1 result with cardinality 2, got", res)
+	}
+	if sketches := m.GetSketches(); len(sketches) != 1 || sketches[0][1] != "uniq" {
+		t.Error("Expected [marvel uniq], got", sketches)
+	}
+}
+
+func TestAddIfAbsent(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	m := NewManager()
+	info := datamodel.NewEmptyInfo()
+	typ := pb.SketchType_MEMB
+	info.Properties.MaxUniqueItems = utils.Int64p(1000)
+	info.Name = utils.Stringp("marvel")
+	info.Type = &typ
+	if err := m.CreateSketch(info); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+
+	if res, err := m.AddIfAbsent(info.ID(), []string{"hulk", "thor", "hulk"}); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if res[0].GetIsMember() || res[1].GetIsMember() || !res[2].GetIsMember() {
+		t.Error("Expected only the second hulk to be present, got", res)
+	}
+	if res, err := m.AddIfAbsent(info.ID(), []string{"thor", "loki"}); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if !res[0].GetIsMember() || res[1].GetIsMember() {
+		t.Error("Expected thor to be present and loki not, got", res)
+	}
+	if res, err := m.GetMembership(info.ID(), []string{"loki"}); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if !res.GetMemberships()[0].GetIsMember() {
+		t.Error("Expected loki to be a member, got", res)
+	}
+
+	// Concurrent adds of the same value find it absent exactly once
+	var wg sync.WaitGroup
+	absent := make(chan bool, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := m.AddIfAbsent(info.ID(), []string{"hawk-eye"})
+			if err == nil && !res[0].GetIsMember() {
+				absent <- true
+			}
+		}()
+	}
+	wg.Wait()
+	if len(absent) != 1 {
+		t.Error("Expected hawk-eye to be absent once, got", len(absent))
+	}
+
+	typ = pb.SketchType_FREQ
+	info = datamodel.NewEmptyInfo()
+	info.Properties.MaxUniqueItems = utils.Int64p(1000)
+	info.Name = utils.Stringp("marvel")
+	info.Type = &typ
+	if err := m.CreateSketch(info); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if _, err := m.AddIfAbsent(info.ID(), []string{"hulk"}); err == nil {
+		t.Error("Expected error on a FREQ sketch, got", err)
+	} else if _, ok := err.(*InvalidArgumentError); !ok {
+		t.Errorf("Expected InvalidArgumentError, got %T", err)
+	}
+	if _, err := m.AddIfAbsent("x-men.MEMB", []string{"hulk"}); err == nil {
+		t.Error("Expected error on a non-existing sketch, got", err)
+	}
+}
+
+func TestCardinalityConfidence(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	m := NewManager()
+	if err := m.CreateDomain(&pb.Domain{Name: utils.Stringp("marvel")}); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if _, err := m.AddToDomain("marvel", []string{"hulk", "thor"}); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+
+	if res, err := m.GetCardinality("marvel.CARD", 0); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if res.GetConfidence() != float32(datamodel.DefaultConfidence) {
+		t.Error("Expected default confidence, got", res.GetConfidence())
+	} else if res.GetLower() > 2 || res.GetUpper() < 2 {
+		t.Errorf("Expected 2 in [%d, %d]", res.GetLower(), res.GetUpper())
+	}
+	if res, err := m.QueryDomain("marvel", nil, 0.5); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else {
+		for _, r := range res {
+			if r.GetCardinality() != nil && r.GetCardinality().GetConfidence() != 0.5 {
+				t.Error("Expected confidence 0.5, got", r.GetCardinality().GetConfidence())
+			}
+		}
+	}
+
+	for _, c := range []float64{-0.5, 1, 2} {
+		if _, err := m.GetCardinality("marvel.CARD", c); err == nil {
+			t.Error("Expected error for confidence", c)
+		} else if _, ok := err.(*InvalidArgumentError); !ok {
+			t.Errorf("Expected InvalidArgumentError, got %T", err)
+		}
+	}
+}
+
+func TestDecayReplay(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	typ := pb.SketchType_FREQ
+	sketch := &pb.Sketch{
+		Name:       utils.Stringp("marvel"),
+		Type:       &typ,
+		Properties: &pb.SketchProperties{MaxUniqueItems: utils.Int64p(1000), HalfLife: utils.Int64p(3600)},
+	}
+	now := time.Now()
+	ops := []struct {
+		op  uint8
+		msg proto.Message
+	}{
+		{storage.CreateSketch, sketch},
+		{storage.Add, &pb.AddRequest{
+			Sketch:    sketch,
+			Values:    []string{"hulk", "hulk", "hulk", "hulk", "hulk", "hulk", "hulk", "hulk"},
+			Timestamp: utils.Int64p(now.Add(-2 * time.Hour).UnixNano()),
+		}},
+		{storage.Add, &pb.AddRequest{
+			Sketch:    sketch,
+			Values:    []string{"hulk", "hulk"},
+			Timestamp: utils.Int64p(now.UnixNano()),
+		}},
+	}
+
+	// Replaying the same operations later gives the same counts
+	for i := 0; i < 2; i++ {
+		m := NewManager()
+		for _, op := range ops {
+			raw, err := proto.Marshal(op.msg)
+			if err != nil {
+				t.Fatal("Expected no errors, got", err)
+			}
+			if err := m.Apply(op.op, raw); err != nil {
+				t.Error("Expected no errors, got", err)
+			}
+		}
+		if res, err := m.GetFrequency("marvel.FREQ", []string{"hulk"}); err != nil {
+			t.Error("Expected no errors, got", err)
+		} else if c := res.GetFrequencies()[0].GetCount(); c != 4 {
+			t.Error("Expected hulk == 8 / 4 + 2 == 4, got", c)
+		}
+	}
+
+	m := NewManager()
+	card := pb.SketchType_CARD
+	info := &datamodel.Info{Sketch: &pb.Sketch{
+		Name:       utils.Stringp("marvel"),
+		Type:       &card,
+		Properties: &pb.SketchProperties{HalfLife: utils.Int64p(3600)},
+	}}
+	if err := m.CreateSketch(info); err == nil {
+		t.Error("Expected error creating a decaying CARD sketch, got", err)
+	} else if _, ok := err.(*InvalidArgumentError); !ok {
+		t.Errorf("Expected InvalidArgumentError, got %T", err)
+	}
+}
+
+func TestCloneRenameRange(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	m := NewManager()
+	typ := pb.SketchType_RANGE
+	dom := &pb.Domain{
+		Name: utils.Stringp("latency"),
+		Sketches: []*pb.Sketch{{
+			Type:       &typ,
+			Properties: &pb.SketchProperties{MinValue: utils.Int64p(100), MaxValue: utils.Int64p(1000)},
+		}},
+	}
+	if err := m.CreateDomain(dom); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if _, err := m.AddToDomain("latency", []string{"150", "250", "900"}); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if err := m.CloneSketch("latency.RANGE", "backup"); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if err := m.RenameSketch("backup.RANGE", "archive"); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if err := m.CloneDomain("latency", "copy"); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if err := m.RenameDomain("copy", "renamed"); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+
+	// Every copy keeps its range, it counts and takes values like the original
+	for _, id := range []string{"latency.RANGE", "archive.RANGE", "renamed.RANGE"} {
+		info, err := m.GetSketch(id)
+		if err != nil {
+			t.Fatal("Expected no errors, got", err)
+		}
+		if props := info.Properties; props.GetMinValue() != 100 || props.GetMaxValue() != 1000 {
+			t.Errorf("Expected %s to keep the range [100, 1000], got %v", id, props)
+		}
+		if res, err := m.AddToSketch(id, []string{"500"}); err != nil || !res.GetSuccess() {
+			t.Errorf("Expected 500 to be added to %s, got %v %v", id, res, err)
+		}
+		if res, err := m.GetRangeCount(id, 200, 1000); err != nil {
+			t.Error("Expected no errors, got", err)
+		} else if res.GetCount() != 3 {
+			t.Errorf("Expected 3 values of %s in [200, 1000], got %d", id, res.GetCount())
+		}
+	}
+}
+
+func TestCloneDecaying(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	m := NewManager()
+	typ := pb.SketchType_FREQ
+	info := &datamodel.Info{Sketch: &pb.Sketch{
+		Name:       utils.Stringp("marvel"),
+		Type:       &typ,
+		Properties: &pb.SketchProperties{MaxUniqueItems: utils.Int64p(1000), HalfLife: utils.Int64p(3600)},
+	}}
+	if err := m.CreateSketch(info); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if err := m.CloneSketch(info.ID(), "avengers"); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	clone, err := m.GetSketch("avengers.FREQ")
+	if err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if h := clone.Properties.GetHalfLife(); h != 3600 {
+		t.Error("Expected the clone to keep halfLife 3600, got", h)
+	}
+
+	// A cleared clone is rebuilt as a decaying sketch
+	if err := m.ClearSketch(clone.ID()); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	now := time.Now()
+	if _, err := m.AddToSketchAt(clone.ID(), []string{"hulk", "hulk", "hulk", "hulk"}, now.Add(-2*time.Hour)); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if _, err := m.AddToSketchAt(clone.ID(), []string{"hulk"}, now); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if res, err := m.GetFrequency(clone.ID(), []string{"hulk"}); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if c := res.GetFrequencies()[0].GetCount(); c != 2 {
+		t.Error("Expected hulk == 4 / 4 + 1 == 2, got", c)
+	}
+}
+
+func TestRangeCount(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	m := NewManager()
+	typ := pb.SketchType_RANGE
+	info := datamodel.NewEmptyInfo()
+	info.Name = utils.Stringp("latency")
+	info.Type = &typ
+	info.Properties.MaxValue = utils.Int64p(1000)
+	if err := m.CreateSketch(info); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+
+	res, err := m.AddToSketch(info.ID(), []string{"10", "20", "30", "fast"})
+	if err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if res.GetStatus() != pb.AddStatus_ERROR || res.GetApplied() != 3 {
+		t.Error("Expected 3 values applied and an error for fast, got", res)
+	}
+	if res, err := m.GetRangeCount(info.ID(), 15, 30); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if res.GetCount() != 2 {
+		t.Error("Expected 2 values in [15, 30], got", res.GetCount())
+	}
+	if _, err := m.GetRangeCount(info.ID(), 30, 15); err == nil {
+		t.Error("Expected error on an empty range, got", err)
+	} else if _, ok := err.(*InvalidArgumentError); !ok {
+		t.Errorf("Expected InvalidArgumentError, got %T", err)
+	}
+	if _, err := m.GetRangeCount("latency.CARD", 0, 1); err == nil {
+		t.Error("Expected error on a non-existing sketch, got", err)
+	}
+
+	info = datamodel.NewEmptyInfo()
+	info.Name = utils.Stringp("inverted")
+	info.Type = &typ
+	info.Properties.MinValue = utils.Int64p(10)
+	if err := m.CreateSketch(info); err == nil {
+		t.Error("Expected error creating an empty range, got", err)
+	} else if _, ok := err.(*InvalidArgumentError); !ok {
+		t.Errorf("Expected InvalidArgumentError, got %T", err)
+	}
+
+	info.Name = utils.Stringp("unbounded")
+	info.Properties.MinValue = utils.Int64p(math.MinInt64)
+	info.Properties.MaxValue = utils.Int64p(math.MaxInt64)
+	if err := m.CreateSketch(info); err == nil {
+		t.Error("Expected error creating a range of 2^64 values, got", err)
+	} else if _, ok := err.(*InvalidArgumentError); !ok {
+		t.Errorf("Expected InvalidArgumentError, got %T", err)
+	}
+
+	// RANGE sketches are only part of domains that ask for them
+	if err := m.CreateDomain(&pb.Domain{Name: utils.Stringp("marvel")}); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if dom, err := m.GetDomain("marvel"); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else {
+		for _, sketch := range dom.GetSketches() {
+			if sketch.GetType() == typ {
+				t.Error("Expected no RANGE sketch, got", sketch)
+			}
+		}
+	}
+	dom := &pb.Domain{
+		Name:     utils.Stringp("requests"),
+		Sketches: []*pb.Sketch{{Type: &typ}},
+	}
+	if err := m.CreateDomain(dom); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if _, err := m.AddToDomain("requests", []string{"4000000000"}); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if res, err := m.GetRangeCount("requests.RANGE", 0, datamodel.DefaultMaxValue); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if res.GetCount() != 1 {
+		t.Error("Expected 1 value within the default range, got", res.GetCount())
+	}
+}
+
+func visitors(from, to int) []string {
+	var values []string
+	for i := from; i < to; i++ {
+		values = append(values, fmt.Sprintf("user%d", i))
+	}
+	return values
+}
+
+func TestCardinalityExpression(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	m := NewManager()
+	if err := m.CreateDomain(&pb.Domain{Name: utils.Stringp("home")}); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if _, err := m.AddToDomain("home", visitors(0, 1000)); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	card := pb.SketchType_CARD
+	for _, page := range []struct {
+		name     string
+		from, to int
+	}{{"pricing", 500, 1500}, {"careers", 995, 1995}} {
+		info := datamodel.NewEmptyInfo()
+		info.Name = utils.Stringp(page.name)
+		info.Type = &card
+		if err := m.CreateSketch(info); err != nil {
+			t.Error("Expected no errors, got", err)
+		}
+		if _, err := m.AddToSketch(info.ID(), visitors(page.from, page.to)); err != nil {
+			t.Error("Expected no errors, got", err)
+		}
+	}
+
+	home := &pb.CardinalityExpression{Domain: utils.Stringp("home")}
+	pricing := &pb.CardinalityExpression{Sketch: &pb.Sketch{Name: utils.Stringp("pricing"), Type: &card}}
+	careers := &pb.CardinalityExpression{Sketch: &pb.Sketch{Name: utils.Stringp("careers"), Type: &card}}
+	op := func(op pb.SetOperation, operands ...*pb.CardinalityExpression) *pb.CardinalityExpression {
+		return &pb.CardinalityExpression{Op: &op, Operands: operands}
+	}
+
+	tests := []struct {
+		expr     *pb.CardinalityExpression
+		expected int64
+		warning  bool
+	}{
+		{op(pb.SetOperation_UNION, home, pricing), 1500, false},
+		{op(pb.SetOperation_INTERSECTION, home, pricing), 500, false},
+		{op(pb.SetOperation_DIFFERENCE, home, pricing), 500, false},
+		{op(pb.SetOperation_INTERSECTION, op(pb.SetOperation_UNION, home, careers), pricing), 1000, false},
+		{op(pb.SetOperation_INTERSECTION, home, careers), 5, true},
+	}
+	for i, test := range tests {
+		reply, err := m.GetCardinalityExpression(test.expr, card, 0)
+		if err != nil {
+			t.Error("Expected no errors, got", err)
+			continue
+		}
+		res := reply.GetResult()
+		if res.GetLower() > test.expected || res.GetUpper() < test.expected {
+			t.Errorf("Expected %d within [%d, %d] for expression %d", test.expected, res.GetLower(), res.GetUpper(), i)
+		}
+		if (reply.Warning != nil) != test.warning {
+			t.Errorf("Expected warning %v for expression %d, got %q", test.warning, i, reply.GetWarning())
+		}
+	}
+
+	memb := pb.SketchType_MEMB
+	invalid := []*pb.CardinalityExpression{
+		{},
+		op(pb.SetOperation_UNION),
+		{Sketch: &pb.Sketch{Name: utils.Stringp("home"), Type: &memb}},
+		{Domain: utils.Stringp("home"), Operands: []*pb.CardinalityExpression{pricing}},
+	}
+	for _, expr := range invalid {
+		if _, err := m.GetCardinalityExpression(expr, card, 0); err == nil {
+			t.Error("Expected error on an invalid expression, got", err)
+		} else if _, ok := err.(*InvalidArgumentError); !ok {
+			t.Errorf("Expected InvalidArgumentError, got %T", err)
+		}
+	}
+	if _, err := m.GetCardinalityExpression(&pb.CardinalityExpression{Domain: utils.Stringp("blog")}, card, 0); err == nil {
+		t.Error("Expected error on a non-existing domain, got", err)
+	}
+}
+
+func TestThetaExpression(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	m := NewManager()
+	theta := pb.SketchType_THETA
+	card := pb.SketchType_CARD
+	dom := &pb.Domain{
+		Name:     utils.Stringp("home"),
+		Sketches: []*pb.Sketch{{Type: &card}, {Type: &theta}},
+	}
+	if err := m.CreateDomain(dom); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if _, err := m.AddToDomain("home", visitors(0, 100000)); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	info := datamodel.NewEmptyInfo()
+	info.Name = utils.Stringp("careers")
+	info.Type = &theta
+	if err := m.CreateSketch(info); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if _, err := m.AddToSketch(info.ID(), visitors(99000, 101000)); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+
+	intersection := pb.SetOperation_INTERSECTION
+	expr := &pb.CardinalityExpression{
+		Op: &intersection,
+		Operands: []*pb.CardinalityExpression{
+			{Domain: utils.Stringp("home")},
+			{Sketch: info.Sketch},
+		},
+	}
+	reply, err := m.GetCardinalityExpression(expr, theta, 0)
+	if err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if res := reply.GetResult(); res.GetLower() > 1000 || res.GetUpper() < 1000 {
+		t.Error("Expected 1000 within the bounds, got", res)
+	}
+
+	// The THETA sketch can not be part of a CARD expression
+	if _, err := m.GetCardinalityExpression(expr, card, 0); err == nil {
+		t.Error("Expected error on a THETA sketch in a CARD expression, got", err)
+	} else if _, ok := err.(*InvalidArgumentError); !ok {
+		t.Errorf("Expected InvalidArgumentError, got %T", err)
+	}
+	if _, err := m.GetCardinalityExpression(expr, pb.SketchType_RANK, 0); err == nil {
+		t.Error("Expected error on a RANK expression, got", err)
+	}
+}
+
+func TestFindSimilar(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	m := NewManager()
+	typ := pb.SketchType_MINHASH
+	create := func(name string, from, to int) *datamodel.Info {
+		info := datamodel.NewEmptyInfo()
+		info.Name = utils.Stringp(name)
+		info.Type = &typ
+		if err := m.CreateSketch(info); err != nil {
+			t.Error("Expected no errors, got", err)
+		}
+		if _, err := m.AddToSketch(info.ID(), visitors(from, to)); err != nil {
+			t.Error("Expected no errors, got", err)
+		}
+		return info
+	}
+	home := create("home", 0, 1000)
+	create("pricing", 100, 1000)
+	create("blog", 500, 1500)
+	careers := create("careers", 5000, 6000)
+
+	results, err := m.FindSimilar(home.ID(), nil, datamodel.SimilarityOptions{})
+	if err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if len(results) != 3 || results[0].GetSketch().GetName() != "pricing" ||
+		results[1].GetSketch().GetName() != "blog" || results[2].GetSimilarity() > 0.05 {
+		t.Error("Expected pricing, blog and careers in that order, got", results)
+	}
+
+	results, err = m.FindSimilar(home.ID(), nil, datamodel.SimilarityOptions{Limit: 1, LSH: true})
+	if err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if len(results) != 1 || results[0].GetSketch().GetName() != "pricing" {
+		t.Error("Expected only pricing, got", results)
+	}
+
+	// The band index follows renames, clears, adds and deletes
+	names := func() []string {
+		results, err := m.FindSimilar(home.ID(), nil, datamodel.SimilarityOptions{LSH: true})
+		if err != nil {
+			t.Fatal("Expected no errors, got", err)
+		}
+		var names []string
+		for _, res := range results {
+			names = append(names, res.GetSketch().GetName())
+		}
+		return names
+	}
+	if err := m.RenameSketch("pricing.MINHASH", "plans"); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if found := names(); !reflect.DeepEqual(found, []string{"plans"}) {
+		t.Error("Expected only plans, got", found)
+	}
+	if err := m.ClearSketch("plans.MINHASH"); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if found := names(); len(found) != 0 {
+		t.Error("Expected no sketches sharing a band, got", found)
+	}
+	if _, err := m.AddToSketch("plans.MINHASH", visitors(100, 1000)); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if found := names(); !reflect.DeepEqual(found, []string{"plans"}) {
+		t.Error("Expected only plans, got", found)
+	}
+	if err := m.DeleteSketch("plans.MINHASH"); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if found := names(); len(found) != 0 {
+		t.Error("Expected no sketches sharing a band, got", found)
+	}
+
+	results, err = m.FindSimilar(home.ID(), []string{careers.ID()}, datamodel.SimilarityOptions{MinSimilarity: 0.5})
+	if err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if len(results) != 0 {
+		t.Error("Expected no similar sketches, got", results)
+	}
+
+	if _, err := m.FindSimilar("nope.MINHASH", nil, datamodel.SimilarityOptions{}); err == nil {
+		t.Error("Expected error on a missing sketch, got", err)
+	} else if _, ok := err.(*NotFoundError); !ok {
+		t.Errorf("Expected NotFoundError, got %T", err)
+	}
+
+	card := pb.SketchType_CARD
+	info := datamodel.NewEmptyInfo()
+	info.Name = utils.Stringp("home")
+	info.Type = &card
+	if err := m.CreateSketch(info); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if _, err := m.FindSimilar(info.ID(), nil, datamodel.SimilarityOptions{}); err == nil {
+		t.Error("Expected error on a CARD sketch, got", err)
+	} else if _, ok := err.(*InvalidArgumentError); !ok {
+		t.Errorf("Expected InvalidArgumentError, got %T", err)
+	}
+	if _, err := m.FindSimilar(home.ID(), []string{info.ID()}, datamodel.SimilarityOptions{}); err == nil {
+		t.Error("Expected error comparing with a CARD sketch, got", err)
+	} else if _, ok := err.(*InvalidArgumentError); !ok {
+		t.Errorf("Expected InvalidArgumentError, got %T", err)
+	}
+}
+
+func TestGetSample(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	m := NewManager()
+	samp := pb.SketchType_SAMP
+	info := datamodel.NewEmptyInfo()
+	info.Name = utils.Stringp("events")
+	info.Type = &samp
+	info.Properties.Size = utils.Int64p(5)
+	info.Properties.Weighted = utils.Boolp(true)
+	if err := m.CreateSketch(info); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	res, err := m.AddToSketch(info.ID(), []string{"login:1", "signup:2", "logout"})
+	if err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if res.GetApplied() != 2 || res.GetSuccess() {
+		t.Error("Expected 2 values applied and one rejected, got", res)
+	}
+	sample, err := m.GetSample(info.ID())
+	if err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if len(sample.GetValues()) != 2 || sample.GetCount() != 2 {
+		t.Error("Expected login and signup in the sample, got", sample)
+	}
+
+	card := pb.SketchType_CARD
+	info = datamodel.NewEmptyInfo()
+	info.Name = utils.Stringp("events")
+	info.Type = &card
+	info.Properties.Weighted = utils.Boolp(true)
+	if err := m.CreateSketch(info); err == nil {
+		t.Error("Expected error on a weighted CARD sketch, got", err)
+	} else if _, ok := err.(*InvalidArgumentError); !ok {
+		t.Errorf("Expected InvalidArgumentError, got %T", err)
+	}
+
+	if err := m.CreateDomain(&pb.Domain{Name: utils.Stringp("marvel")}); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if _, err := m.GetSample("marvel.CARD"); err == nil {
+		t.Error("Expected error on a CARD sketch, got", err)
+	} else if _, ok := err.(*InvalidArgumentError); !ok {
+		t.Errorf("Expected InvalidArgumentError, got %T", err)
+	}
+	if err := m.AttachSketch("marvel", &pb.Sketch{Type: &samp}); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if _, err := m.AddToDomain("marvel", []string{"hulk", "thor"}); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	results, err := m.QueryDomain("marvel", nil, 0)
+	if err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	for _, r := range results {
+		if r.GetSketch().GetType() == samp && len(r.GetSample().GetValues()) != 2 {
+			t.Error("Expected hulk and thor in the sample, got", r.GetSample())
+		}
+	}
+}
+
+func TestCloneWeightedSample(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	m := NewManager()
+	samp := pb.SketchType_SAMP
+	info := datamodel.NewEmptyInfo()
+	info.Name = utils.Stringp("events")
+	info.Type = &samp
+	info.Properties.Size = utils.Int64p(5)
+	info.Properties.Weighted = utils.Boolp(true)
+	if err := m.CreateSketch(info); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if err := m.CloneSketch(info.ID(), "backup"); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	clone, err := m.GetSketch("backup.SAMP")
+	if err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if !clone.Properties.GetWeighted() {
+		t.Error("Expected the clone to stay weighted, got", clone.Properties)
+	}
+
+	// A cleared clone is rebuilt as a weighted sketch
+	if err := m.ClearSketch(clone.ID()); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	res, err := m.AddToSketch(clone.ID(), []string{"login:3", "logout"})
+	if err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if res.GetApplied() != 1 || res.GetSuccess() {
+		t.Error("Expected login applied and logout rejected, got", res)
+	}
+	sample, err := m.GetSample(clone.ID())
+	if err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if vals := sample.GetValues(); len(vals) != 1 || vals[0].GetValue() != "login" || vals[0].GetWeight() != 3 {
+		t.Error("Expected login with weight 3 in the sample, got", sample)
+	}
+}
+
+func TestGroups(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	typ := pb.SketchType_KCARD
+	sketch := &pb.Sketch{Name: utils.Stringp("pages"), Type: &typ}
+	ops := []struct {
+		op  uint8
+		msg proto.Message
+	}{
+		{storage.CreateSketch, sketch},
+		{storage.Add, &pb.AddRequest{
+			Sketch: sketch,
+			Keys:   []string{"/home", "/home", "/home", "/pricing", "/pricing", "/blog"},
+			Values: []string{"neil", "seif", "martin", "neil", "conor", "seif"},
+		}},
+		{storage.Add, &pb.AddRequest{
+			Sketch: sketch,
+			Keys:   []string{"/blog", "/home"},
+			Values: []string{"neil", "neil"},
+		}},
+	}
+	// The groups are rebuilt from the AOF, keys may hold any byte
+	path := filepath.Join(config.DataDir, "skizze.aof")
+	aof := storage.NewAOF(path)
+	aof.Run()
+	for _, op := range ops {
+		if err := aof.Append(op.op, op.msg); err != nil {
+			t.Error("Expected no errors, got", err)
+		}
+	}
+	if err := aof.Close(); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	aof = storage.NewAOF(path)
+	defer aof.Close()
+	m := NewManager()
+	if err := m.Replay(aof); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+
+	id := "pages.KCARD"
+	results, count, err := m.GetGroups(id, nil, datamodel.GroupOptions{Limit: 2})
+	if err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if count != 3 || len(results) != 2 || results[0].GetKey() != "/home" ||
+		results[0].GetCardinality().GetCardinality() != 3 || results[1].GetKey() != "/blog" {
+		t.Error("Expected /home and /blog out of 3 groups, got", count, results)
+	}
+	results, _, err = m.GetGroups(id, []string{"/pricing", "/careers"}, datamodel.GroupOptions{})
+	if err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	if len(results) != 2 || results[0].GetCardinality().GetCardinality() != 2 ||
+		results[1].GetCardinality().GetCardinality() != 0 {
+		t.Error("Expected 2 visitors of /pricing and none of /careers, got", results)
+	}
+
+	if _, err := m.AddToGroups(id, []string{"/home"}, []string{"neil", "seif"}); err == nil {
+		t.Error("Expected error on a missing group key, got", err)
+	} else if _, ok := err.(*InvalidArgumentError); !ok {
+		t.Errorf("Expected InvalidArgumentError, got %T", err)
+	}
+	if res, err := m.AddToSketch(id, []string{"neil"}); err != nil {
+		t.Error("Expected no errors, got", err)
+	} else if res.GetSuccess() || res.GetStatus() != pb.AddStatus_ERROR {
+		t.Error("Expected an add without group key to fail, got", res)
+	}
+
+	card := pb.SketchType_CARD
+	info := datamodel.NewEmptyInfo()
+	info.Name = utils.Stringp("pages")
+	info.Type = &card
+	if err := m.CreateSketch(info); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if _, err := m.AddToGroups(info.ID(), []string{"/home"}, []string{"neil"}); err == nil {
+		t.Error("Expected error adding keyed values to a CARD sketch, got", err)
+	} else if _, ok := err.(*InvalidArgumentError); !ok {
+		t.Errorf("Expected InvalidArgumentError, got %T", err)
+	}
+	if _, _, err := m.GetGroups(info.ID(), nil, datamodel.GroupOptions{}); err == nil {
+		t.Error("Expected error on the groups of a CARD sketch, got", err)
+	} else if _, ok := err.(*InvalidArgumentError); !ok {
+		t.Errorf("Expected InvalidArgumentError, got %T", err)
+	}
+}
@@ -0,0 +1,145 @@
+package manager
+
+import (
+	"io"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+
+	"datamodel"
+	pb "datamodel/protobuf"
+	"storage"
+)
+
+// Replay applies every operation recorded in the AOF. Operations that fail
+// are logged and skipped, an error is only returned if the AOF is corrupt.
+func (m *Manager) Replay(aof *storage.AOF) error {
+	logger.Infof("Replaying ...")
+	for {
+		e, err := aof.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := m.Apply(e.OpType(), e.RawMsg()); err != nil {
+			logger.Errorf("an error has occurred while replaying: %s", err.Error())
+		}
+	}
+}
+
+// Apply applies a single operation, raw being the marshalled request as
+// it is recorded in the AOF
+func (m *Manager) Apply(op uint8, raw []byte) error {
+	switch op {
+	case storage.Add:
+		req := &pb.AddRequest{}
+		if err := proto.Unmarshal(raw, req); err != nil {
+			return err
+		}
+		// Adds recorded before timestamps were kept are applied as if new
+		at := time.Now()
+		if req.Timestamp != nil {
+			at = time.Unix(0, req.GetTimestamp())
+		}
+		if len(req.GetKeys()) > 0 {
+			info := &datamodel.Info{Sketch: req.GetSketch()}
+			_, err := m.AddToGroups(info.ID(), req.GetKeys(), req.GetValues())
+			return err
+		} else if dom := req.GetDomain(); dom != nil {
+			_, err := m.AddToDomainAt(dom.GetName(), req.GetValues(), at)
+			return err
+		} else if sketch := req.GetSketch(); sketch != nil {
+			info := &datamodel.Info{Sketch: sketch}
+			_, err := m.AddToSketchAt(info.ID(), req.GetValues(), at)
+			return err
+		}
+		return nil
+	case storage.AddIfAbsent:
+		req := &pb.AddIfAbsentRequest{}
+		if err := proto.Unmarshal(raw, req); err != nil {
+			return err
+		}
+		info := &datamodel.Info{Sketch: req.GetSketch()}
+		_, err := m.AddIfAbsent(info.ID(), req.GetValues())
+		return err
+	case storage.CreateSketch:
+		sketch := &pb.Sketch{}
+		if err := proto.Unmarshal(raw, sketch); err != nil {
+			return err
+		}
+		return m.CreateSketch(&datamodel.Info{Sketch: sketch})
+	case storage.DeleteSketch:
+		sketch := &pb.Sketch{}
+		if err := proto.Unmarshal(raw, sketch); err != nil {
+			return err
+		}
+		info := &datamodel.Info{Sketch: sketch}
+		return m.DeleteSketch(info.ID())
+	case storage.CreateDom:
+		dom := &pb.Domain{}
+		if err := proto.Unmarshal(raw, dom); err != nil {
+			return err
+		}
+		return m.CreateDomain(dom)
+	case storage.DeleteDom:
+		dom := &pb.Domain{}
+		if err := proto.Unmarshal(raw, dom); err != nil {
+			return err
+		}
+		return m.DeleteDomain(dom.GetName())
+	case storage.AttachSketch:
+		req := &pb.AttachSketchRequest{}
+		if err := proto.Unmarshal(raw, req); err != nil {
+			return err
+		}
+		return m.AttachSketch(req.GetDomain(), req.GetSketch())
+	case storage.DetachSketch:
+		req := &pb.DetachSketchRequest{}
+		if err := proto.Unmarshal(raw, req); err != nil {
+			return err
+		}
+		return m.DetachSketch(req.GetDomain(), req.GetSketch())
+	case storage.ClearSketch:
+		sketch := &pb.Sketch{}
+		if err := proto.Unmarshal(raw, sketch); err != nil {
+			return err
+		}
+		info := &datamodel.Info{Sketch: sketch}
+		return m.ClearSketch(info.ID())
+	case storage.RenameSketch:
+		req := &pb.SketchNameRequest{}
+		if err := proto.Unmarshal(raw, req); err != nil {
+			return err
+		}
+		info := &datamodel.Info{Sketch: req.GetSketch()}
+		return m.RenameSketch(info.ID(), req.GetNewName())
+	case storage.CloneSketch:
+		req := &pb.SketchNameRequest{}
+		if err := proto.Unmarshal(raw, req); err != nil {
+			return err
+		}
+		info := &datamodel.Info{Sketch: req.GetSketch()}
+		return m.CloneSketch(info.ID(), req.GetNewName())
+	case storage.ClearDom:
+		dom := &pb.Domain{}
+		if err := proto.Unmarshal(raw, dom); err != nil {
+			return err
+		}
+		return m.ClearDomain(dom.GetName())
+	case storage.RenameDom:
+		req := &pb.DomainNameRequest{}
+		if err := proto.Unmarshal(raw, req); err != nil {
+			return err
+		}
+		return m.RenameDomain(req.GetDomain().GetName(), req.GetNewName())
+	case storage.CloneDom:
+		req := &pb.DomainNameRequest{}
+		if err := proto.Unmarshal(raw, req); err != nil {
+			return err
+		}
+		return m.CloneDomain(req.GetDomain().GetName(), req.GetNewName())
+	default:
+		return nil
+	}
+}
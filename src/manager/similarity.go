@@ -0,0 +1,105 @@
+package manager
+
+import (
+	"sort"
+
+	"datamodel"
+	pb "datamodel/protobuf"
+)
+
+// defaultSimilarLimit is the number of sketches a similarity search returns
+// when the request does not ask for a limit
+const defaultSimilarLimit = 10
+
+// bySimilarity sorts similarities from the largest down, ties by name
+type bySimilarity []*pb.Similarity
+
+func (s bySimilarity) Len() int      { return len(s) }
+func (s bySimilarity) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s bySimilarity) Less(i, j int) bool {
+	if s[i].GetSimilarity() != s[j].GetSimilarity() {
+		return s[i].GetSimilarity() > s[j].GetSimilarity()
+	}
+	return s[i].GetSketch().GetName() < s[j].GetSketch().GetName()
+}
+
+// FindSimilar ranks sketches by the similarity of their values to those of
+// the sketch id. Without candidates every other sketch of the same type is
+// compared, leaving out the ones of another size, with LSH only the ones the
+// band index finds sharing a band with it.
+func (m *Manager) FindSimilar(id string, candidates []string, opts datamodel.SimilarityOptions) ([]*pb.Similarity, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if opts.Limit < 0 || opts.MinSimilarity < 0 || opts.MinSimilarity > 1 {
+		return nil, errInvalidArgument("Invalid similarity limit %d or minimum similarity %v",
+			opts.Limit, opts.MinSimilarity)
+	}
+	if opts.Limit == 0 {
+		opts.Limit = defaultSimilarLimit
+	}
+	info := m.infos.get(id)
+	if info == nil {
+		return nil, errNotFound("No such sketch %s", id)
+	}
+	if t, _ := datamodel.LookupType(info.GetType()); !t.Can(datamodel.SimilarityQuery) {
+		return nil, errInvalidArgument("Sketch of type %s does not support similarity queries", info.GetType())
+	}
+	sketch, err := m.sketches.get(id)
+	if err != nil {
+		return nil, err
+	}
+	// Compare with a copy, so that no two sketches are locked at once
+	target, err := sketch.Clone(info)
+	if err != nil {
+		return nil, err
+	}
+
+	explicit := len(candidates) > 0
+	if !explicit && opts.LSH {
+		bands, err := target.Bands()
+		if err != nil {
+			return nil, unsupported(err)
+		}
+		candidates = m.sketches.bands.candidates(id, info.GetType(), bands)
+	} else if !explicit {
+		for cid, ci := range m.infos.info {
+			if cid != id && ci.GetType() == info.GetType() {
+				candidates = append(candidates, cid)
+			}
+		}
+	}
+
+	var results []*pb.Similarity
+	for _, cid := range candidates {
+		candidate, err := m.sketches.get(cid)
+		if err != nil {
+			return nil, err
+		}
+		if opts.LSH && explicit {
+			shares, err := candidate.SharesBand(target)
+			if err != nil {
+				return nil, unsupported(err)
+			}
+			if !shares {
+				continue
+			}
+		}
+		res, err := candidate.Similarity(target)
+		if err != nil {
+			if explicit {
+				return nil, unsupported(err)
+			}
+			continue
+		}
+		if float64(res.GetSimilarity()) < opts.MinSimilarity {
+			continue
+		}
+		res.Sketch = candidate.Sketch
+		results = append(results, res)
+	}
+	sort.Sort(bySimilarity(results))
+	if len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results, nil
+}
@@ -1,71 +1,253 @@
 package manager
 
 import (
-	"fmt"
+	"time"
 
 	"datamodel"
+	pb "datamodel/protobuf"
 	"sketches"
+	"utils"
 )
 
 type sketchManager struct {
 	sketches map[string]*sketches.SketchProxy
+	bands    *bandIndex
 }
 
 func newSketchManager() *sketchManager {
 	return &sketchManager{
 		sketches: make(map[string]*sketches.SketchProxy),
+		bands:    newBandIndex(),
 	}
 }
 
 // CreateSketch ...
 func (m *sketchManager) create(info *datamodel.Info) error {
 	sketch, err := sketches.CreateSketch(info)
-	if err != nil {
-		return err
+	if _, ok := err.(*sketches.PropertyError); ok {
+		return errInvalidArgument(`Invalid sketch "%s": %s`, info.ID(), err)
+	} else if err != nil {
+		return errResourceExhausted(`Could not allocate sketch "%s": %s`, info.ID(), err)
 	}
 	m.sketches[info.ID()] = sketch
 	return nil
 }
 
-func (m *sketchManager) add(id string, values []string) error {
+// add returns the number of values applied to the sketch
+func (m *sketchManager) add(id string, values []string, at time.Time) (int, error) {
 	sketch, ok := m.sketches[id]
 	if !ok {
-		return fmt.Errorf(`Sketch "%s" does not exists`, id)
+		return 0, errNotFound(`Sketch "%s" does not exists`, id)
 	}
 	if sketch.Locked() {
-		// Append to File here
-		return nil //&lockedError{}
+		return 0, errLocked(`Sketch "%s" is locked`, id)
 	}
 
-	byts := make([][]byte, len(values), len(values))
+	applied, err := sketch.AddAt(toBytes(values), at)
+	if applied > 0 {
+		m.bands.update(id, sketch)
+	}
+	if err != nil {
+		return applied, err
+	}
+	if applied < len(values) {
+		return applied, errResourceExhausted(`Sketch "%s" is saturated, %d of %d values applied`,
+			id, applied, len(values))
+	}
+	return applied, nil
+}
+
+// addKeyed returns the number of values applied to the groups of a keyed
+// sketch
+func (m *sketchManager) addKeyed(id string, keys, values []string) (int, error) {
+	sketch, ok := m.sketches[id]
+	if !ok {
+		return 0, errNotFound(`Sketch "%s" does not exists`, id)
+	}
+	if sketch.Locked() {
+		return 0, errLocked(`Sketch "%s" is locked`, id)
+	}
+	return sketch.AddKeyed(toBytes(keys), toBytes(values))
+}
+
+// addIfAbsent returns for every value whether it was present before
+func (m *sketchManager) addIfAbsent(id string, values []string) ([]*pb.Membership, error) {
+	sketch, err := m.get(id)
+	if err != nil {
+		return nil, err
+	}
+	if sketch.Locked() {
+		return nil, errLocked(`Sketch "%s" is locked`, id)
+	}
+	present, err := sketch.AddIfAbsent(toBytes(values))
+	if err != nil {
+		return nil, unsupported(err)
+	}
+	m.bands.update(id, sketch)
+	res := make([]*pb.Membership, len(values))
 	for i, v := range values {
-		byts[i] = []byte(v)
+		res[i] = &pb.Membership{
+			Value:    utils.Stringp(v),
+			IsMember: utils.Boolp(present[i]),
+		}
 	}
-	// FIXME: return if adding was successful or not
-	_, err := sketch.Add(byts)
-	return err
+	return res, nil
 }
 
 func (m *sketchManager) delete(id string) error {
 	if _, ok := m.sketches[id]; !ok {
-		return fmt.Errorf(`Sketch "%s" does not exists`, id)
+		return errNotFound(`Sketch "%s" does not exists`, id)
+	}
+	delete(m.sketches, id)
+	m.bands.remove(id)
+	return nil
+}
+
+func (m *sketchManager) clear(id string) error {
+	sketch, ok := m.sketches[id]
+	if !ok {
+		return errNotFound(`Sketch "%s" does not exists`, id)
+	}
+	err := sketch.Clear()
+	m.bands.update(id, sketch)
+	return err
+}
+
+func (m *sketchManager) clone(id string, info *datamodel.Info) error {
+	sketch, ok := m.sketches[id]
+	if !ok {
+		return errNotFound(`Sketch "%s" does not exists`, id)
+	}
+	clone, err := sketch.Clone(info)
+	if err != nil {
+		return err
+	}
+	m.sketches[info.ID()] = clone
+	m.bands.update(info.ID(), clone)
+	return nil
+}
+
+func (m *sketchManager) rename(id string, newID string) error {
+	sketch, ok := m.sketches[id]
+	if !ok {
+		return errNotFound(`Sketch "%s" does not exists`, id)
+	}
+	if _, ok := m.sketches[newID]; ok {
+		return errAlreadyExists(`Sketch "%s" already exists`, newID)
 	}
 	delete(m.sketches, id)
+	m.sketches[newID] = sketch
+	m.bands.remove(id)
+	m.bands.update(newID, sketch)
 	return nil
 }
 
-func (m *sketchManager) get(id string, data interface{}) (interface{}, error) {
-	var values []string
-	if data != nil {
-		values = data.([]string)
+func (m *sketchManager) get(id string) (*sketches.SketchProxy, error) {
+	sketch, ok := m.sketches[id]
+	if !ok {
+		return nil, errNotFound("No such key %s", id)
+	}
+	return sketch, nil
+}
+
+func (m *sketchManager) cardinality(id string, confidence float64) (*pb.CardinalityResult, error) {
+	sketch, err := m.get(id)
+	if err != nil {
+		return nil, err
+	}
+	res, err := sketch.Cardinality(confidence)
+	return res, unsupported(err)
+}
+
+func (m *sketchManager) frequency(id string, values []string) (*pb.FrequencyResult, error) {
+	sketch, err := m.get(id)
+	if err != nil {
+		return nil, err
+	}
+	res, err := sketch.Frequency(toBytes(values))
+	return res, unsupported(err)
+}
+
+func (m *sketchManager) membership(id string, values []string) (*pb.MembershipResult, error) {
+	sketch, err := m.get(id)
+	if err != nil {
+		return nil, err
+	}
+	res, err := sketch.Membership(toBytes(values))
+	return res, unsupported(err)
+}
+
+func (m *sketchManager) rankings(id string, opts datamodel.RankingsOptions) (*pb.RankingsResult, error) {
+	sketch, err := m.get(id)
+	if err != nil {
+		return nil, err
+	}
+	res, err := sketch.Rankings(opts)
+	return res, unsupported(err)
+}
+
+func (m *sketchManager) rangeCount(id string, lo, hi int64) (*pb.RangeCountResult, error) {
+	sketch, err := m.get(id)
+	if err != nil {
+		return nil, err
+	}
+	res, err := sketch.RangeCount(lo, hi)
+	return res, unsupported(err)
+}
+
+func (m *sketchManager) sample(id string) (*pb.SampleResult, error) {
+	sketch, err := m.get(id)
+	if err != nil {
+		return nil, err
+	}
+	res, err := sketch.Sample()
+	return res, unsupported(err)
+}
+
+func (m *sketchManager) groups(id string, keys []string, opts datamodel.GroupOptions) ([]*pb.GroupResult, int, error) {
+	sketch, err := m.get(id)
+	if err != nil {
+		return nil, 0, err
+	}
+	res, count, err := sketch.Groups(keys, opts)
+	return res, count, unsupported(err)
+}
+
+// query answers every query the sketch supports
+func (m *sketchManager) query(id string, values []string, confidence float64) (*pb.QueryResult, error) {
+	sketch, err := m.get(id)
+	if err != nil {
+		return nil, err
+	}
+	byts := toBytes(values)
+	result := &pb.QueryResult{}
+	errs := make([]error, 5)
+	result.Cardinality, errs[0] = sketch.Cardinality(confidence)
+	result.Frequency, errs[1] = sketch.Frequency(byts)
+	result.Membership, errs[2] = sketch.Membership(byts)
+	result.Rankings, errs[3] = sketch.Rankings(datamodel.RankingsOptions{})
+	result.Sample, errs[4] = sketch.Sample()
+	for _, err := range errs {
+		if _, ok := err.(*sketches.UnsupportedError); err != nil && !ok {
+			return nil, err
+		}
 	}
+	return result, nil
+}
+
+func toBytes(values []string) [][]byte {
 	byts := make([][]byte, len(values), len(values))
 	for i, v := range values {
 		byts[i] = []byte(v)
 	}
-	v, ok := m.sketches[id]
-	if !ok {
-		return nil, fmt.Errorf("No such key %s", id)
+	return byts
+}
+
+// unsupported turns the error of a query the sketch type can not answer into
+// an InvalidArgumentError
+func unsupported(err error) error {
+	if e, ok := err.(*sketches.UnsupportedError); ok {
+		return errInvalidArgument("%s", e.Error())
 	}
-	return v.Get(byts)
+	return err
 }
@@ -1,7 +1,6 @@
 package server
 
 import (
-	"datamodel"
 	pb "datamodel/protobuf"
 
 	"storage"
@@ -10,23 +9,10 @@ import (
 )
 
 func (s *serverStruct) createDomain(ctx context.Context, in *pb.Domain) (*pb.Domain, error) {
-	info := datamodel.NewEmptyInfo()
-	info.Name = in.Name
-	// FIXME: A Domain's info should have an array of properties for each Sketch (or just an array
-	// of Sketches, like what the proto has). This is just a hack to choose the first Sketch and
-	// use it's info for now
-	info.Properties.MaxUniqueItems = in.GetSketches()[0].GetProperties().MaxUniqueItems
-	info.Properties.Size = in.GetSketches()[0].GetProperties().Size
-	if info.Properties.Size == nil || *info.Properties.Size == 0 {
-		var defaultSize int64 = 100
-		info.Properties.Size = &defaultSize
-	}
-	// FIXME: We should be passing a pb.Domain and not a datamodel.Info to manager.CreateDomain
-	err := s.manager.CreateDomain(info)
-	if err != nil {
+	if err := s.manager.CreateDomain(in); err != nil {
 		return nil, err
 	}
-	return in, nil
+	return s.manager.GetDomain(in.GetName())
 }
 
 func (s *serverStruct) CreateDomain(ctx context.Context, in *pb.Domain) (*pb.Domain, error) {
@@ -62,3 +48,81 @@ func (s *serverStruct) DeleteDomain(ctx context.Context, in *pb.Domain) (*pb.Emp
 func (s *serverStruct) GetDomain(ctx context.Context, in *pb.Domain) (*pb.Domain, error) {
 	return s.manager.GetDomain(in.GetName())
 }
+
+func (s *serverStruct) QueryDomain(ctx context.Context, in *pb.QueryDomainRequest) (*pb.QueryDomainReply, error) {
+	results, err := s.manager.QueryDomain(in.GetName(), in.GetValues(), float64(in.GetConfidence()))
+	if err != nil {
+		return nil, err
+	}
+	return &pb.QueryDomainReply{
+		Name:    in.Name,
+		Results: results,
+	}, nil
+}
+
+func (s *serverStruct) attachSketch(ctx context.Context, in *pb.AttachSketchRequest) (*pb.Domain, error) {
+	if err := s.manager.AttachSketch(in.GetDomain(), in.GetSketch()); err != nil {
+		return nil, err
+	}
+	return s.manager.GetDomain(in.GetDomain())
+}
+
+func (s *serverStruct) AttachSketch(ctx context.Context, in *pb.AttachSketchRequest) (*pb.Domain, error) {
+	if err := s.storage.Append(storage.AttachSketch, in); err != nil {
+		return nil, err
+	}
+	return s.attachSketch(ctx, in)
+}
+
+func (s *serverStruct) detachSketch(ctx context.Context, in *pb.DetachSketchRequest) (*pb.Domain, error) {
+	if err := s.manager.DetachSketch(in.GetDomain(), in.GetSketch()); err != nil {
+		return nil, err
+	}
+	return s.manager.GetDomain(in.GetDomain())
+}
+
+func (s *serverStruct) DetachSketch(ctx context.Context, in *pb.DetachSketchRequest) (*pb.Domain, error) {
+	if err := s.storage.Append(storage.DetachSketch, in); err != nil {
+		return nil, err
+	}
+	return s.detachSketch(ctx, in)
+}
+
+func (s *serverStruct) clearDomain(ctx context.Context, in *pb.Domain) (*pb.Empty, error) {
+	return &pb.Empty{}, s.manager.ClearDomain(in.GetName())
+}
+
+func (s *serverStruct) ClearDomain(ctx context.Context, in *pb.Domain) (*pb.Empty, error) {
+	if err := s.storage.Append(storage.ClearDom, in); err != nil {
+		return nil, err
+	}
+	return s.clearDomain(ctx, in)
+}
+
+func (s *serverStruct) renameDomain(ctx context.Context, in *pb.DomainNameRequest) (*pb.Domain, error) {
+	if err := s.manager.RenameDomain(in.GetDomain().GetName(), in.GetNewName()); err != nil {
+		return nil, err
+	}
+	return s.manager.GetDomain(in.GetNewName())
+}
+
+func (s *serverStruct) RenameDomain(ctx context.Context, in *pb.DomainNameRequest) (*pb.Domain, error) {
+	if err := s.storage.Append(storage.RenameDom, in); err != nil {
+		return nil, err
+	}
+	return s.renameDomain(ctx, in)
+}
+
+func (s *serverStruct) cloneDomain(ctx context.Context, in *pb.DomainNameRequest) (*pb.Domain, error) {
+	if err := s.manager.CloneDomain(in.GetDomain().GetName(), in.GetNewName()); err != nil {
+		return nil, err
+	}
+	return s.manager.GetDomain(in.GetNewName())
+}
+
+func (s *serverStruct) CloneDomain(ctx context.Context, in *pb.DomainNameRequest) (*pb.Domain, error) {
+	if err := s.storage.Append(storage.CloneDom, in); err != nil {
+		return nil, err
+	}
+	return s.cloneDomain(ctx, in)
+}
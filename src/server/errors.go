@@ -0,0 +1,38 @@
+package server
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"manager"
+
+	"golang.org/x/net/context"
+)
+
+// grpcCode returns the gRPC status code matching a typed manager error
+func grpcCode(err error) codes.Code {
+	switch err.(type) {
+	case *manager.NotFoundError:
+		return codes.NotFound
+	case *manager.AlreadyExistsError:
+		return codes.AlreadyExists
+	case *manager.InvalidArgumentError:
+		return codes.InvalidArgument
+	case *manager.LockedError:
+		return codes.FailedPrecondition
+	case *manager.ResourceExhaustedError:
+		return codes.ResourceExhausted
+	default:
+		return grpc.Code(err)
+	}
+}
+
+// errorInterceptor converts the errors returned by the handlers into gRPC
+// errors carrying the matching status code
+func errorInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	res, err := handler(ctx, req)
+	if err == nil {
+		return res, nil
+	}
+	return res, grpc.Errorf(grpcCode(err), "%s", grpc.ErrorDesc(err))
+}
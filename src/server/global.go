@@ -4,6 +4,7 @@ import (
 	pb "datamodel/protobuf"
 	"errors"
 
+	"github.com/gogo/protobuf/proto"
 	"golang.org/x/net/context"
 )
 
@@ -13,3 +14,12 @@ func (s *serverStruct) CreateSnapshot(ctx context.Context, in *pb.CreateSnapshot
 	status := pb.SnapshotStatus_FAILED
 	return &pb.CreateSnapshotReply{Status: &status}, errors.New("Snapshots not supported (yet)!")
 }
+
+func (s *serverStruct) GetSnapshot(ctx context.Context, in *pb.GetSnapshotRequest) (*pb.GetSnapshotReply, error) {
+	// FIXME: report the state of the last snapshot once snapshots are supported
+	status := pb.SnapshotStatus_FAILED
+	return &pb.GetSnapshotReply{
+		Status:        &status,
+		StatusMessage: proto.String("Snapshots not supported (yet)!"),
+	}, nil
+}
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"time"
+
 	"datamodel"
 	pb "datamodel/protobuf"
 	"storage"
@@ -8,6 +10,8 @@ import (
 	"github.com/gogo/protobuf/proto"
 	"github.com/njpatel/loggo"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 )
 
 var logger = loggo.GetLogger("server")
@@ -28,41 +32,73 @@ func (s *serverStruct) CreateSketch(ctx context.Context, in *pb.Sketch) (*pb.Ske
 }
 
 func (s *serverStruct) add(ctx context.Context, in *pb.AddRequest) (*pb.AddReply, error) {
-	info := datamodel.NewEmptyInfo()
-	// FIXME: use domain or sketch directly and stop casting to Info
-	if dom := in.GetDomain(); dom != nil {
-		info.Name = dom.Name
-		err := s.manager.AddToDomain(info.GetName(), in.GetValues())
+	reply := &pb.AddReply{}
+	at := time.Unix(0, in.GetTimestamp())
+	if len(in.GetKeys()) > 0 {
+		if in.GetDomain() != nil || in.GetSketch() == nil {
+			return nil, grpc.Errorf(codes.InvalidArgument, "Values with a group key can only be added to a sketch")
+		}
+		info := &datamodel.Info{Sketch: in.GetSketch()}
+		res, err := s.manager.AddToGroups(info.ID(), in.GetKeys(), in.GetValues())
+		if err != nil {
+			return nil, err
+		}
+		reply.Results = []*pb.AddResult{res}
+	} else if dom := in.GetDomain(); dom != nil {
+		results, err := s.manager.AddToDomainAt(dom.GetName(), in.GetValues(), at)
 		if err != nil {
 			return nil, err
 		}
+		reply.Results = results
 	} else if sketch := in.GetSketch(); sketch != nil {
 		info := &datamodel.Info{Sketch: sketch}
-		err := s.manager.AddToSketch(info.ID(), in.GetValues())
+		res, err := s.manager.AddToSketchAt(info.ID(), in.GetValues(), at)
 		if err != nil {
 			return nil, err
 		}
+		reply.Results = []*pb.AddResult{res}
 	}
-	return &pb.AddReply{}, nil
+	return reply, nil
 }
 
 func (s *serverStruct) Add(ctx context.Context, in *pb.AddRequest) (*pb.AddReply, error) {
+	// The time is recorded with the values so that replaying the AOF decays
+	// them the same way
+	if in.Timestamp == nil {
+		in.Timestamp = proto.Int64(time.Now().UnixNano())
+	}
 	if err := s.storage.Append(storage.Add, in); err != nil {
 		return nil, err
 	}
 	return s.add(ctx, in)
 }
 
+func (s *serverStruct) addIfAbsent(ctx context.Context, in *pb.AddIfAbsentRequest) (*pb.AddIfAbsentReply, error) {
+	info := &datamodel.Info{Sketch: in.GetSketch()}
+	results, err := s.manager.AddIfAbsent(info.ID(), in.GetValues())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.AddIfAbsentReply{Results: results}, nil
+}
+
+func (s *serverStruct) AddIfAbsent(ctx context.Context, in *pb.AddIfAbsentRequest) (*pb.AddIfAbsentReply, error) {
+	if err := s.storage.Append(storage.AddIfAbsent, in); err != nil {
+		return nil, err
+	}
+	return s.addIfAbsent(ctx, in)
+}
+
 func (s *serverStruct) GetMembership(ctx context.Context, in *pb.GetRequest) (*pb.GetMembershipReply, error) {
 	reply := &pb.GetMembershipReply{}
 
 	for _, sketch := range in.GetSketches() {
 		info := &datamodel.Info{Sketch: sketch}
-		res, err := s.manager.GetFromSketch(info.ID(), in.GetValues())
+		res, err := s.manager.GetMembership(info.ID(), in.GetValues())
 		if err != nil {
 			return nil, err
 		}
-		reply.Results = append(reply.Results, res.(*pb.MembershipResult))
+		reply.Results = append(reply.Results, res)
 	}
 	return reply, nil
 }
@@ -72,11 +108,11 @@ func (s *serverStruct) GetFrequency(ctx context.Context, in *pb.GetRequest) (*pb
 
 	for _, sketch := range in.GetSketches() {
 		info := &datamodel.Info{Sketch: sketch}
-		res, err := s.manager.GetFromSketch(info.ID(), in.GetValues())
+		res, err := s.manager.GetFrequency(info.ID(), in.GetValues())
 		if err != nil {
 			return nil, err
 		}
-		reply.Results = append(reply.Results, res.(*pb.FrequencyResult))
+		reply.Results = append(reply.Results, res)
 	}
 	return reply, nil
 }
@@ -86,30 +122,97 @@ func (s *serverStruct) GetCardinality(ctx context.Context, in *pb.GetRequest) (*
 
 	for _, sketch := range in.GetSketches() {
 		info := &datamodel.Info{Sketch: sketch}
-		res, err := s.manager.GetFromSketch(info.ID(), in.GetValues())
+		res, err := s.manager.GetCardinality(info.ID(), float64(in.GetConfidence()))
 		if err != nil {
 			return nil, err
 		}
-		reply.Results = append(reply.Results, res.(*pb.CardinalityResult))
+		reply.Results = append(reply.Results, res)
 	}
 	return reply, nil
 }
 
 func (s *serverStruct) GetRankings(ctx context.Context, in *pb.GetRequest) (*pb.GetRankingsReply, error) {
 	reply := &pb.GetRankingsReply{}
+	opts := datamodel.RankingsOptions{
+		Limit:    int(in.GetLimit()),
+		Offset:   int(in.GetOffset()),
+		MinCount: in.GetMinCount(),
+	}
 
 	for _, sketch := range in.GetSketches() {
 		info := &datamodel.Info{Sketch: sketch}
+		res, err := s.manager.GetRankings(info.ID(), opts)
+		if err != nil {
+			return nil, err
+		}
+		reply.Results = append(reply.Results, res)
+	}
+	return reply, nil
+}
+
+func (s *serverStruct) GetRangeCount(ctx context.Context, in *pb.RangeCountRequest) (*pb.GetRangeCountReply, error) {
+	reply := &pb.GetRangeCountReply{}
 
-		res, err := s.manager.GetFromSketch(info.ID(), in.GetValues())
+	for _, sketch := range in.GetSketches() {
+		info := &datamodel.Info{Sketch: sketch}
+		res, err := s.manager.GetRangeCount(info.ID(), in.GetLo(), in.GetHi())
 		if err != nil {
 			return nil, err
 		}
-		reply.Results = append(reply.Results, res.(*pb.RankingsResult))
+		reply.Results = append(reply.Results, res)
 	}
 	return reply, nil
 }
 
+func (s *serverStruct) GetSample(ctx context.Context, in *pb.GetRequest) (*pb.GetSampleReply, error) {
+	reply := &pb.GetSampleReply{}
+
+	for _, sketch := range in.GetSketches() {
+		info := &datamodel.Info{Sketch: sketch}
+		res, err := s.manager.GetSample(info.ID())
+		if err != nil {
+			return nil, err
+		}
+		reply.Results = append(reply.Results, res)
+	}
+	return reply, nil
+}
+
+func (s *serverStruct) GetGroups(ctx context.Context, in *pb.GroupsRequest) (*pb.GetGroupsReply, error) {
+	info := &datamodel.Info{Sketch: in.GetSketch()}
+	opts := datamodel.GroupOptions{
+		Limit:      int(in.GetLimit()),
+		Confidence: float64(in.GetConfidence()),
+	}
+	results, count, err := s.manager.GetGroups(info.ID(), in.GetKeys(), opts)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetGroupsReply{Results: results, Groups: proto.Int64(int64(count))}, nil
+}
+
+func (s *serverStruct) GetCardinalityExpression(ctx context.Context, in *pb.CardinalityExpressionRequest) (*pb.CardinalityExpressionReply, error) {
+	return s.manager.GetCardinalityExpression(in.GetExpression(), in.GetType(), float64(in.GetConfidence()))
+}
+
+func (s *serverStruct) FindSimilar(ctx context.Context, in *pb.FindSimilarRequest) (*pb.FindSimilarReply, error) {
+	info := &datamodel.Info{Sketch: in.GetSketch()}
+	var candidates []string
+	for _, sketch := range in.GetCandidates() {
+		candidates = append(candidates, (&datamodel.Info{Sketch: sketch}).ID())
+	}
+	opts := datamodel.SimilarityOptions{
+		Limit:         int(in.GetLimit()),
+		MinSimilarity: float64(in.GetMinSimilarity()),
+		LSH:           in.GetLsh(),
+	}
+	results, err := s.manager.FindSimilar(info.ID(), candidates, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.FindSimilarReply{Results: results}, nil
+}
+
 func (s *serverStruct) deleteSketch(ctx context.Context, in *pb.Sketch) (*pb.Empty, error) {
 	info := &datamodel.Info{Sketch: in}
 	return &pb.Empty{}, s.manager.DeleteSketch(info.ID())
@@ -122,52 +225,90 @@ func (s *serverStruct) DeleteSketch(ctx context.Context, in *pb.Sketch) (*pb.Emp
 	return s.deleteSketch(ctx, in)
 }
 
+// getNamedSketch returns the sketch that a rename or clone request resulted in
+func (s *serverStruct) getNamedSketch(in *pb.SketchNameRequest) (*pb.Sketch, error) {
+	info := &datamodel.Info{Sketch: &pb.Sketch{Name: in.NewName, Type: in.GetSketch().Type}}
+	info, err := s.manager.GetSketch(info.ID())
+	if err != nil {
+		return nil, err
+	}
+	return info.Sketch, nil
+}
+
+func (s *serverStruct) clearSketch(ctx context.Context, in *pb.Sketch) (*pb.Empty, error) {
+	info := &datamodel.Info{Sketch: in}
+	return &pb.Empty{}, s.manager.ClearSketch(info.ID())
+}
+
+func (s *serverStruct) ClearSketch(ctx context.Context, in *pb.Sketch) (*pb.Empty, error) {
+	if err := s.storage.Append(storage.ClearSketch, in); err != nil {
+		return nil, err
+	}
+	return s.clearSketch(ctx, in)
+}
+
+func (s *serverStruct) renameSketch(ctx context.Context, in *pb.SketchNameRequest) (*pb.Sketch, error) {
+	info := &datamodel.Info{Sketch: in.GetSketch()}
+	if err := s.manager.RenameSketch(info.ID(), in.GetNewName()); err != nil {
+		return nil, err
+	}
+	return s.getNamedSketch(in)
+}
+
+func (s *serverStruct) RenameSketch(ctx context.Context, in *pb.SketchNameRequest) (*pb.Sketch, error) {
+	if err := s.storage.Append(storage.RenameSketch, in); err != nil {
+		return nil, err
+	}
+	return s.renameSketch(ctx, in)
+}
+
+func (s *serverStruct) cloneSketch(ctx context.Context, in *pb.SketchNameRequest) (*pb.Sketch, error) {
+	info := &datamodel.Info{Sketch: in.GetSketch()}
+	if err := s.manager.CloneSketch(info.ID(), in.GetNewName()); err != nil {
+		return nil, err
+	}
+	return s.getNamedSketch(in)
+}
+
+func (s *serverStruct) CloneSketch(ctx context.Context, in *pb.SketchNameRequest) (*pb.Sketch, error) {
+	if err := s.storage.Append(storage.CloneSketch, in); err != nil {
+		return nil, err
+	}
+	return s.cloneSketch(ctx, in)
+}
+
 func (s *serverStruct) ListAll(ctx context.Context, in *pb.Empty) (*pb.ListReply, error) {
 	sketches := s.manager.GetSketches()
 	filtered := &pb.ListReply{}
 	for _, v := range sketches {
-		var typ pb.SketchType
-		switch v[1] {
-		case datamodel.CML:
-			typ = pb.SketchType_FREQ
-		case datamodel.TopK:
-			typ = pb.SketchType_RANK
-		case datamodel.HLLPP:
-			typ = pb.SketchType_CARD
-		case datamodel.Bloom:
-			typ = pb.SketchType_MEMB
-		default:
+		t, ok := datamodel.LookupName(v[1])
+		if !ok {
 			continue
 		}
+		typ := t.Type
 		filtered.Sketches = append(filtered.Sketches, &pb.Sketch{Name: proto.String(v[0]), Type: &typ})
 	}
 	return filtered, nil
 }
 
 func (s *serverStruct) GetSketch(ctx context.Context, in *pb.Sketch) (*pb.Sketch, error) {
-	var err error
 	info := &datamodel.Info{Sketch: in}
-	info, err = s.manager.GetSketch(info.ID())
-	return in, err
+	info, err := s.manager.GetSketch(info.ID())
+	if err != nil {
+		return nil, err
+	}
+	return info.Sketch, nil
 }
 
 func (s *serverStruct) List(ctx context.Context, in *pb.ListRequest) (*pb.ListReply, error) {
 	sketches := s.manager.GetSketches()
 	filtered := &pb.ListReply{}
 	for _, v := range sketches {
-		var typ pb.SketchType
-		switch v[1] {
-		case datamodel.CML:
-			typ = pb.SketchType_FREQ
-		case datamodel.TopK:
-			typ = pb.SketchType_RANK
-		case datamodel.HLLPP:
-			typ = pb.SketchType_CARD
-		case datamodel.Bloom:
-			typ = pb.SketchType_MEMB
-		default:
+		t, ok := datamodel.LookupName(v[1])
+		if !ok {
 			continue
 		}
+		typ := t.Type
 		if in.GetType() == typ {
 			filtered.Sketches = append(filtered.Sketches, &pb.Sketch{Name: proto.String(v[0]), Type: &typ})
 		}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/gogo/protobuf/proto"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 
 	"config"
 	pb "datamodel/protobuf"
@@ -111,8 +113,12 @@ func TestCreateAddDeleteAddSketch(t *testing.T) {
 		t.Error("Did not expect error, got", err)
 	}
 
-	if _, err := client.Add(context.Background(), addReq); err != nil {
+	if res, err := client.Add(context.Background(), addReq); err != nil {
 		t.Error("Did not expect error, got", err)
+	} else if len(res.GetResults()) != 1 {
+		t.Error("Expected len(res) == 1, got ", len(res.GetResults()))
+	} else if r := res.GetResults()[0]; !r.GetSuccess() || r.GetApplied() != 6 {
+		t.Error("Expected 6 values applied, got", r)
 	}
 
 	if res, err := client.ListAll(context.Background(), &pb.Empty{}); err != nil {
@@ -355,3 +361,51 @@ func TestAddGetRankSketch(t *testing.T) {
 		}
 	}
 }
+
+func TestErrorCodes(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	client, conn := setupClient()
+	defer tearDownClient(conn)
+
+	typ := pb.SketchType_CARD
+	in := &pb.Sketch{
+		Name: proto.String("yoyo"),
+		Type: &typ,
+	}
+
+	if _, err := client.GetCardinality(context.Background(), &pb.GetRequest{Sketches: []*pb.Sketch{in}}); grpc.Code(err) != codes.NotFound {
+		t.Error("Expected NotFound, got", err)
+	}
+	if _, err := client.CreateSketch(context.Background(), in); err != nil {
+		t.Error("Did not expect error, got", err)
+	}
+	if _, err := client.CreateSketch(context.Background(), in); grpc.Code(err) != codes.AlreadyExists {
+		t.Error("Expected AlreadyExists, got", err)
+	}
+	// Querying a sketch for something its type does not support
+	req := &pb.GetRequest{Sketches: []*pb.Sketch{in}, Values: []string{"hulk"}}
+	if _, err := client.GetFrequency(context.Background(), req); grpc.Code(err) != codes.InvalidArgument {
+		t.Error("Expected InvalidArgument, got", err)
+	}
+	if _, err := client.GetRankings(context.Background(), req); grpc.Code(err) != codes.InvalidArgument {
+		t.Error("Expected InvalidArgument, got", err)
+	}
+
+	dom := &pb.Domain{
+		Name: proto.String("marvel"),
+		Sketches: []*pb.Sketch{{
+			Name:       proto.String("marvel"),
+			Type:       &typ,
+			Properties: &pb.SketchProperties{ErrorRate: proto.Float32(2)},
+		}},
+	}
+	if _, err := client.CreateDomain(context.Background(), dom); grpc.Code(err) != codes.InvalidArgument {
+		t.Error("Expected InvalidArgument, got", err)
+	}
+	if _, err := client.DeleteDomain(context.Background(), dom); grpc.Code(err) != codes.NotFound {
+		t.Error("Expected NotFound, got", err)
+	}
+}
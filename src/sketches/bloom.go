@@ -1,6 +1,9 @@
 package sketches
 
 import (
+	"encoding/json"
+	"math"
+
 	bloom "github.com/AndreasBriese/bbloom"
 
 	"datamodel"
@@ -8,51 +11,166 @@ import (
 	"utils"
 )
 
+func init() {
+	datamodel.Register(&datamodel.SketchType{
+		Type: pb.SketchType_MEMB,
+		Name: datamodel.Bloom,
+		New: func(info *datamodel.Info) (datamodel.Sketcher, error) {
+			return NewBloomSketch(info)
+		},
+		Defaults:     &pb.SketchProperties{MaxUniqueItems: utils.Int64p(datamodel.DefaultMaxUniqueItems)},
+		Capabilities: datamodel.MembershipQuery | datamodel.AddIfAbsentQuery,
+	})
+}
+
 // BloomSketch is the toplevel Sketch to control the count-min-log implementation
 type BloomSketch struct {
 	*datamodel.Info
 	impl *bloom.Bloom
+	// members is the number of values that were absent when they were added
+	members uint64
+	// bits and hashes are the dimensions of the filter
+	bits, hashes uint64
+}
+
+// bloomHashes is the number of bits set for every value
+const bloomHashes = 4
+
+// maxBloomBits bounds the size of a filter, far beyond what fits in memory,
+// so that sizing it can not overflow
+const maxBloomBits = 1 << 40
+
+// bloomState is the serialized form of a BloomSketch
+type bloomState struct {
+	Filter  []byte
+	Members uint64
+}
+
+// bloomDimensions returns the number of bits and of hashes of the filter of a
+// sketch. Without an errorRate the filter has a bit per unique item and
+// bloomHashes hashes, otherwise it is sized to hold maxUniqueItems members at
+// that false positive rate. The bits are rounded up to a power of two of at
+// least 512, as the filter does.
+func bloomDimensions(props *pb.SketchProperties) (uint64, uint64, error) {
+	n, p := float64(props.GetMaxUniqueItems()), float64(props.GetErrorRate())
+	if n < 0 {
+		return 0, 0, errProperty("Invalid maxUniqueItems %d, must not be negative", props.GetMaxUniqueItems())
+	}
+	if !(p >= 0 && p < 1) {
+		return 0, 0, errProperty("Invalid errorRate %v, must be between 0 and 1", p)
+	}
+	bits, hashes := n, float64(bloomHashes)
+	if p > 0 && n > 0 {
+		bits = math.Ceil(-n * math.Log(p) / (math.Ln2 * math.Ln2))
+		hashes = math.Ceil(math.Ln2 * bits / n)
+	}
+	if bits > maxBloomBits {
+		return 0, 0, errProperty("Can not size a filter of %.0f bits for maxUniqueItems %d and errorRate %v, at most %d bits",
+			bits, props.GetMaxUniqueItems(), p, uint64(maxBloomBits))
+	}
+	size := uint64(512)
+	for size < uint64(bits) {
+		size <<= 1
+	}
+	return size, uint64(hashes), nil
 }
 
 // NewBloomSketch ...
 func NewBloomSketch(info *datamodel.Info) (*BloomSketch, error) {
-	// FIXME: We are converting from int64 to uint
-	sketch := bloom.New(float64(info.Properties.GetMaxUniqueItems()), 4.0)
-	d := BloomSketch{info, &sketch}
+	bits, hashes, err := bloomDimensions(info.Properties)
+	if err != nil {
+		return nil, err
+	}
+	sketch := bloom.New(float64(bits), float64(hashes))
+	d := BloomSketch{Info: info, impl: &sketch, bits: bits, hashes: hashes}
 	return &d, nil
 }
 
 // Add ...
-func (d *BloomSketch) Add(values [][]byte) (bool, error) {
-	success := true
+func (d *BloomSketch) Add(values [][]byte) (int, error) {
 	dict := make(map[string]uint)
 	for _, v := range values {
 		dict[string(v)]++
 	}
 	for v := range dict {
-		d.impl.Add([]byte(v))
+		if d.impl.AddIfNotHas([]byte(v)) {
+			d.members++
+		}
 	}
-	// Fixme: return what was added and what not
-	return success, nil
+	return len(values), nil
 }
 
-// Get ...
-func (d *BloomSketch) Get(data interface{}) (interface{}, error) {
-	values := data.([][]byte)
+// AddIfAbsent adds the values that are not members yet. A value given twice
+// is present the second time.
+func (d *BloomSketch) AddIfAbsent(values [][]byte) ([]bool, error) {
+	present := make([]bool, len(values))
+	for i, v := range values {
+		present[i] = !d.impl.AddIfNotHas(v)
+		if !present[i] {
+			d.members++
+		}
+	}
+	return present, nil
+}
+
+// falsePositiveRate estimates the probability that an absent value is
+// reported as a member, (1 - e^(-kn/m))^k for k hashes, n members and m bits
+func (d *BloomSketch) falsePositiveRate() float64 {
+	k := float64(d.hashes)
+	return math.Pow(1-math.Exp(-k*float64(d.members)/float64(d.bits)), k)
+}
+
+// Membership ...
+func (d *BloomSketch) Membership(values [][]byte) (*pb.MembershipResult, error) {
 	tmpRes := make(map[string]*pb.Membership)
 	res := &pb.MembershipResult{
 		Memberships: make([]*pb.Membership, len(values), len(values)),
 	}
+	fpRate := utils.Float32p(float32(d.falsePositiveRate()))
 	for i, v := range values {
 		if r, ok := tmpRes[string(v)]; ok {
 			res.Memberships[i] = r
 			continue
 		}
 		res.Memberships[i] = &pb.Membership{
-			Value:    utils.Stringp(string(v)),
-			IsMember: utils.Boolp(d.impl.Has(v)),
+			Value:             utils.Stringp(string(v)),
+			IsMember:          utils.Boolp(d.impl.Has(v)),
+			FalsePositiveRate: fpRate,
 		}
 		tmpRes[string(v)] = res.Memberships[i]
 	}
 	return res, nil
 }
+
+// Marshal ...
+func (d *BloomSketch) Marshal() ([]byte, error) {
+	return json.Marshal(&bloomState{d.impl.JSONMarshal(), d.members})
+}
+
+// Unmarshal ...
+func (d *BloomSketch) Unmarshal(data []byte) error {
+	state := &bloomState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return err
+	}
+	if state.Filter == nil {
+		// Snapshots written before the member count was kept only hold the
+		// filter itself
+		state.Filter = data
+	}
+	impl := bloom.JSONUnmarshal(state.Filter)
+	d.impl = &impl
+	d.members = state.Members
+	return nil
+}
+
+// Clone ...
+func (d *BloomSketch) Clone(info *datamodel.Info) (datamodel.Sketcher, error) {
+	return &BloomSketch{
+		Info:    info,
+		impl:    deepCopy(d.impl).(*bloom.Bloom),
+		members: d.members,
+		bits:    d.bits,
+		hashes:  d.hashes,
+	}, nil
+}
@@ -1,6 +1,7 @@
 package sketches
 
 import (
+	"math"
 	"strconv"
 	"testing"
 
@@ -46,10 +47,10 @@ func TestAddBloom(t *testing.T) {
 		"rogue":       false,
 		"storm":       false}
 
-	if res, err := sketch.Get(values); err != nil {
+	if res, err := sketch.Membership(values); err != nil {
 		t.Error("expected no errors, got", err)
 	} else {
-		tmp := res.(*pb.MembershipResult)
+		tmp := res
 		mres := tmp.GetMemberships()
 		for key := range check {
 			for i := 0; i < len(mres); i++ {
@@ -62,6 +63,117 @@ func TestAddBloom(t *testing.T) {
 	}
 }
 
+func TestFalsePositiveRate(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	info := datamodel.NewEmptyInfo()
+	info.Properties.MaxUniqueItems = utils.Int64p(1024)
+	info.Name = utils.Stringp("marvel")
+	sketch, err := NewBloomSketch(info)
+	if err != nil {
+		t.Error("expected no errors, got", err)
+	}
+
+	query := [][]byte{[]byte("hulk")}
+	if res, err := sketch.Membership(query); err != nil {
+		t.Error("expected no errors, got", err)
+	} else if r := res.Memberships[0].GetFalsePositiveRate(); r != 0 {
+		t.Error("expected false positive rate == 0, got", r)
+	}
+
+	values := [][]byte{[]byte("thor"), []byte("loki"), []byte("loki")}
+	if _, err := sketch.Add(values); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	res, err := sketch.Membership(query)
+	if err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	few := res.Memberships[0].GetFalsePositiveRate()
+	if few <= 0 {
+		t.Error("expected false positive rate > 0, got", few)
+	}
+
+	values = nil
+	for i := 0; i < 1000; i++ {
+		values = append(values, []byte("avenger"+strconv.Itoa(i)))
+	}
+	if _, err := sketch.Add(values); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	if res, err := sketch.Membership(query); err != nil {
+		t.Error("expected no errors, got", err)
+	} else if r := res.Memberships[0].GetFalsePositiveRate(); r <= few || r >= 1 {
+		t.Errorf("expected false positive rate in ]%v, 1[, got %v", few, r)
+	}
+}
+
+func TestBloomErrorRate(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	var members, absent [][]byte
+	for i := 0; i < 10000; i++ {
+		members = append(members, []byte("avenger"+strconv.Itoa(i)))
+		absent = append(absent, []byte("villain"+strconv.Itoa(i)))
+	}
+	falsePositives := func(errorRate float32) (int, float32) {
+		info := datamodel.NewEmptyInfo()
+		info.Properties.MaxUniqueItems = utils.Int64p(10000)
+		info.Properties.ErrorRate = utils.Float32p(errorRate)
+		info.Name = utils.Stringp("marvel")
+		sketch, err := NewBloomSketch(info)
+		if err != nil {
+			t.Fatal("expected no errors, got", err)
+		}
+		if _, err := sketch.Add(members); err != nil {
+			t.Error("expected no errors, got", err)
+		}
+		res, err := sketch.Membership(absent)
+		if err != nil {
+			t.Fatal("expected no errors, got", err)
+		}
+		count := 0
+		for _, m := range res.Memberships {
+			if m.GetIsMember() {
+				count++
+			}
+		}
+		return count, res.Memberships[0].GetFalsePositiveRate()
+	}
+
+	// A full filter sized for its errorRate stays within it
+	count, rate := falsePositives(0.001)
+	if count > 20 || rate > 0.001 {
+		t.Errorf("expected at most 20 false positives at a rate <= 0.001, got %d at %v", count, rate)
+	}
+	// Without an errorRate the filter keeps a bit per unique item
+	if count, rate = falsePositives(0); count < 1000 || rate < 0.1 {
+		t.Errorf("expected many false positives without errorRate, got %d at %v", count, rate)
+	}
+}
+
+func TestBloomInvalidDimensions(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	for _, props := range []*pb.SketchProperties{
+		{MaxUniqueItems: utils.Int64p(1000), ErrorRate: utils.Float32p(1.5)},
+		{MaxUniqueItems: utils.Int64p(1000), ErrorRate: utils.Float32p(1)},
+		{MaxUniqueItems: utils.Int64p(-1)},
+		{MaxUniqueItems: utils.Int64p(math.MaxInt64)},
+		{MaxUniqueItems: utils.Int64p(1 << 40), ErrorRate: utils.Float32p(0.001)},
+	} {
+		info := datamodel.NewEmptyInfo()
+		info.Properties = props
+		info.Name = utils.Stringp("marvel")
+		if _, err := NewBloomSketch(info); err == nil {
+			t.Error("expected error sizing a filter for", props, "got", err)
+		}
+	}
+}
+
 func TestStressBloom(t *testing.T) {
 	testutils.SetupTests()
 	defer testutils.TearDownTests()
@@ -1,6 +1,9 @@
 package sketches
 
 import (
+	"math"
+	"strconv"
+
 	"github.com/skizzehq/count-min-log"
 
 	"datamodel"
@@ -8,45 +11,79 @@ import (
 	"utils"
 )
 
+func init() {
+	datamodel.Register(&datamodel.SketchType{
+		Type: pb.SketchType_FREQ,
+		Name: datamodel.CML,
+		New: func(info *datamodel.Info) (datamodel.Sketcher, error) {
+			if info.Properties.GetHalfLife() > 0 {
+				return NewDecayingCMLSketch(info)
+			}
+			return NewCMLSketch(info)
+		},
+		Defaults:     &pb.SketchProperties{MaxUniqueItems: utils.Int64p(datamodel.DefaultMaxUniqueItems)},
+		Capabilities: datamodel.FrequencyQuery | datamodel.Decay,
+	})
+}
+
+// cmlErrorRate is the relative error (ε) the count-min-log sketches are
+// created with unless they have an errorRate, a frequency overestimates by at
+// most ε times the total count with high probability
+const cmlErrorRate = 0.01
+
+// cmlEpsilon returns the relative error of a sketch, its errorRate or
+// cmlErrorRate if it has none. The float32 errorRate is widened to the
+// decimal it was given as, float64(float32(0.05)) would round bounds up.
+func cmlEpsilon(info *datamodel.Info) float64 {
+	if e := info.Properties.GetErrorRate(); e > 0 {
+		eps, _ := strconv.ParseFloat(strconv.FormatFloat(float64(e), 'g', -1, 32), 64)
+		return eps
+	}
+	return cmlErrorRate
+}
+
 // CMLSketch is the toplevel Sketch to control the count-min-log implementation
 type CMLSketch struct {
 	*datamodel.Info
-	impl *cml.Sketch
+	impl  *cml.Sketch
+	total uint64
 }
 
 // NewCMLSketch ...
 func NewCMLSketch(info *datamodel.Info) (*CMLSketch, error) {
-	sketch, err := cml.NewForCapacity16(uint64(info.Properties.GetMaxUniqueItems()), 0.01)
-	d := CMLSketch{info, sketch}
+	sketch, err := cml.NewForCapacity16(uint64(info.Properties.GetMaxUniqueItems()), cmlEpsilon(info))
 	if err != nil {
-		logger.Errorf("an error has occurred while saving CMLSketch: %s", err.Error())
+		return nil, err
 	}
+	d := CMLSketch{info, sketch, 0}
 	return &d, nil
 }
 
 // Add ...
-func (d *CMLSketch) Add(values [][]byte) (bool, error) {
-	success := true
+func (d *CMLSketch) Add(values [][]byte) (int, error) {
+	applied := 0
 
 	dict := make(map[string]uint)
 	for _, v := range values {
 		dict[string(v)]++
 	}
 	for v, count := range dict {
-		if b := d.impl.BulkUpdate([]byte(v), count); !b {
-			success = false
+		// BulkUpdate fails once the counters of a value are saturated
+		if b := d.impl.BulkUpdate([]byte(v), count); b {
+			applied += int(count)
 		}
 	}
-	return success, nil
+	d.total += uint64(applied)
+	return applied, nil
 }
 
-// Get ...
-func (d *CMLSketch) Get(data interface{}) (interface{}, error) {
-	values := data.([][]byte)
+// Frequency ...
+func (d *CMLSketch) Frequency(values [][]byte) (*pb.FrequencyResult, error) {
 	res := &pb.FrequencyResult{
 		Frequencies: make([]*pb.Frequency, len(values), len(values)),
 	}
 	tmpRes := make(map[string]*pb.Frequency)
+	errBound := int64(math.Ceil(cmlEpsilon(d.Info) * float64(d.total)))
 	for i, v := range values {
 		if r, ok := tmpRes[string(v)]; ok {
 			res.Frequencies[i] = r
@@ -55,8 +92,14 @@ func (d *CMLSketch) Get(data interface{}) (interface{}, error) {
 		res.Frequencies[i] = &pb.Frequency{
 			Value: utils.Stringp(string(v)),
 			Count: utils.Int64p(int64(d.impl.Query(v))),
+			Error: utils.Int64p(errBound),
 		}
 		tmpRes[string(v)] = res.Frequencies[i]
 	}
 	return res, nil
 }
+
+// Clone ...
+func (d *CMLSketch) Clone(info *datamodel.Info) (datamodel.Sketcher, error) {
+	return &CMLSketch{info, deepCopy(d.impl).(*cml.Sketch), d.total}, nil
+}
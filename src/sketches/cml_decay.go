@@ -0,0 +1,105 @@
+package sketches
+
+import (
+	"hash/fnv"
+	"math"
+	"time"
+
+	"datamodel"
+	pb "datamodel/protobuf"
+
+	"utils"
+)
+
+// decayingCMLDepth is the number of rows of the count-min sketch of a
+// DecayingCMLSketch, the overestimate of a frequency stays within ε times the
+// total count for all but e^-depth of the values. The width of the rows is
+// ceil(e / ε).
+const decayingCMLDepth = 5
+
+// DecayingCMLSketch counts frequencies decayed with the halfLife of the
+// sketch. It is a count-min sketch with weighted counters, the logarithmic
+// counters of count-min-log can not hold the weights.
+type DecayingCMLSketch struct {
+	*datamodel.Info
+	decay    forwardDecay
+	counters [decayingCMLDepth][]float64
+	total    float64
+}
+
+// NewDecayingCMLSketch ...
+func NewDecayingCMLSketch(info *datamodel.Info) (*DecayingCMLSketch, error) {
+	d := &DecayingCMLSketch{Info: info, decay: newForwardDecay(info)}
+	width := int(math.Ceil(math.E / cmlEpsilon(info)))
+	for i := range d.counters {
+		d.counters[i] = make([]float64, width)
+	}
+	return d, nil
+}
+
+// locations returns the counter of value in every row of the given width,
+// derived from a single 64 bit hash
+func locations(value []byte, width int) [decayingCMLDepth]int {
+	hash := fnv.New64a()
+	_, _ = hash.Write(value)
+	sum := hash.Sum64()
+	h1, h2 := uint32(sum), uint32(sum>>32)
+	var locs [decayingCMLDepth]int
+	for i := range locs {
+		locs[i] = int((h1 + uint32(i)*h2) % uint32(width))
+	}
+	return locs
+}
+
+// Add ...
+func (d *DecayingCMLSketch) Add(values [][]byte) (int, error) {
+	return d.AddAt(values, now())
+}
+
+// AddAt adds values seen at the given time
+func (d *DecayingCMLSketch) AddAt(values [][]byte, at time.Time) (int, error) {
+	weight, rescale := d.decay.weight(at)
+	if rescale != 1 {
+		for i := range d.counters {
+			for j := range d.counters[i] {
+				d.counters[i][j] *= rescale
+			}
+		}
+		d.total *= rescale
+	}
+	for _, v := range values {
+		for i, j := range locations(v, len(d.counters[0])) {
+			d.counters[i][j] += weight
+		}
+	}
+	d.total += weight * float64(len(values))
+	return len(values), nil
+}
+
+// Frequency returns the counts of values decayed to the current time
+func (d *DecayingCMLSketch) Frequency(values [][]byte) (*pb.FrequencyResult, error) {
+	scale := d.decay.scale(now())
+	errBound := int64(math.Ceil(cmlEpsilon(d.Info) * d.total * scale))
+	res := &pb.FrequencyResult{
+		Frequencies: make([]*pb.Frequency, len(values), len(values)),
+	}
+	for i, v := range values {
+		count := math.Inf(1)
+		for r, j := range locations(v, len(d.counters[0])) {
+			count = math.Min(count, d.counters[r][j])
+		}
+		res.Frequencies[i] = &pb.Frequency{
+			Value: utils.Stringp(string(v)),
+			Count: utils.Int64p(int64(math.Floor(count*scale + 0.5))),
+			Error: utils.Int64p(errBound),
+		}
+	}
+	return res, nil
+}
+
+// Clone ...
+func (d *DecayingCMLSketch) Clone(info *datamodel.Info) (datamodel.Sketcher, error) {
+	clone := deepCopy(d).(*DecayingCMLSketch)
+	clone.Info = info
+	return clone, nil
+}
@@ -5,7 +5,6 @@ import (
 	"testing"
 
 	"datamodel"
-	pb "datamodel/protobuf"
 	"utils"
 	"testutils"
 )
@@ -36,10 +35,51 @@ func TestAdd(t *testing.T) {
 		t.Error("expected no errors, got", err)
 	}
 
-	if res, err := sketch.Get([][]byte{[]byte("cyclops")}); err != nil {
+	if res, err := sketch.Frequency([][]byte{[]byte("cyclops")}); err != nil {
 		t.Error("expected no errors, got", err)
-	} else if res.(*pb.FrequencyResult).Frequencies[0].GetCount() != 3 {
-		t.Error("expected 'cyclops' count == 3, got", res.(*pb.FrequencyResult).Frequencies[0].GetCount())
+	} else if res.Frequencies[0].GetCount() != 3 {
+		t.Error("expected 'cyclops' count == 3, got", res.Frequencies[0].GetCount())
+	}
+}
+
+func TestFrequencyError(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	info := datamodel.NewEmptyInfo()
+	info.Properties.MaxUniqueItems = utils.Int64p(1000)
+	info.Name = utils.Stringp("marvel")
+	sketch, err := NewCMLSketch(info)
+	if err != nil {
+		t.Error("expected no errors, got", err)
+	}
+
+	var values [][]byte
+	for i := 0; i < 1000; i++ {
+		values = append(values, []byte("avenger"+strconv.Itoa(i%100)))
+	}
+	if _, err := sketch.Add(values); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+
+	if res, err := sketch.Frequency([][]byte{[]byte("avenger1")}); err != nil {
+		t.Error("expected no errors, got", err)
+	} else if e := res.Frequencies[0].GetError(); e != 10 {
+		t.Error("expected error == 10, got", e)
+	}
+
+	// The bound follows the errorRate of the sketch
+	info.Properties.ErrorRate = utils.Float32p(0.05)
+	if sketch, err = NewCMLSketch(info); err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	if _, err := sketch.Add(values); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	if res, err := sketch.Frequency([][]byte{[]byte("avenger1")}); err != nil {
+		t.Error("expected no errors, got", err)
+	} else if e := res.Frequencies[0].GetError(); e != 50 {
+		t.Error("expected error == 50, got", e)
 	}
 }
 
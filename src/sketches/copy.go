@@ -0,0 +1,89 @@
+package sketches
+
+import (
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// deepCopy returns a copy of v that shares no memory with it. The sketch
+// libraries do not have a common serialization API and keep most of their
+// state unexported, so the copy is done reflectively.
+func deepCopy(v interface{}) interface{} {
+	src := reflect.ValueOf(v)
+	dst := reflect.New(src.Type()).Elem()
+	copyValue(dst, src, make(map[uintptr]reflect.Value))
+	return dst.Interface()
+}
+
+var (
+	mutexType   = reflect.TypeOf(sync.Mutex{})
+	rwMutexType = reflect.TypeOf(sync.RWMutex{})
+)
+
+func copyValue(dst, src reflect.Value, seen map[uintptr]reflect.Value) {
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		// Keep pointers that alias each other aliased in the copy
+		if p, ok := seen[src.Pointer()]; ok && p.Type() == src.Type() {
+			dst.Set(p)
+			return
+		}
+		p := reflect.New(src.Type().Elem())
+		seen[src.Pointer()] = p
+		copyValue(p.Elem(), src.Elem(), seen)
+		dst.Set(p)
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		elem := reflect.New(src.Elem().Type()).Elem()
+		copyValue(elem, src.Elem(), seen)
+		dst.Set(elem)
+	case reflect.Struct:
+		// Locks are not state, copies start unlocked
+		if src.Type() == mutexType || src.Type() == rwMutexType {
+			return
+		}
+		if !src.CanAddr() {
+			tmp := reflect.New(src.Type()).Elem()
+			tmp.Set(src)
+			src = tmp
+		}
+		for i := 0; i < src.NumField(); i++ {
+			copyValue(exported(dst.Field(i)), exported(src.Field(i)), seen)
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Cap()))
+		for i := 0; i < src.Len(); i++ {
+			copyValue(dst.Index(i), src.Index(i), seen)
+		}
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			copyValue(dst.Index(i), src.Index(i), seen)
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeMap(src.Type()))
+		for _, key := range src.MapKeys() {
+			val := reflect.New(src.Type().Elem()).Elem()
+			copyValue(val, src.MapIndex(key), seen)
+			dst.SetMapIndex(key, val)
+		}
+	default:
+		dst.Set(src)
+	}
+}
+
+// exported makes an (unexported) struct field readable and writable
+func exported(v reflect.Value) reflect.Value {
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
@@ -0,0 +1,73 @@
+package sketches
+
+import (
+	"testing"
+
+	"datamodel"
+	pb "datamodel/protobuf"
+	"utils"
+	"testutils"
+)
+
+func TestCloneSketches(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	for _, typ := range datamodel.GetTypesPb() {
+		// RANGE sketches only take integers, see TestCloneRange, keyed
+		// sketches need a group key, see TestCloneKeyed
+		if typ == pb.SketchType_RANGE || typ == pb.SketchType_KCARD || typ == pb.SketchType_KRANK {
+			continue
+		}
+		styp := typ
+		info := datamodel.NewEmptyInfo()
+		info.Properties.MaxUniqueItems = utils.Int64p(1024)
+		info.Properties.Size = utils.Int64p(10)
+		info.Name = utils.Stringp("marvel")
+		info.Type = &styp
+
+		sketch, err := CreateSketch(info)
+		if err != nil {
+			t.Fatal("expected no errors, got", err)
+		}
+		if _, err := sketch.Add([][]byte{[]byte("hulk"), []byte("thor")}); err != nil {
+			t.Error("expected no errors, got", err)
+		}
+
+		cloneInfo := info.Copy()
+		cloneInfo.Name = utils.Stringp("avengers")
+		clone, err := sketch.Clone(cloneInfo)
+		if err != nil {
+			t.Fatal("expected no errors, got", err)
+		}
+
+		// Adding to the original must not change the clone
+		if _, err := sketch.Add([][]byte{[]byte("hawk-eye"), []byte("hulk")}); err != nil {
+			t.Error("expected no errors, got", err)
+		}
+
+		values := [][]byte{[]byte("hulk"), []byte("hawk-eye")}
+		switch typ {
+		case pb.SketchType_CARD, pb.SketchType_THETA:
+			res, err := clone.Cardinality(datamodel.DefaultConfidence)
+			if c := res.GetCardinality(); err != nil || c != 2 {
+				t.Error("expected cardinality 2, got", c)
+			}
+		case pb.SketchType_FREQ:
+			res, err := clone.Frequency(values)
+			if c := res.GetFrequencies()[0].GetCount(); err != nil || c != 1 {
+				t.Error("expected frequency of hulk 1, got", c)
+			}
+		case pb.SketchType_MEMB:
+			res, err := clone.Membership(values)
+			if m := res.GetMemberships()[1].GetIsMember(); err != nil || m {
+				t.Error("expected hawk-eye not to be a member, got", m)
+			}
+		case pb.SketchType_RANK:
+			res, err := clone.Rankings(datamodel.RankingsOptions{})
+			if r := res.GetRankings(); err != nil || len(r) != 2 {
+				t.Error("expected 2 rankings, got", len(r))
+			}
+		}
+	}
+}
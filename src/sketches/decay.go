@@ -0,0 +1,60 @@
+package sketches
+
+import (
+	"math"
+	"time"
+
+	"datamodel"
+)
+
+// now is the time decaying sketches are queried at
+var now = time.Now
+
+// maxDecayExponent bounds the weight of new values to 2^maxDecayExponent,
+// well within the range of a float64, before the counters are rescaled
+const maxDecayExponent = 512
+
+// forwardDecay weighs every value by the time it was added, relative to a
+// landmark: w = 2^((t - landmark) / halfLife). The count of a value at time t
+// is the sum of its weights divided by the weight of t, so counts fade with
+// age without touching the counters at query time. The weights only depend
+// on the times recorded with the adds, replaying them gives the same counts.
+type forwardDecay struct {
+	// halfLife in nanoseconds
+	halfLife float64
+	// landmark in Unix nanoseconds, 0 until the first add
+	landmark int64
+}
+
+func newForwardDecay(info *datamodel.Info) forwardDecay {
+	halfLife := time.Duration(info.Properties.GetHalfLife()) * time.Second
+	return forwardDecay{halfLife: float64(halfLife)}
+}
+
+func (d *forwardDecay) exponent(at time.Time) float64 {
+	return float64(at.UnixNano()-d.landmark) / d.halfLife
+}
+
+// weight returns the weight of values added at the given time. Once weights
+// grow too large the landmark moves to that time, rescale is then the factor
+// the existing counters must be multiplied with, it is 1 otherwise.
+func (d *forwardDecay) weight(at time.Time) (weight, rescale float64) {
+	if d.landmark == 0 {
+		d.landmark = at.UnixNano()
+		return 1, 1
+	}
+	exp := d.exponent(at)
+	if exp > maxDecayExponent {
+		d.landmark = at.UnixNano()
+		return 1, math.Exp2(-exp)
+	}
+	return math.Exp2(exp), 1
+}
+
+// scale returns the factor turning counters into counts at the given time
+func (d *forwardDecay) scale(at time.Time) float64 {
+	if d.landmark == 0 {
+		return 1
+	}
+	return math.Exp2(-d.exponent(at))
+}
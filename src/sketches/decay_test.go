@@ -0,0 +1,185 @@
+package sketches
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"datamodel"
+	"testutils"
+	"utils"
+)
+
+func decayingInfo(halfLife time.Duration) *datamodel.Info {
+	info := datamodel.NewEmptyInfo()
+	info.Properties.Size = utils.Int64p(10)
+	info.Properties.MaxUniqueItems = utils.Int64p(1000)
+	info.Properties.HalfLife = utils.Int64p(int64(halfLife / time.Second))
+	info.Name = utils.Stringp("marvel")
+	return info
+}
+
+func repeat(value string, n int) [][]byte {
+	values := make([][]byte, n)
+	for i := range values {
+		values[i] = []byte(value)
+	}
+	return values
+}
+
+func TestDecayingTopK(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	start := time.Unix(1450000000, 0)
+	defer func() { now = time.Now }()
+
+	sketch, err := NewDecayingTopKSketch(decayingInfo(time.Hour))
+	if err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	// hulk was big a day ago, thor is trending now
+	if _, err := sketch.AddAt(repeat("hulk", 1000), start); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	if _, err := sketch.AddAt(repeat("thor", 16), start.Add(24*time.Hour)); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+
+	now = func() time.Time { return start.Add(24 * time.Hour) }
+	res, err := sketch.Rankings(datamodel.RankingsOptions{})
+	if err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	if r := res.GetRankings(); len(r) != 2 || r[0].GetValue() != "thor" || r[0].GetCount() != 16 {
+		t.Error("expected thor to lead with 16, got", r)
+	} else if r[1].GetCount() != 0 {
+		t.Error("expected hulk to have decayed to 0, got", r[1].GetCount())
+	}
+
+	// An hour later every count weighs half
+	now = func() time.Time { return start.Add(25 * time.Hour) }
+	res, err = sketch.Rankings(datamodel.RankingsOptions{MinCount: 1})
+	if err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	if r := res.GetRankings(); len(r) != 1 || r[0].GetCount() != 8 {
+		t.Error("expected thor == 8, got", r)
+	}
+}
+
+func TestDecayingCML(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	start := time.Unix(1450000000, 0)
+	defer func() { now = time.Now }()
+
+	sketch, err := NewDecayingCMLSketch(decayingInfo(time.Minute))
+	if err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	if _, err := sketch.AddAt(repeat("hulk", 64), start); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	if _, err := sketch.AddAt(repeat("hulk", 4), start.Add(2*time.Minute)); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+
+	now = func() time.Time { return start.Add(2 * time.Minute) }
+	values := [][]byte{[]byte("hulk"), []byte("thor")}
+	if res, err := sketch.Frequency(values); err != nil {
+		t.Error("expected no errors, got", err)
+	} else if c := res.Frequencies[0].GetCount(); c != 20 {
+		t.Error("expected hulk == 64 / 4 + 4 == 20, got", c)
+	} else if c := res.Frequencies[1].GetCount(); c != 0 {
+		t.Error("expected thor == 0, got", c)
+	}
+}
+
+func TestDecayingCMLErrorRate(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	info := decayingInfo(time.Minute)
+	sketch, err := NewDecayingCMLSketch(info)
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	if w := len(sketch.counters[0]); w != 272 {
+		t.Error("expected 272 counters per row, got", w)
+	}
+	info.Properties.ErrorRate = utils.Float32p(0.001)
+	if sketch, err = NewDecayingCMLSketch(info); err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	if w := len(sketch.counters[0]); w != 2719 {
+		t.Error("expected 2719 counters per row, got", w)
+	}
+	if _, err := sketch.Add(repeat("hulk", 4000)); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	if res, err := sketch.Frequency([][]byte{[]byte("hulk")}); err != nil {
+		t.Error("expected no errors, got", err)
+	} else if e := res.Frequencies[0].GetError(); e != 4 {
+		t.Error("expected error == 0.001 * 4000 == 4, got", e)
+	}
+}
+
+func TestDecayRescale(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	start := time.Unix(1450000000, 0)
+	defer func() { now = time.Now }()
+
+	sketch, err := NewDecayingCMLSketch(decayingInfo(time.Second))
+	if err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	// Far more half-lives than a float64 weight can hold
+	var at time.Time
+	for i := 0; i < 5; i++ {
+		at = start.Add(time.Duration(i*400) * time.Second)
+		if _, err := sketch.AddAt(repeat("hulk", 2), at); err != nil {
+			t.Error("expected no errors, got", err)
+		}
+	}
+
+	now = func() time.Time { return at.Add(time.Second) }
+	if res, err := sketch.Frequency([][]byte{[]byte("hulk")}); err != nil {
+		t.Error("expected no errors, got", err)
+	} else if c := res.Frequencies[0].GetCount(); c != 1 {
+		t.Error("expected hulk == 1, got", c)
+	}
+}
+
+func TestDecayingClone(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	sketch, err := NewDecayingTopKSketch(decayingInfo(time.Hour))
+	if err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	for i := 0; i < 30; i++ {
+		if _, err := sketch.Add(repeat("avenger"+strconv.Itoa(i), i+1)); err != nil {
+			t.Error("expected no errors, got", err)
+		}
+	}
+	clone, err := sketch.Clone(decayingInfo(time.Hour))
+	if err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	// Adding to the clone must not change the original
+	if _, err := clone.Add(repeat("hulk", 100)); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	res, err := sketch.Rankings(datamodel.RankingsOptions{})
+	if err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	if r := res.GetRankings(); len(r) != 5 || r[0].GetValue() != "avenger29" {
+		t.Error("expected avenger29 to lead, got", r)
+	}
+}
@@ -1,6 +1,10 @@
 package sketches
 
 import (
+	"fmt"
+	"math"
+	"sync"
+
 	"github.com/retailnext/hllpp"
 
 	"datamodel"
@@ -8,20 +12,41 @@ import (
 	"utils"
 )
 
+func init() {
+	datamodel.Register(&datamodel.SketchType{
+		Type: pb.SketchType_CARD,
+		Name: datamodel.HLLPP,
+		New: func(info *datamodel.Info) (datamodel.Sketcher, error) {
+			return NewHLLPPSketch(info)
+		},
+		Capabilities: datamodel.CardinalityQuery,
+	})
+}
+
+// hllPrecision is the precision (p) of the HLL++ sketches, they use 2^p
+// registers
+const hllPrecision = 14
+
 // HLLPPSketch is the toplevel sketch to control the HLL implementation
 type HLLPPSketch struct {
 	*datamodel.Info
 	impl *hllpp.HLLPP
+	// flush serializes the reads of impl: counting, merging and marshalling
+	// flush the values it buffers, so concurrent queries would race
+	flush sync.Mutex
 }
 
 // NewHLLPPSketch ...
 func NewHLLPPSketch(info *datamodel.Info) (*HLLPPSketch, error) {
-	d := HLLPPSketch{info, hllpp.New()}
-	return &d, nil
+	impl, err := hllpp.NewWithConfig(hllpp.Config{Precision: hllPrecision})
+	if err != nil {
+		return nil, err
+	}
+	return &HLLPPSketch{Info: info, impl: impl}, nil
 }
 
 // Add ...
-func (d *HLLPPSketch) Add(values [][]byte) (bool, error) {
+func (d *HLLPPSketch) Add(values [][]byte) (int, error) {
 	dict := make(map[string]uint)
 	for _, v := range values {
 		dict[string(v)]++
@@ -29,12 +54,64 @@ func (d *HLLPPSketch) Add(values [][]byte) (bool, error) {
 	for v := range dict {
 		d.impl.Add([]byte(v))
 	}
-	return true, nil
+	return len(values), nil
 }
 
-// Get ...
-func (d *HLLPPSketch) Get(interface{}) (interface{}, error) {
+// Cardinality returns the estimated cardinality and its bounds at the given
+// confidence. The bounds follow from the standard error of HLL, 1.04/sqrt(m)
+// with m registers, and are conservative for small cardinalities where HLL++
+// is more precise.
+func (d *HLLPPSketch) Cardinality(confidence float64) (*pb.CardinalityResult, error) {
+	d.flush.Lock()
+	count := d.impl.Count()
+	d.flush.Unlock()
+	stdErr := 1.04 / math.Sqrt(float64(uint64(1)<<hllPrecision))
+	margin := float64(count) * stdErr * zScore(confidence)
 	return &pb.CardinalityResult{
-		Cardinality: utils.Int64p(int64(d.impl.Count())),
+		Cardinality: utils.Int64p(int64(count)),
+		Lower:       utils.Int64p(int64(math.Max(0, math.Floor(float64(count)-margin)))),
+		Upper:       utils.Int64p(int64(math.Ceil(float64(count) + margin))),
+		Confidence:  utils.Float32p(float32(confidence)),
 	}, nil
 }
+
+// zScore returns the two-sided z-score of a confidence for a normal
+// distribution
+func zScore(confidence float64) float64 {
+	return math.Sqrt2 * math.Erfinv(confidence)
+}
+
+// Merge adds the values of another HLLPP sketch
+func (d *HLLPPSketch) Merge(other datamodel.Sketcher) error {
+	o, ok := other.(*HLLPPSketch)
+	if !ok {
+		return fmt.Errorf("Can not merge %T into %T", other, d)
+	}
+	o.flush.Lock()
+	defer o.flush.Unlock()
+	return d.impl.Merge(o.impl)
+}
+
+// Marshal ...
+func (d *HLLPPSketch) Marshal() ([]byte, error) {
+	d.flush.Lock()
+	defer d.flush.Unlock()
+	return d.impl.Marshal(), nil
+}
+
+// Unmarshal ...
+func (d *HLLPPSketch) Unmarshal(data []byte) error {
+	impl, err := hllpp.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	d.impl = impl
+	return nil
+}
+
+// Clone ...
+func (d *HLLPPSketch) Clone(info *datamodel.Info) (datamodel.Sketcher, error) {
+	d.flush.Lock()
+	defer d.flush.Unlock()
+	return &HLLPPSketch{Info: info, impl: deepCopy(d.impl).(*hllpp.HLLPP)}, nil
+}
@@ -5,7 +5,6 @@ import (
 	"testing"
 
 	"datamodel"
-	pb "datamodel/protobuf"
 	"utils"
 	"testutils"
 )
@@ -38,10 +37,10 @@ func TestAddHLLPP(t *testing.T) {
 
 	const expectedCardinality int64 = 4
 
-	if res, err := sketch.Get(values); err != nil {
+	if res, err := sketch.Cardinality(datamodel.DefaultConfidence); err != nil {
 		t.Error("expected no errors, got", err)
 	} else {
-		tmp := res.(*pb.CardinalityResult)
+		tmp := res
 		mres := tmp.GetCardinality()
 		if mres != int64(expectedCardinality) {
 			t.Error("expected cardinality == "+strconv.FormatInt(expectedCardinality, 10)+", got", mres)
@@ -49,6 +48,48 @@ func TestAddHLLPP(t *testing.T) {
 	}
 }
 
+func TestCardinalityBounds(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	info := datamodel.NewEmptyInfo()
+	info.Name = utils.Stringp("marvel")
+	sketch, err := NewHLLPPSketch(info)
+	if err != nil {
+		t.Error("expected no errors, got", err)
+	}
+
+	var values [][]byte
+	for i := 0; i < 100000; i++ {
+		values = append(values, []byte("avenger"+strconv.Itoa(i)))
+	}
+	if _, err := sketch.Add(values); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+
+	narrow, err := sketch.Cardinality(0.5)
+	if err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	wide, err := sketch.Cardinality(0.99)
+	if err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	if c := wide.GetCardinality(); wide.GetLower() > c || wide.GetUpper() < c {
+		t.Errorf("expected %d in [%d, %d]", c, wide.GetLower(), wide.GetUpper())
+	}
+	if wide.GetLower() > 100000 || wide.GetUpper() < 100000 {
+		t.Errorf("expected 100000 in [%d, %d]", wide.GetLower(), wide.GetUpper())
+	}
+	if narrow.GetLower() <= wide.GetLower() || narrow.GetUpper() >= wide.GetUpper() {
+		t.Errorf("expected [%d, %d] within [%d, %d]", narrow.GetLower(), narrow.GetUpper(),
+			wide.GetLower(), wide.GetUpper())
+	}
+	if wide.GetConfidence() != 0.99 {
+		t.Error("expected confidence 0.99, got", wide.GetConfidence())
+	}
+}
+
 func BenchmarkHLLPP(b *testing.B) {
 	values := make([][]byte, 10)
 	for i := 0; i < 1024; i++ {
@@ -71,3 +112,30 @@ func BenchmarkHLLPP(b *testing.B) {
 		}
 	}
 }
+
+func TestMergeHLLPP(t *testing.T) {
+	info := datamodel.NewEmptyInfo()
+	info.Name = utils.Stringp("marvel")
+	marvel, _ := NewHLLPPSketch(info)
+	dc, _ := NewHLLPPSketch(info)
+
+	if _, err := marvel.Add([][]byte{[]byte("hulk"), []byte("thor")}); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	if _, err := dc.Add([][]byte{[]byte("batman"), []byte("hulk")}); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	if err := marvel.Merge(dc); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	if res, err := marvel.Cardinality(datamodel.DefaultConfidence); err != nil {
+		t.Error("expected no errors, got", err)
+	} else if res.GetCardinality() != 3 {
+		t.Error("expected cardinality == 3, got", res.GetCardinality())
+	}
+
+	bloom, _ := NewBloomSketch(info)
+	if err := marvel.Merge(bloom); err == nil {
+		t.Error("expected error merging a bloom sketch, got", err)
+	}
+}
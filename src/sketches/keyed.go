@@ -0,0 +1,255 @@
+package sketches
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"datamodel"
+	pb "datamodel/protobuf"
+
+	"utils"
+)
+
+func init() {
+	datamodel.Register(&datamodel.SketchType{
+		Type: pb.SketchType_KCARD,
+		Name: datamodel.KeyedCard,
+		New: func(info *datamodel.Info) (datamodel.Sketcher, error) {
+			return NewKeyedSketch(info, pb.SketchType_CARD)
+		},
+		Capabilities: datamodel.GroupQuery,
+		// Values need a group key, domain values have none
+		Optional: true,
+	})
+	datamodel.Register(&datamodel.SketchType{
+		Type: pb.SketchType_KRANK,
+		Name: datamodel.KeyedRank,
+		New: func(info *datamodel.Info) (datamodel.Sketcher, error) {
+			return NewKeyedSketch(info, pb.SketchType_RANK)
+		},
+		Defaults:     &pb.SketchProperties{Size: utils.Int64p(datamodel.DefaultSize)},
+		Capabilities: datamodel.GroupQuery,
+		Optional:     true,
+	})
+}
+
+// KeyedSketch keeps a sketch of one type per group key, e.g. the unique
+// users of every page. The sketch of a group is created with the first
+// value added under its key, all of them share the properties of the
+// family.
+type KeyedSketch struct {
+	*datamodel.Info
+	// group describes the sketches of the groups
+	group  *datamodel.Info
+	groups map[string]datamodel.Sketcher
+}
+
+// NewKeyedSketch creates an empty family of sketches of type typ
+func NewKeyedSketch(info *datamodel.Info, typ pb.SketchType) (*KeyedSketch, error) {
+	group := &datamodel.Info{Sketch: &pb.Sketch{
+		Name:       info.Name,
+		Type:       &typ,
+		Properties: info.Properties,
+	}}
+	// Check the properties now rather than with the first value of a group
+	if _, err := newSketch(group); err != nil {
+		return nil, err
+	}
+	return &KeyedSketch{
+		Info:   info,
+		group:  group,
+		groups: make(map[string]datamodel.Sketcher),
+	}, nil
+}
+
+// Add rejects values without a group key
+func (d *KeyedSketch) Add(values [][]byte) (int, error) {
+	if len(values) == 0 {
+		return 0, nil
+	}
+	return 0, fmt.Errorf("Values added to a sketch of type %s need a group key", d.GetType())
+}
+
+// AddKeyed adds every value to the sketch of its group, creating the sketch
+// if the group is new
+func (d *KeyedSketch) AddKeyed(keys, values [][]byte) (int, error) {
+	if len(keys) != len(values) {
+		return 0, fmt.Errorf("Expected a group key per value, got %d keys for %d values", len(keys), len(values))
+	}
+	byKey := make(map[string][][]byte)
+	for i, key := range keys {
+		byKey[string(key)] = append(byKey[string(key)], values[i])
+	}
+	applied := 0
+	for key, vals := range byKey {
+		sketch, ok := d.groups[key]
+		if !ok {
+			var err error
+			if sketch, err = newSketch(d.group); err != nil {
+				return applied, err
+			}
+			d.groups[key] = sketch
+		}
+		n, err := sketch.Add(vals)
+		applied += n
+		if err != nil {
+			return applied, err
+		}
+	}
+	return applied, nil
+}
+
+// result answers the query of the type of the groups for a single group
+func (d *KeyedSketch) result(key string, sketch datamodel.Sketcher, opts datamodel.GroupOptions) (*pb.GroupResult, error) {
+	res := &pb.GroupResult{Key: utils.Stringp(key)}
+	var err error
+	switch s := sketch.(type) {
+	case datamodel.Cardinality:
+		res.Cardinality, err = s.Cardinality(opts.Confidence)
+	case datamodel.Ranking:
+		res.Rankings, err = s.Rankings(datamodel.RankingsOptions{})
+	}
+	return res, err
+}
+
+// Groups ...
+func (d *KeyedSketch) Groups(keys []string, opts datamodel.GroupOptions) ([]*pb.GroupResult, error) {
+	results := make([]*pb.GroupResult, len(keys))
+	for i, key := range keys {
+		sketch, ok := d.groups[key]
+		if !ok {
+			var err error
+			if sketch, err = newSketch(d.group); err != nil {
+				return nil, err
+			}
+		}
+		res, err := d.result(key, sketch, opts)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = res
+	}
+	return results, nil
+}
+
+// groupCardinality is the cardinality of a group, ranked by TopGroups
+type groupCardinality struct {
+	key         string
+	cardinality int64
+}
+
+// byCardinality orders groups from the smallest cardinality up, ties by
+// reverse key so that the smallest key ranks first
+type byCardinality []groupCardinality
+
+func (h byCardinality) Len() int { return len(h) }
+func (h byCardinality) Less(i, j int) bool {
+	if h[i].cardinality != h[j].cardinality {
+		return h[i].cardinality < h[j].cardinality
+	}
+	return h[i].key > h[j].key
+}
+func (h byCardinality) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *byCardinality) Push(x interface{}) { *h = append(*h, x.(groupCardinality)) }
+func (h *byCardinality) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// TopGroups keeps the opts.Limit groups with the largest cardinality in a
+// heap while counting every group. Only families of sketches that count
+// distinct values rank their groups.
+func (d *KeyedSketch) TopGroups(opts datamodel.GroupOptions) ([]*pb.GroupResult, error) {
+	var top byCardinality
+	for key, sketch := range d.groups {
+		s, ok := sketch.(datamodel.Cardinality)
+		if !ok {
+			return nil, &UnsupportedError{d.GetType(), "top groups"}
+		}
+		res, err := s.Cardinality(opts.Confidence)
+		if err != nil {
+			return nil, err
+		}
+		heap.Push(&top, groupCardinality{key, res.GetCardinality()})
+		if len(top) > opts.Limit {
+			heap.Pop(&top)
+		}
+	}
+	sort.Sort(sort.Reverse(top))
+	results := make([]*pb.GroupResult, len(top))
+	for i, g := range top {
+		res, err := d.result(g.key, d.groups[g.key], opts)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = res
+	}
+	return results, nil
+}
+
+// GroupCount ...
+func (d *KeyedSketch) GroupCount() int {
+	return len(d.groups)
+}
+
+// Marshal writes every group as the length of its key, the key, the length
+// of its sketch and the sketch
+func (d *KeyedSketch) Marshal() ([]byte, error) {
+	var data []byte
+	buf := make([]byte, binary.MaxVarintLen64)
+	for key, sketch := range d.groups {
+		s, ok := sketch.(datamodel.Serializable)
+		if !ok {
+			return nil, fmt.Errorf("Sketch of type %s can not be serialized", d.group.GetType())
+		}
+		b, err := s.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range [][]byte{[]byte(key), b} {
+			n := binary.PutUvarint(buf, uint64(len(field)))
+			data = append(append(data, buf[:n]...), field...)
+		}
+	}
+	return data, nil
+}
+
+// Unmarshal ...
+func (d *KeyedSketch) Unmarshal(data []byte) error {
+	groups := make(map[string]datamodel.Sketcher)
+	for len(data) > 0 {
+		var fields [2][]byte
+		for i := range fields {
+			size, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < size {
+				return fmt.Errorf("Invalid %s sketch, group %d is truncated", d.GetType(), len(groups))
+			}
+			fields[i], data = data[n:n+int(size)], data[n+int(size):]
+		}
+		sketch, err := newSketch(d.group)
+		if err != nil {
+			return err
+		}
+		s, ok := sketch.(datamodel.Serializable)
+		if !ok {
+			return fmt.Errorf("Sketch of type %s can not be serialized", d.group.GetType())
+		}
+		if err := s.Unmarshal(fields[1]); err != nil {
+			return err
+		}
+		groups[string(fields[0])] = sketch
+	}
+	d.groups = groups
+	return nil
+}
+
+// Clone ...
+func (d *KeyedSketch) Clone(info *datamodel.Info) (datamodel.Sketcher, error) {
+	clone := deepCopy(d).(*KeyedSketch)
+	clone.Info = info
+	return clone, nil
+}
@@ -0,0 +1,150 @@
+package sketches
+
+import (
+	"strconv"
+	"testing"
+
+	"datamodel"
+	pb "datamodel/protobuf"
+	"testutils"
+	"utils"
+)
+
+func keyedSketch(t *testing.T, typ pb.SketchType) *KeyedSketch {
+	info := datamodel.NewEmptyInfo()
+	info.Properties.Size = utils.Int64p(10)
+	info.Name = utils.Stringp("pages")
+	info.Type = &typ
+	sub := pb.SketchType_CARD
+	if typ == pb.SketchType_KRANK {
+		sub = pb.SketchType_RANK
+	}
+	sketch, err := NewKeyedSketch(info, sub)
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	return sketch
+}
+
+// visits adds n users to the group of page
+func visits(t *testing.T, sketch *KeyedSketch, page string, n int) {
+	var keys, values [][]byte
+	for i := 0; i < n; i++ {
+		keys = append(keys, []byte(page))
+		values = append(values, []byte("user"+strconv.Itoa(i)))
+	}
+	if applied, err := sketch.AddKeyed(keys, values); err != nil || applied != n {
+		t.Error("expected", n, "values applied, got", applied, err)
+	}
+}
+
+func TestKeyedCardinality(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	sketch := keyedSketch(t, pb.SketchType_KCARD)
+	visits(t, sketch, "/home", 30)
+	visits(t, sketch, "/pricing", 20)
+	visits(t, sketch, "/blog", 10)
+	visits(t, sketch, "/home", 30)
+	if sketch.GroupCount() != 3 {
+		t.Error("expected 3 groups, got", sketch.GroupCount())
+	}
+
+	opts := datamodel.GroupOptions{Limit: 2, Confidence: datamodel.DefaultConfidence}
+	res, err := sketch.Groups([]string{"/pricing", "/careers"}, opts)
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	if len(res) != 2 || res[0].GetCardinality().GetCardinality() != 20 || res[1].GetCardinality().GetCardinality() != 0 {
+		t.Error("expected 20 visitors of /pricing and none of /careers, got", res)
+	}
+
+	res, err = sketch.TopGroups(opts)
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	if len(res) != 2 || res[0].GetKey() != "/home" || res[1].GetKey() != "/pricing" ||
+		res[0].GetCardinality().GetCardinality() != 30 {
+		t.Error("expected /home and /pricing, got", res)
+	}
+
+	if _, err := sketch.Add([][]byte{[]byte("user1")}); err == nil {
+		t.Error("expected error adding a value without a group key")
+	}
+	if _, err := sketch.AddKeyed([][]byte{[]byte("/home")}, nil); err == nil {
+		t.Error("expected error adding more keys than values")
+	}
+}
+
+func TestKeyedRankings(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	sketch := keyedSketch(t, pb.SketchType_KRANK)
+	keys := [][]byte{[]byte("/home"), []byte("/home"), []byte("/home"), []byte("/blog")}
+	values := [][]byte{[]byte("chrome"), []byte("firefox"), []byte("chrome"), []byte("safari")}
+	if _, err := sketch.AddKeyed(keys, values); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	res, err := sketch.Groups([]string{"/home"}, datamodel.GroupOptions{})
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	if r := res[0].GetRankings().GetRankings(); len(r) != 2 || r[0].GetValue() != "chrome" || r[0].GetCount() != 2 {
+		t.Error("expected chrome to rank first on /home, got", r)
+	}
+	if _, err := sketch.TopGroups(datamodel.GroupOptions{Limit: 1}); err == nil {
+		t.Error("expected error ranking KRANK groups by cardinality")
+	} else if _, ok := err.(*UnsupportedError); !ok {
+		t.Errorf("expected UnsupportedError, got %T", err)
+	}
+}
+
+func TestKeyedMarshal(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	sketch := keyedSketch(t, pb.SketchType_KCARD)
+	visits(t, sketch, "/home", 30)
+	visits(t, sketch, "/pricing", 20)
+	data, err := sketch.Marshal()
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	restored := keyedSketch(t, pb.SketchType_KCARD)
+	if err := restored.Unmarshal(data); err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	res, err := restored.Groups([]string{"/home", "/pricing"}, datamodel.GroupOptions{Confidence: datamodel.DefaultConfidence})
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	if res[0].GetCardinality().GetCardinality() != 30 || res[1].GetCardinality().GetCardinality() != 20 {
+		t.Error("expected 30 and 20 visitors, got", res)
+	}
+	if err := restored.Unmarshal(data[:len(data)-1]); err == nil {
+		t.Error("expected an error unmarshaling a truncated sketch")
+	}
+}
+
+func TestCloneKeyed(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	sketch := keyedSketch(t, pb.SketchType_KCARD)
+	visits(t, sketch, "/home", 30)
+	cloneInfo := sketch.Info.Copy()
+	cloneInfo.Name = utils.Stringp("pages-backup")
+	clone, err := sketch.Clone(cloneInfo)
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	visits(t, sketch, "/home", 40)
+	visits(t, sketch, "/blog", 5)
+	keyed := clone.(*KeyedSketch)
+	res, _ := keyed.Groups([]string{"/home"}, datamodel.GroupOptions{Confidence: datamodel.DefaultConfidence})
+	if keyed.GroupCount() != 1 || res[0].GetCardinality().GetCardinality() != 30 {
+		t.Error("expected the clone to keep 30 visitors of /home only, got", res)
+	}
+}
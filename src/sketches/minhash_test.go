@@ -0,0 +1,108 @@
+package sketches
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"datamodel"
+	"testutils"
+	"utils"
+)
+
+func minHashSketch(t *testing.T, size int64, from, to int) *MinHashSketch {
+	info := datamodel.NewEmptyInfo()
+	info.Properties.Size = utils.Int64p(size)
+	info.Name = utils.Stringp("visitors")
+	sketch, err := NewMinHashSketch(info)
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	var values [][]byte
+	for i := from; i < to; i++ {
+		values = append(values, []byte("user"+strconv.Itoa(i)))
+	}
+	if _, err := sketch.Add(values); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	return sketch
+}
+
+func TestMinHashSimilarity(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	a := minHashSketch(t, 256, 0, 1000)
+	for _, c := range []struct {
+		other    *MinHashSketch
+		expected float64
+	}{
+		{minHashSketch(t, 256, 0, 1000), 1},
+		{minHashSketch(t, 256, 500, 1500), 1.0 / 3},
+		{minHashSketch(t, 256, 1000, 2000), 0},
+	} {
+		res, err := a.Similarity(c.other)
+		if err != nil {
+			t.Fatal("expected no errors, got", err)
+		}
+		if s := float64(res.GetSimilarity()); math.Abs(s-c.expected) > 4*float64(res.GetError())+0.01 {
+			t.Errorf("expected similarity %v, got %v ± %v", c.expected, s, res.GetError())
+		}
+	}
+
+	empty := minHashSketch(t, 256, 0, 0)
+	if res, err := a.Similarity(empty); err != nil || res.GetSimilarity() != 0 {
+		t.Error("expected an empty sketch to be similar to nothing, got", res, err)
+	}
+	if _, err := a.Similarity(minHashSketch(t, 128, 0, 1000)); err == nil {
+		t.Error("expected sketches of different sizes not to compare")
+	}
+}
+
+func TestMinHashBands(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	a := minHashSketch(t, 128, 0, 1000)
+	if shares, err := a.SharesBand(minHashSketch(t, 128, 100, 1000)); err != nil || !shares {
+		t.Error("expected very similar sketches to share a band, got", shares, err)
+	}
+	if shares, err := a.SharesBand(minHashSketch(t, 128, 5000, 6000)); err != nil || shares {
+		t.Error("expected disjoint sketches to share no band, got", shares, err)
+	}
+	if bands := a.Bands(); len(bands) != 128/minHashBandRows {
+		t.Error("expected", 128/minHashBandRows, "bands, got", len(bands))
+	}
+	if bands := minHashSketch(t, 128, 0, 0).Bands(); bands != nil {
+		t.Error("expected an empty sketch to have no bands, got", bands)
+	}
+}
+
+func TestMinHashMergeAndMarshal(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	merged := minHashSketch(t, 64, 0, 500)
+	if err := merged.Merge(minHashSketch(t, 64, 500, 1000)); err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	whole := minHashSketch(t, 64, 0, 1000)
+	if res, _ := merged.Similarity(whole); res.GetSimilarity() != 1 {
+		t.Error("expected a merge to match the sketch of all values, got", res.GetSimilarity())
+	}
+
+	data, err := merged.Marshal()
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	restored := minHashSketch(t, 64, 0, 0)
+	if err := restored.Unmarshal(data); err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	if shares, _ := restored.SharesBand(whole); !shares {
+		t.Error("expected an unmarshaled sketch to keep its bands")
+	}
+	if err := restored.Unmarshal(data[:7]); err == nil {
+		t.Error("expected an error unmarshaling a truncated sketch")
+	}
+}
@@ -2,11 +2,14 @@ package sketches
 
 import (
 	"fmt"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/njpatel/loggo"
 
 	"datamodel"
+	pb "datamodel/protobuf"
 )
 
 var logger = loggo.GetLogger("sketches")
@@ -19,49 +22,281 @@ type SketchProxy struct {
 }
 
 // Add ...
-func (sp *SketchProxy) Add(values [][]byte) (bool, error) {
+func (sp *SketchProxy) Add(values [][]byte) (int, error) {
 	sp.lock.Lock()
 	defer sp.lock.Unlock()
 	return sp.sketch.Add(values)
 }
 
-// Get ...
-func (sp *SketchProxy) Get(data interface{}) (interface{}, error) {
-	switch datamodel.GetTypeString(sp.GetType()) {
-	case datamodel.HLLPP:
-		return sp.sketch.Get(nil)
-	case datamodel.CML:
-		return sp.sketch.Get(data)
-	case datamodel.TopK:
-		return sp.sketch.Get(nil)
-	case datamodel.Bloom:
-		return sp.sketch.Get(data)
-	default:
-		return nil, fmt.Errorf("Invalid sketch type: %s", sp.GetType())
+// AddAt adds values seen at the given time, sketches that do not depend on
+// time ignore it
+func (sp *SketchProxy) AddAt(values [][]byte, at time.Time) (int, error) {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+	if s, ok := sp.sketch.(datamodel.TimedAdder); ok {
+		return s.AddAt(values, at)
 	}
+	return sp.sketch.Add(values)
 }
 
-// CreateSketch ...
-func CreateSketch(info *datamodel.Info) (*SketchProxy, error) {
+// AddKeyed adds values[i] to the group of keys[i] of a keyed sketch
+func (sp *SketchProxy) AddKeyed(keys, values [][]byte) (int, error) {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+	s, ok := sp.sketch.(datamodel.Grouped)
+	if !ok {
+		return 0, &UnsupportedError{sp.GetType(), "keyed add"}
+	}
+	return s.AddKeyed(keys, values)
+}
+
+// UnsupportedError is returned when a sketch is queried for something its
+// type can not answer, e.g. the frequency of a value from a CARD sketch
+type UnsupportedError struct {
+	Type  pb.SketchType
+	Query string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("Sketch of type %s does not support %s queries", e.Type, e.Query)
+}
+
+// PropertyError is returned when a sketch can not be created with the
+// properties it is given, e.g. a negative size
+type PropertyError struct {
+	Message string
+}
+
+func (e *PropertyError) Error() string {
+	return e.Message
+}
+
+func errProperty(format string, args ...interface{}) error {
+	return &PropertyError{fmt.Sprintf(format, args...)}
+}
+
+// AddIfAbsent tests and adds values under the write lock, so no other add or
+// query can happen in between
+func (sp *SketchProxy) AddIfAbsent(values [][]byte) ([]bool, error) {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+	s, ok := sp.sketch.(datamodel.AddIfAbsenter)
+	if !ok {
+		return nil, &UnsupportedError{sp.GetType(), "add if absent"}
+	}
+	return s.AddIfAbsent(values)
+}
+
+// Cardinality ...
+func (sp *SketchProxy) Cardinality(confidence float64) (*pb.CardinalityResult, error) {
+	sp.lock.RLock()
+	defer sp.lock.RUnlock()
+	s, ok := sp.sketch.(datamodel.Cardinality)
+	if !ok {
+		return nil, &UnsupportedError{sp.GetType(), "cardinality"}
+	}
+	return s.Cardinality(confidence)
+}
+
+// Frequency ...
+func (sp *SketchProxy) Frequency(values [][]byte) (*pb.FrequencyResult, error) {
+	sp.lock.RLock()
+	defer sp.lock.RUnlock()
+	s, ok := sp.sketch.(datamodel.Frequency)
+	if !ok {
+		return nil, &UnsupportedError{sp.GetType(), "frequency"}
+	}
+	return s.Frequency(values)
+}
+
+// Membership ...
+func (sp *SketchProxy) Membership(values [][]byte) (*pb.MembershipResult, error) {
+	sp.lock.RLock()
+	defer sp.lock.RUnlock()
+	s, ok := sp.sketch.(datamodel.Membership)
+	if !ok {
+		return nil, &UnsupportedError{sp.GetType(), "membership"}
+	}
+	return s.Membership(values)
+}
+
+// Rankings ...
+func (sp *SketchProxy) Rankings(opts datamodel.RankingsOptions) (*pb.RankingsResult, error) {
+	sp.lock.RLock()
+	defer sp.lock.RUnlock()
+	s, ok := sp.sketch.(datamodel.Ranking)
+	if !ok {
+		return nil, &UnsupportedError{sp.GetType(), "rankings"}
+	}
+	return s.Rankings(opts)
+}
+
+// RangeCount ...
+func (sp *SketchProxy) RangeCount(lo, hi int64) (*pb.RangeCountResult, error) {
+	sp.lock.RLock()
+	defer sp.lock.RUnlock()
+	s, ok := sp.sketch.(datamodel.RangeCounter)
+	if !ok {
+		return nil, &UnsupportedError{sp.GetType(), "range count"}
+	}
+	return s.RangeCount(lo, hi)
+}
+
+// Groups returns the results of the groups of the given keys, or of the
+// top groups if there are none, together with the number of groups
+func (sp *SketchProxy) Groups(keys []string, opts datamodel.GroupOptions) ([]*pb.GroupResult, int, error) {
+	sp.lock.RLock()
+	defer sp.lock.RUnlock()
+	s, ok := sp.sketch.(datamodel.Grouped)
+	if !ok {
+		return nil, 0, &UnsupportedError{sp.GetType(), "group"}
+	}
+	var results []*pb.GroupResult
 	var err error
-	var sketch datamodel.Sketcher
-	sp := &SketchProxy{info, sketch, sync.RWMutex{}}
-
-	switch datamodel.GetTypeString(info.GetType()) {
-	case datamodel.HLLPP:
-		sp.sketch, err = NewHLLPPSketch(info)
-	case datamodel.CML:
-		sp.sketch, err = NewCMLSketch(info)
-	case datamodel.TopK:
-		sp.sketch, err = NewTopKSketch(info)
-	case datamodel.Bloom:
-		sp.sketch, err = NewBloomSketch(info)
-	default:
-		return nil, fmt.Errorf("Invalid sketch type: %s", sp.GetType())
+	if len(keys) == 0 {
+		results, err = s.TopGroups(opts)
+	} else {
+		results, err = s.Groups(keys, opts)
 	}
+	return results, s.GroupCount(), err
+}
+
+// Sample ...
+func (sp *SketchProxy) Sample() (*pb.SampleResult, error) {
+	sp.lock.RLock()
+	defer sp.lock.RUnlock()
+	s, ok := sp.sketch.(datamodel.Sampler)
+	if !ok {
+		return nil, &UnsupportedError{sp.GetType(), "sample"}
+	}
+	return s.Sample()
+}
+
+// Similarity estimates how similar the values of the sketch are to those of
+// other, which must not change meanwhile, e.g. a clone
+func (sp *SketchProxy) Similarity(other *SketchProxy) (*pb.Similarity, error) {
+	sp.lock.RLock()
+	defer sp.lock.RUnlock()
+	s, ok := sp.sketch.(datamodel.Similar)
+	if !ok {
+		return nil, &UnsupportedError{sp.GetType(), "similarity"}
+	}
+	return s.Similarity(other.sketch)
+}
+
+// SharesBand tells whether the sketch is a likely match for other, which
+// must not change meanwhile, e.g. a clone
+func (sp *SketchProxy) SharesBand(other *SketchProxy) (bool, error) {
+	sp.lock.RLock()
+	defer sp.lock.RUnlock()
+	s, ok := sp.sketch.(datamodel.Similar)
+	if !ok {
+		return false, &UnsupportedError{sp.GetType(), "similarity"}
+	}
+	return s.SharesBand(other.sketch)
+}
+
+// Bands returns the keys of the bands of the signature of a similarity
+// sketch, nil while it is empty
+func (sp *SketchProxy) Bands() ([]uint64, error) {
+	sp.lock.RLock()
+	defer sp.lock.RUnlock()
+	s, ok := sp.sketch.(datamodel.Similar)
+	if !ok {
+		return nil, &UnsupportedError{sp.GetType(), "similarity"}
+	}
+	return s.Bands(), nil
+}
+
+// Clear resets the state of the sketch, keeping its properties
+func (sp *SketchProxy) Clear() error {
+	sketch, err := newSketch(sp.Info)
+	if err != nil {
+		return err
+	}
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+	sp.sketch = sketch
+	return nil
+}
+
+type cloner interface {
+	Clone(*datamodel.Info) (datamodel.Sketcher, error)
+}
+
+// Clone returns a deep copy of the sketch described by info
+func (sp *SketchProxy) Clone(info *datamodel.Info) (*SketchProxy, error) {
+	sp.lock.RLock()
+	defer sp.lock.RUnlock()
+	c, ok := sp.sketch.(cloner)
+	if !ok {
+		return nil, fmt.Errorf("Sketch of type %s can not be cloned", sp.GetType())
+	}
+	sketch, err := c.Clone(info)
+	if err != nil {
+		return nil, err
+	}
+	return &SketchProxy{info, sketch, sync.RWMutex{}}, nil
+}
+
+// Combine returns a new sketch described by info holding the values of the
+// first sketch combined with the others by op: merged with them, reduced to
+// the values they all share, or stripped of the values found in any of
+// them. The sketches must be of the same type, intersections and
+// differences need a type that supports them. The given sketches are left
+// unchanged.
+func Combine(info *datamodel.Info, op pb.SetOperation, sps ...*SketchProxy) (*SketchProxy, error) {
+	if len(sps) == 0 {
+		return nil, fmt.Errorf("No sketches to combine")
+	}
+	combined, err := sps[0].Clone(info)
+	if err != nil {
+		return nil, err
+	}
+	var apply func(datamodel.Sketcher) error
+	if op == pb.SetOperation_UNION {
+		m, ok := combined.sketch.(datamodel.Mergeable)
+		if !ok {
+			return nil, &UnsupportedError{sps[0].GetType(), "merge"}
+		}
+		apply = m.Merge
+	} else {
+		s, ok := combined.sketch.(datamodel.SetOperable)
+		if !ok {
+			return nil, &UnsupportedError{sps[0].GetType(), strings.ToLower(op.String())}
+		}
+		apply = s.Intersect
+		if op == pb.SetOperation_DIFFERENCE {
+			apply = s.Subtract
+		}
+	}
+	for _, sp := range sps[1:] {
+		if sp.GetType() != sps[0].GetType() {
+			return nil, fmt.Errorf("Can not combine sketch of type %s with %s", sp.GetType(), sps[0].GetType())
+		}
+		sp.lock.RLock()
+		err := apply(sp.sketch)
+		sp.lock.RUnlock()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return combined, nil
+}
 
+func newSketch(info *datamodel.Info) (datamodel.Sketcher, error) {
+	t, ok := datamodel.LookupType(info.GetType())
+	if !ok {
+		return nil, errProperty("Invalid sketch type: %s", info.GetType())
+	}
+	return t.New(info)
+}
+
+// CreateSketch ...
+func CreateSketch(info *datamodel.Info) (*SketchProxy, error) {
+	sketch, err := newSketch(info)
 	if err != nil {
 		return nil, err
 	}
-	return sp, nil
+	return &SketchProxy{info, sketch, sync.RWMutex{}}, nil
 }
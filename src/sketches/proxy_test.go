@@ -0,0 +1,191 @@
+package sketches
+
+import (
+	"testing"
+
+	"datamodel"
+	pb "datamodel/protobuf"
+	"testutils"
+	"utils"
+)
+
+func TestUnsupportedQuery(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	typ := pb.SketchType_CARD
+	info := datamodel.NewEmptyInfo()
+	info.Name = utils.Stringp("marvel")
+	info.Type = &typ
+	sketch, err := CreateSketch(info)
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+
+	if _, err := sketch.Cardinality(datamodel.DefaultConfidence); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	if _, err := sketch.Frequency([][]byte{[]byte("hulk")}); err == nil {
+		t.Error("expected error getting the frequency from a CARD sketch, got", err)
+	} else if _, ok := err.(*UnsupportedError); !ok {
+		t.Errorf("expected UnsupportedError, got %T", err)
+	}
+	if _, err := sketch.Membership(nil); err == nil {
+		t.Error("expected error getting memberships from a CARD sketch, got", err)
+	}
+	if _, err := sketch.Rankings(datamodel.RankingsOptions{}); err == nil {
+		t.Error("expected error getting rankings from a CARD sketch, got", err)
+	}
+}
+
+func TestMarshalSketches(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	values := [][]byte{[]byte("hulk"), []byte("thor"), []byte("hulk")}
+	for _, typ := range datamodel.GetTypesPb() {
+		styp := typ
+		info := datamodel.NewEmptyInfo()
+		info.Properties.MaxUniqueItems = utils.Int64p(1024)
+		info.Properties.Size = utils.Int64p(10)
+		info.Name = utils.Stringp("marvel")
+		info.Type = &styp
+
+		sketch, err := newSketch(info)
+		if err != nil {
+			t.Fatal("expected no errors, got", err)
+		}
+		s, ok := sketch.(datamodel.Serializable)
+		// Keyed sketches need a group key, see TestKeyedMarshal
+		if _, grouped := sketch.(datamodel.Grouped); !ok || grouped {
+			continue
+		}
+		if _, err := sketch.Add(values); err != nil {
+			t.Error("expected no errors, got", err)
+		}
+		data, err := s.Marshal()
+		if err != nil {
+			t.Fatal("expected no errors, got", err)
+		}
+
+		restored, err := newSketch(info)
+		if err != nil {
+			t.Fatal("expected no errors, got", err)
+		}
+		if err := restored.(datamodel.Serializable).Unmarshal(data); err != nil {
+			t.Fatal("expected no errors, got", err)
+		}
+		proxy := &SketchProxy{Info: info, sketch: restored}
+		switch typ {
+		case pb.SketchType_CARD, pb.SketchType_THETA:
+			if res, err := proxy.Cardinality(datamodel.DefaultConfidence); err != nil || res.GetCardinality() != 2 {
+				t.Error("expected cardinality 2, got", res, err)
+			}
+		case pb.SketchType_MEMB:
+			if res, err := proxy.Membership(values); err != nil || !res.GetMemberships()[0].GetIsMember() {
+				t.Error("expected hulk to be a member, got", res, err)
+			}
+		case pb.SketchType_RANK:
+			if res, err := proxy.Rankings(datamodel.RankingsOptions{}); err != nil || res.GetRankings()[0].GetValue() != "hulk" {
+				t.Error("expected hulk to rank first, got", res, err)
+			}
+		case pb.SketchType_SAMP:
+			if res, err := proxy.Sample(); err != nil || len(res.GetValues()) != 3 || res.GetCount() != 3 {
+				t.Error("expected all 3 values in the sample, got", res, err)
+			}
+		}
+	}
+}
+
+func TestRegisteredCapabilities(t *testing.T) {
+	for _, st := range datamodel.Types() {
+		typ := st.Type
+		info := datamodel.NewEmptyInfo()
+		info.Properties.MaxUniqueItems = utils.Int64p(1024)
+		info.Properties.Size = utils.Int64p(10)
+		info.Name = utils.Stringp("marvel")
+		info.Type = &typ
+
+		sketch, err := st.New(info)
+		if err != nil {
+			t.Fatal("expected no errors, got", err)
+		}
+		_, card := sketch.(datamodel.Cardinality)
+		_, freq := sketch.(datamodel.Frequency)
+		_, memb := sketch.(datamodel.Membership)
+		_, rank := sketch.(datamodel.Ranking)
+		_, addnew := sketch.(datamodel.AddIfAbsenter)
+		_, rng := sketch.(datamodel.RangeCounter)
+		_, set := sketch.(datamodel.SetOperable)
+		_, similar := sketch.(datamodel.Similar)
+		_, sampler := sketch.(datamodel.Sampler)
+		_, grouped := sketch.(datamodel.Grouped)
+		if card != st.Can(datamodel.CardinalityQuery) || freq != st.Can(datamodel.FrequencyQuery) ||
+			memb != st.Can(datamodel.MembershipQuery) || rank != st.Can(datamodel.RankingsQuery) ||
+			addnew != st.Can(datamodel.AddIfAbsentQuery) || rng != st.Can(datamodel.RangeQuery) ||
+			set != st.Can(datamodel.SetQuery) || similar != st.Can(datamodel.SimilarityQuery) ||
+			sampler != st.Can(datamodel.SampleQuery) || grouped != st.Can(datamodel.GroupQuery) {
+			t.Errorf("expected the capabilities of %s to match its interfaces", st.Name)
+		}
+	}
+	if len(datamodel.Types()) != 10 {
+		t.Error("expected 10 built-in types, got", len(datamodel.Types()))
+	}
+}
+
+func TestCombineProxies(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	typ := pb.SketchType_CARD
+	newCard := func(name string, values ...string) *SketchProxy {
+		info := datamodel.NewEmptyInfo()
+		info.Name = utils.Stringp(name)
+		info.Type = &typ
+		sketch, err := CreateSketch(info)
+		if err != nil {
+			t.Fatal("expected no errors, got", err)
+		}
+		var byts [][]byte
+		for _, v := range values {
+			byts = append(byts, []byte(v))
+		}
+		if _, err := sketch.Add(byts); err != nil {
+			t.Error("expected no errors, got", err)
+		}
+		return sketch
+	}
+	marvel := newCard("marvel", "hulk", "thor")
+	dc := newCard("dc", "batman", "hulk")
+
+	merged, err := Combine(datamodel.NewEmptyInfo(), pb.SetOperation_UNION, marvel, dc)
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	if res, err := merged.Cardinality(datamodel.DefaultConfidence); err != nil || res.GetCardinality() != 3 {
+		t.Error("expected union cardinality == 3, got", res.GetCardinality(), err)
+	}
+	if res, err := marvel.Cardinality(datamodel.DefaultConfidence); err != nil || res.GetCardinality() != 2 {
+		t.Error("expected marvel to be unchanged, got", res.GetCardinality(), err)
+	}
+
+	memb := pb.SketchType_MEMB
+	info := datamodel.NewEmptyInfo()
+	info.Name = utils.Stringp("xmen")
+	info.Type = &memb
+	xmen, err := CreateSketch(info)
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	if _, err := Combine(datamodel.NewEmptyInfo(), pb.SetOperation_UNION, marvel, xmen); err == nil {
+		t.Error("expected error merging a MEMB sketch, got", err)
+	}
+	if _, err := Combine(datamodel.NewEmptyInfo(), pb.SetOperation_UNION, xmen); err == nil {
+		t.Error("expected error merging MEMB sketches, got", err)
+	}
+	if _, err := Combine(datamodel.NewEmptyInfo(), pb.SetOperation_INTERSECTION, marvel, dc); err == nil {
+		t.Error("expected error intersecting CARD sketches, got", err)
+	} else if _, ok := err.(*UnsupportedError); !ok {
+		t.Errorf("expected UnsupportedError, got %T", err)
+	}
+}
@@ -0,0 +1,221 @@
+package sketches
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"datamodel"
+	pb "datamodel/protobuf"
+
+	"utils"
+)
+
+func init() {
+	datamodel.Register(&datamodel.SketchType{
+		Type: pb.SketchType_RANGE,
+		Name: datamodel.Range,
+		New: func(info *datamodel.Info) (datamodel.Sketcher, error) {
+			return NewRangeSketch(info)
+		},
+		Defaults:     &pb.SketchProperties{MaxValue: utils.Int64p(datamodel.DefaultMaxValue)},
+		Capabilities: datamodel.RangeQuery,
+		// Only integers can be added, domains mostly count other values
+		Optional: true,
+	})
+}
+
+// Dimensions of the count-min sketches of a RangeSketch level
+const (
+	rangeErrorRate = 0.001
+	rangeWidth     = 2719 // ceil(e / rangeErrorRate)
+	rangeDepth     = 4
+)
+
+// RangeSketch counts integers between minValue and maxValue and answers how
+// many fall in a range. It is a dyadic count-min sketch: level l counts the
+// values in buckets of 2^l consecutive integers, a range is the sum of at
+// most two buckets per level. Levels with few buckets are counted exactly.
+type RangeSketch struct {
+	*datamodel.Info
+	min    int64
+	levels []*rangeLevel
+	total  uint64
+}
+
+// rangeLevel holds either exact counters or a count-min sketch
+type rangeLevel struct {
+	exact  []uint64
+	sketch [][]uint64
+}
+
+// NewRangeSketch ...
+func NewRangeSketch(info *datamodel.Info) (*RangeSketch, error) {
+	min, max := info.Properties.GetMinValue(), info.Properties.GetMaxValue()
+	if max < min {
+		return nil, errProperty("Invalid range [%d, %d], maxValue is smaller than minValue", min, max)
+	}
+	// last is the offset of maxValue, the domain holds last + 1 values
+	last := uint64(max - min)
+	if last == math.MaxUint64 {
+		return nil, errProperty("Invalid range [%d, %d], it can hold at most %d values", min, max, uint64(math.MaxUint64))
+	}
+	d := &RangeSketch{Info: info, min: min}
+	for l := uint(0); ; l++ {
+		buckets := last>>l + 1
+		level := &rangeLevel{}
+		if buckets <= rangeWidth*rangeDepth {
+			level.exact = make([]uint64, buckets)
+		} else {
+			level.sketch = make([][]uint64, rangeDepth)
+			for i := range level.sketch {
+				level.sketch[i] = make([]uint64, rangeWidth)
+			}
+		}
+		d.levels = append(d.levels, level)
+		if buckets == 1 {
+			break
+		}
+	}
+	return d, nil
+}
+
+// bucketLocations returns the counter of a bucket in every row of a level
+func bucketLocations(bucket uint64) [rangeDepth]int {
+	// splitmix64 finalizer, split in two hashes for double hashing
+	h := bucket + 0x9e3779b97f4a7c15
+	h = (h ^ (h >> 30)) * 0xbf58476d1ce4e5b9
+	h = (h ^ (h >> 27)) * 0x94d049bb133111eb
+	h ^= h >> 31
+	h1, h2 := uint32(h), uint32(h>>32)
+	var locs [rangeDepth]int
+	for i := range locs {
+		locs[i] = int((h1 + uint32(i)*h2) % rangeWidth)
+	}
+	return locs
+}
+
+func (l *rangeLevel) add(bucket uint64, count uint64) {
+	if l.exact != nil {
+		l.exact[bucket] += count
+		return
+	}
+	for i, j := range bucketLocations(bucket) {
+		l.sketch[i][j] += count
+	}
+}
+
+func (l *rangeLevel) count(bucket uint64) uint64 {
+	if l.exact != nil {
+		return l.exact[bucket]
+	}
+	count := uint64(math.MaxUint64)
+	for i, j := range bucketLocations(bucket) {
+		if c := l.sketch[i][j]; c < count {
+			count = c
+		}
+	}
+	return count
+}
+
+// offset parses a value and returns its distance to minValue
+func (d *RangeSketch) offset(value []byte) (uint64, error) {
+	v, err := strconv.ParseInt(string(value), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Value %q is not an integer", value)
+	}
+	if max := d.Properties.GetMaxValue(); v < d.min || v > max {
+		return 0, fmt.Errorf("Value %d is out of range [%d, %d]", v, d.min, max)
+	}
+	return uint64(v - d.min), nil
+}
+
+// Add counts the values that are integers within the range of the sketch,
+// the other values are skipped and reported in the error
+func (d *RangeSketch) Add(values [][]byte) (int, error) {
+	dict := make(map[uint64]uint64)
+	var invalid []error
+	for _, v := range values {
+		offset, err := d.offset(v)
+		if err != nil {
+			invalid = append(invalid, err)
+			continue
+		}
+		dict[offset]++
+	}
+	applied := 0
+	for offset, count := range dict {
+		for l, level := range d.levels {
+			level.add(offset>>uint(l), count)
+		}
+		applied += int(count)
+	}
+	d.total += uint64(applied)
+	switch len(invalid) {
+	case 0:
+		return applied, nil
+	case 1:
+		return applied, invalid[0]
+	default:
+		return applied, fmt.Errorf("%s, and %d more invalid values", invalid[0], len(invalid)-1)
+	}
+}
+
+// RangeCount returns the number of values between lo and hi, both included.
+// The bounds are clipped to the range of the sketch.
+func (d *RangeSketch) RangeCount(lo, hi int64) (*pb.RangeCountResult, error) {
+	res := &pb.RangeCountResult{
+		Lo:    utils.Int64p(lo),
+		Hi:    utils.Int64p(hi),
+		Count: utils.Int64p(0),
+		Error: utils.Int64p(0),
+	}
+	if lo < d.min {
+		lo = d.min
+	}
+	if max := d.Properties.GetMaxValue(); hi > max {
+		hi = max
+	}
+	if lo > hi {
+		return res, nil
+	}
+
+	var count uint64
+	estimated := 0
+	from, to := uint64(lo-d.min), uint64(hi-d.min)
+	add := func(level *rangeLevel, bucket uint64) {
+		count += level.count(bucket)
+		if level.exact == nil {
+			estimated++
+		}
+	}
+	for _, level := range d.levels {
+		if from == to {
+			add(level, from)
+			break
+		}
+		if from&1 == 1 {
+			add(level, from)
+			from++
+		}
+		if to&1 == 0 {
+			add(level, to)
+			to--
+		}
+		if from > to {
+			break
+		}
+		from, to = from>>1, to>>1
+	}
+	res.Count = utils.Int64p(int64(count))
+	// Every estimated bucket overcounts by at most ε times the total
+	res.Error = utils.Int64p(int64(math.Ceil(rangeErrorRate * float64(d.total) * float64(estimated))))
+	return res, nil
+}
+
+// Clone ...
+func (d *RangeSketch) Clone(info *datamodel.Info) (datamodel.Sketcher, error) {
+	clone := deepCopy(d).(*RangeSketch)
+	clone.Info = info
+	return clone, nil
+}
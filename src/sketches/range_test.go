@@ -0,0 +1,149 @@
+package sketches
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"datamodel"
+	"testutils"
+	"utils"
+)
+
+func rangeInfo(min, max int64) *datamodel.Info {
+	info := datamodel.NewEmptyInfo()
+	info.Properties.MinValue = utils.Int64p(min)
+	info.Properties.MaxValue = utils.Int64p(max)
+	info.Name = utils.Stringp("latency")
+	return info
+}
+
+func TestRangeCount(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	sketch, err := NewRangeSketch(rangeInfo(-100, 1000))
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	var values [][]byte
+	for i := -100; i <= 1000; i++ {
+		values = append(values, []byte(strconv.Itoa(i)))
+	}
+	if applied, err := sketch.Add(values); err != nil || applied != len(values) {
+		t.Errorf("expected %d values applied, got %d, %v", len(values), applied, err)
+	}
+
+	tests := []struct {
+		lo, hi, expected int64
+	}{
+		{-100, 1000, 1101},
+		{0, 0, 1},
+		{-5, 5, 11},
+		{17, 513, 497},
+		{-1000, -50, 51},
+		{999, 5000, 2},
+		{2000, 3000, 0},
+	}
+	for _, test := range tests {
+		if res, err := sketch.RangeCount(test.lo, test.hi); err != nil {
+			t.Error("expected no errors, got", err)
+		} else if c := res.GetCount(); c != test.expected || res.GetError() != 0 {
+			t.Errorf("expected [%d, %d] == %d, got %d ± %d", test.lo, test.hi, test.expected, c, res.GetError())
+		}
+	}
+}
+
+func TestRangeCountLargeDomain(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	sketch, err := NewRangeSketch(rangeInfo(0, datamodel.DefaultMaxValue))
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	r := rand.New(rand.NewSource(42))
+	var values [][]byte
+	inRange := int64(0)
+	for i := 0; i < 10000; i++ {
+		v := r.Int63n(datamodel.DefaultMaxValue)
+		if v >= 1<<30 && v < 1<<31 {
+			inRange++
+		}
+		values = append(values, []byte(strconv.FormatInt(v, 10)))
+	}
+	if _, err := sketch.Add(values); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+
+	res, err := sketch.RangeCount(1<<30, 1<<31-1)
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	if c, e := res.GetCount(), res.GetError(); c < inRange || c > inRange+e {
+		t.Errorf("expected %d in [%d, %d]", inRange, c-e, c)
+	}
+}
+
+func TestRangeInvalidValues(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	if _, err := NewRangeSketch(rangeInfo(10, 0)); err == nil {
+		t.Error("expected error creating an empty range, got", err)
+	}
+	if _, err := NewRangeSketch(rangeInfo(math.MinInt64, math.MaxInt64)); err == nil {
+		t.Error("expected error creating a range of 2^64 values, got", err)
+	}
+	wide, err := NewRangeSketch(rangeInfo(math.MinInt64, math.MaxInt64-1))
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	extremes := [][]byte{[]byte(strconv.FormatInt(math.MinInt64, 10)), []byte(strconv.FormatInt(math.MaxInt64-1, 10))}
+	if applied, err := wide.Add(extremes); err != nil || applied != 2 {
+		t.Errorf("expected 2 values applied, got %d, %v", applied, err)
+	}
+	if res, err := wide.RangeCount(math.MinInt64, math.MaxInt64); err != nil || res.GetCount() != 2 {
+		t.Error("expected 2 values in the sketch, got", res.GetCount(), err)
+	}
+
+	sketch, err := NewRangeSketch(rangeInfo(0, 100))
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	values := [][]byte{[]byte("1"), []byte("hulk"), []byte("101"), []byte("2.5"), []byte("100")}
+	applied, err := sketch.Add(values)
+	if applied != 2 {
+		t.Error("expected 2 values applied, got", applied)
+	}
+	if err == nil || err.Error() != `Value "hulk" is not an integer, and 2 more invalid values` {
+		t.Error("expected error naming hulk, got", err)
+	}
+	if res, err := sketch.RangeCount(0, 100); err != nil || res.GetCount() != 2 {
+		t.Error("expected 2 values in the sketch, got", res.GetCount(), err)
+	}
+}
+
+func TestCloneRange(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	sketch, err := NewRangeSketch(rangeInfo(0, 100))
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	if _, err := sketch.Add([][]byte{[]byte("1"), []byte("2")}); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	clone, err := sketch.Clone(rangeInfo(0, 100))
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	if _, err := sketch.Add([][]byte{[]byte("3")}); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	if res, err := clone.(*RangeSketch).RangeCount(0, 100); err != nil || res.GetCount() != 2 {
+		t.Error("expected 2 values in the clone, got", res.GetCount(), err)
+	}
+}
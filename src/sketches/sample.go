@@ -0,0 +1,226 @@
+package sketches
+
+import (
+	"bytes"
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"datamodel"
+	pb "datamodel/protobuf"
+
+	"utils"
+)
+
+func init() {
+	datamodel.Register(&datamodel.SketchType{
+		Type: pb.SketchType_SAMP,
+		Name: datamodel.Sample,
+		New: func(info *datamodel.Info) (datamodel.Sketcher, error) {
+			return NewSampleSketch(info)
+		},
+		Defaults:     &pb.SketchProperties{Size: utils.Int64p(datamodel.DefaultSampleSize)},
+		Capabilities: datamodel.SampleQuery | datamodel.Weighting,
+		// Samples are for inspecting a domain, they are attached on demand
+		Optional: true,
+	})
+}
+
+// SampleSketch keeps a sample of size values drawn without replacement from
+// the values added. Every value gets the key log(u)/w for a random u in
+// ]0, 1[ and its weight w, the values with the largest keys are kept
+// (A-Res). Unweighted sketches give every value the weight 1, which makes
+// the sample uniform. The random numbers are drawn from a generator seeded
+// with the sketch ID, so that replaying the AOF rebuilds the same sample.
+type SampleSketch struct {
+	*datamodel.Info
+	size     int
+	weighted bool
+	seed     uint64
+	count    int64
+	// heap orders the sampled values from the smallest key up, the smallest
+	// is replaced when the sample is full
+	heap sampleHeap
+}
+
+type sampledValue struct {
+	value  []byte
+	weight float64
+	key    float64
+}
+
+type sampleHeap []*sampledValue
+
+func (h sampleHeap) Len() int            { return len(h) }
+func (h sampleHeap) Less(i, j int) bool  { return h[i].key < h[j].key }
+func (h sampleHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *sampleHeap) Push(x interface{}) { *h = append(*h, x.(*sampledValue)) }
+func (h *sampleHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// NewSampleSketch creates a sketch sampling size values, DefaultSampleSize if
+// the size is not set
+func NewSampleSketch(info *datamodel.Info) (*SampleSketch, error) {
+	size := info.Properties.GetSize()
+	if size == 0 {
+		size = datamodel.DefaultSampleSize
+	}
+	if size < 0 {
+		return nil, errProperty("Invalid size %d, must be positive", size)
+	}
+	return &SampleSketch{
+		Info:     info,
+		size:     int(size),
+		weighted: info.Properties.GetWeighted(),
+		seed:     thetaHash([]byte(info.ID())),
+	}, nil
+}
+
+// random returns the next number of the splitmix64 generator of the sketch
+// as a float in ]0, 1[
+func (d *SampleSketch) random() float64 {
+	d.seed += 0x9e3779b97f4a7c15
+	h := d.seed
+	h = (h ^ (h >> 30)) * 0xbf58476d1ce4e5b9
+	h = (h ^ (h >> 27)) * 0x94d049bb133111eb
+	h ^= h >> 31
+	return (float64(h>>11) + 0.5) / (1 << 53)
+}
+
+// parseWeighted splits a value of a weighted sketch into the value and its
+// weight, which follows the last colon
+func parseWeighted(v []byte) ([]byte, float64, error) {
+	i := bytes.LastIndexByte(v, ':')
+	if i < 0 {
+		return nil, 0, fmt.Errorf("Value %q has no weight, expected value:weight", v)
+	}
+	weight, err := strconv.ParseFloat(string(v[i+1:]), 64)
+	if err != nil || weight <= 0 || math.IsInf(weight, 1) {
+		return nil, 0, fmt.Errorf("Invalid weight %q of value %q, expected a positive number", v[i+1:], v[:i])
+	}
+	return v[:i], weight, nil
+}
+
+// Add offers the values to the sample. Weighted sketches skip the values
+// without a valid weight and report them in the error.
+func (d *SampleSketch) Add(values [][]byte) (int, error) {
+	var invalid []error
+	applied := 0
+	for _, v := range values {
+		weight := 1.0
+		if d.weighted {
+			var err error
+			if v, weight, err = parseWeighted(v); err != nil {
+				invalid = append(invalid, err)
+				continue
+			}
+		}
+		d.count++
+		applied++
+		key := math.Log(d.random()) / weight
+		if len(d.heap) < d.size {
+			heap.Push(&d.heap, &sampledValue{append([]byte(nil), v...), weight, key})
+		} else if key > d.heap[0].key {
+			d.heap[0] = &sampledValue{append([]byte(nil), v...), weight, key}
+			heap.Fix(&d.heap, 0)
+		}
+	}
+	switch len(invalid) {
+	case 0:
+		return applied, nil
+	case 1:
+		return applied, invalid[0]
+	default:
+		return applied, fmt.Errorf("%s, and %d more invalid values", invalid[0], len(invalid)-1)
+	}
+}
+
+// Sample returns the sampled values, the ones most likely to be kept first
+func (d *SampleSketch) Sample() (*pb.SampleResult, error) {
+	sorted := make(sampleHeap, len(d.heap))
+	copy(sorted, d.heap)
+	sort.Sort(sort.Reverse(sorted))
+	res := &pb.SampleResult{
+		Values: make([]*pb.SampledValue, len(sorted)),
+		Count:  utils.Int64p(d.count),
+	}
+	for i, v := range sorted {
+		res.Values[i] = &pb.SampledValue{Value: utils.Stringp(string(v.value))}
+		if d.weighted {
+			res.Values[i].Weight = utils.Float32p(float32(v.weight))
+		}
+	}
+	return res, nil
+}
+
+// Marshal writes the size, the state of the generator, the count and every
+// sampled value with its weight and key
+func (d *SampleSketch) Marshal() ([]byte, error) {
+	buf := make([]byte, binary.MaxVarintLen64)
+	var data []byte
+	putUvarint := func(x uint64) {
+		n := binary.PutUvarint(buf, x)
+		data = append(data, buf[:n]...)
+	}
+	putUvarint(uint64(d.size))
+	putUvarint(d.seed)
+	putUvarint(uint64(d.count))
+	for _, v := range d.heap {
+		putUvarint(math.Float64bits(v.weight))
+		putUvarint(math.Float64bits(v.key))
+		putUvarint(uint64(len(v.value)))
+		data = append(data, v.value...)
+	}
+	return data, nil
+}
+
+// Unmarshal ...
+func (d *SampleSketch) Unmarshal(data []byte) error {
+	r := bytes.NewReader(data)
+	var header [3]uint64
+	for i := range header {
+		x, err := binary.ReadUvarint(r)
+		if err != nil {
+			return fmt.Errorf("Invalid SAMP sketch: %s", err)
+		}
+		header[i] = x
+	}
+	size := int(header[0])
+	var sh sampleHeap
+	for r.Len() > 0 {
+		var fields [3]uint64
+		for i := range fields {
+			x, err := binary.ReadUvarint(r)
+			if err != nil {
+				return fmt.Errorf("Invalid SAMP sketch: %s", err)
+			}
+			fields[i] = x
+		}
+		if fields[2] > uint64(r.Len()) {
+			return fmt.Errorf("Invalid SAMP sketch, value of %d bytes is truncated", fields[2])
+		}
+		value := make([]byte, fields[2])
+		_, _ = r.Read(value)
+		sh = append(sh, &sampledValue{value, math.Float64frombits(fields[0]), math.Float64frombits(fields[1])})
+	}
+	if len(sh) > size {
+		return fmt.Errorf("Invalid SAMP sketch of size %d holding %d values", size, len(sh))
+	}
+	heap.Init(&sh)
+	d.size, d.seed, d.count, d.heap = size, header[1], int64(header[2]), sh
+	return nil
+}
+
+// Clone ...
+func (d *SampleSketch) Clone(info *datamodel.Info) (datamodel.Sketcher, error) {
+	clone := deepCopy(d).(*SampleSketch)
+	clone.Info = info
+	return clone, nil
+}
@@ -0,0 +1,128 @@
+package sketches
+
+import (
+	"strconv"
+	"testing"
+
+	"datamodel"
+	"testutils"
+	"utils"
+)
+
+func sampleSketch(t *testing.T, name string, size int64, weighted bool) *SampleSketch {
+	info := datamodel.NewEmptyInfo()
+	info.Properties.Size = utils.Int64p(size)
+	info.Properties.Weighted = utils.Boolp(weighted)
+	info.Name = utils.Stringp(name)
+	sketch, err := NewSampleSketch(info)
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	return sketch
+}
+
+func TestSampleUniform(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	// Every value of the first half should make about half of the samples
+	firstHalf := 0
+	for run := 0; run < 100; run++ {
+		sketch := sampleSketch(t, "events"+strconv.Itoa(run), 10, false)
+		var values [][]byte
+		for i := 0; i < 1000; i++ {
+			values = append(values, []byte(strconv.Itoa(i)))
+		}
+		if applied, err := sketch.Add(values); err != nil || applied != 1000 {
+			t.Fatal("expected 1000 values applied, got", applied, err)
+		}
+		res, err := sketch.Sample()
+		if err != nil {
+			t.Fatal("expected no errors, got", err)
+		}
+		if len(res.GetValues()) != 10 || res.GetCount() != 1000 {
+			t.Fatal("expected 10 of 1000 values, got", res)
+		}
+		for _, v := range res.GetValues() {
+			if i, _ := strconv.Atoi(v.GetValue()); i < 500 {
+				firstHalf++
+			}
+		}
+	}
+	if firstHalf < 400 || firstHalf > 600 {
+		t.Error("expected about 500 of 1000 sampled values from the first half, got", firstHalf)
+	}
+}
+
+func TestSampleWeighted(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	sketch := sampleSketch(t, "events", 5, true)
+	var values [][]byte
+	for i := 0; i < 1000; i++ {
+		values = append(values, []byte("light"+strconv.Itoa(i)+":0.001"))
+	}
+	values = append(values, []byte("heavy:1000"), []byte("http://heavy:8080:1000"))
+	applied, err := sketch.Add(append(values, []byte("noweight"), []byte("negative:-1")))
+	if applied != 1002 || err == nil {
+		t.Error("expected 1002 values applied and an error for 2 invalid ones, got", applied, err)
+	}
+	res, err := sketch.Sample()
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	found := 0
+	for _, v := range res.GetValues() {
+		if v.GetValue() == "heavy" || v.GetValue() == "http://heavy:8080" {
+			found++
+			if v.GetWeight() != 1000 {
+				t.Error("expected weight 1000, got", v.GetWeight())
+			}
+		}
+	}
+	if found != 2 {
+		t.Error("expected both heavy values in the sample, got", res.GetValues())
+	}
+}
+
+func TestSampleMarshal(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	sketch := sampleSketch(t, "events", 3, false)
+	replayed := sampleSketch(t, "events", 3, false)
+	values := [][]byte{[]byte("hulk"), []byte("thor"), []byte("storm"), []byte("wolverine")}
+	if _, err := sketch.Add(values); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	data, err := sketch.Marshal()
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	restored := sampleSketch(t, "events", 3, false)
+	if err := restored.Unmarshal(data); err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+
+	// Adding the same values again gives the same sample
+	more := [][]byte{[]byte("cyclops"), []byte("gambit")}
+	for _, s := range []*SampleSketch{sketch, restored} {
+		if _, err := s.Add(more); err != nil {
+			t.Error("expected no errors, got", err)
+		}
+	}
+	if _, err := replayed.Add(append(values, more...)); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	expected, _ := sketch.Sample()
+	for _, s := range []*SampleSketch{restored, replayed} {
+		res, _ := s.Sample()
+		if res.String() != expected.String() {
+			t.Error("expected the same sample", expected, "got", res)
+		}
+	}
+	if err := restored.Unmarshal(data[:len(data)-1]); err == nil {
+		t.Error("expected an error unmarshaling a truncated sketch")
+	}
+}
@@ -0,0 +1,236 @@
+package sketches
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+
+	"datamodel"
+	pb "datamodel/protobuf"
+
+	"utils"
+)
+
+func init() {
+	datamodel.Register(&datamodel.SketchType{
+		Type: pb.SketchType_THETA,
+		Name: datamodel.Theta,
+		New: func(info *datamodel.Info) (datamodel.Sketcher, error) {
+			return NewThetaSketch(info)
+		},
+		Defaults:     &pb.SketchProperties{Size: utils.Int64p(datamodel.DefaultThetaSize)},
+		Capabilities: datamodel.CardinalityQuery | datamodel.SetQuery,
+		// Domains already count distinct values with a CARD sketch
+		Optional: true,
+	})
+}
+
+// ThetaSketch estimates the number of distinct values from the smallest
+// hashes of the values (k minimum values). It keeps up to size hashes, all
+// below theta, a uniform sample of the hash space. Unlike HLL the samples of
+// two sketches can be intersected and subtracted, the error of the result
+// depends on the size of the result rather than on the size of the sketches.
+type ThetaSketch struct {
+	*datamodel.Info
+	size int
+	// theta is the exclusive upper bound of the kept hashes, MaxUint64
+	// while every value is kept and the count is exact
+	theta  uint64
+	hashes map[uint64]struct{}
+	// heap orders the hashes from the largest down, the largest is dropped
+	// when the sketch is full
+	heap hashHeap
+}
+
+type hashHeap []uint64
+
+func (h hashHeap) Len() int            { return len(h) }
+func (h hashHeap) Less(i, j int) bool  { return h[i] > h[j] }
+func (h hashHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *hashHeap) Push(x interface{}) { *h = append(*h, x.(uint64)) }
+func (h *hashHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// NewThetaSketch creates a sketch keeping up to size hashes, DefaultThetaSize
+// if the size is not set. Its relative standard error is about 1/sqrt(size).
+func NewThetaSketch(info *datamodel.Info) (*ThetaSketch, error) {
+	size := info.Properties.GetSize()
+	if size == 0 {
+		size = datamodel.DefaultThetaSize
+	}
+	if size < 0 {
+		return nil, errProperty("Invalid size %d, must be positive", size)
+	}
+	return &ThetaSketch{
+		Info:   info,
+		size:   int(size),
+		theta:  math.MaxUint64,
+		hashes: make(map[uint64]struct{}),
+	}, nil
+}
+
+// thetaHash spreads the 64 bit FNV-1a hash of a value with the splitmix64
+// finalizer, the kept hashes must be uniform over the whole hash space
+func thetaHash(value []byte) uint64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write(value)
+	h := hash.Sum64() + 0x9e3779b97f4a7c15
+	h = (h ^ (h >> 30)) * 0xbf58476d1ce4e5b9
+	h = (h ^ (h >> 27)) * 0x94d049bb133111eb
+	return h ^ (h >> 31)
+}
+
+// Add ...
+func (d *ThetaSketch) Add(values [][]byte) (int, error) {
+	for _, v := range values {
+		d.insert(thetaHash(v))
+	}
+	return len(values), nil
+}
+
+func (d *ThetaSketch) insert(h uint64) {
+	if h >= d.theta {
+		return
+	}
+	if _, ok := d.hashes[h]; ok {
+		return
+	}
+	d.hashes[h] = struct{}{}
+	heap.Push(&d.heap, h)
+	if len(d.heap) > d.size {
+		largest := heap.Pop(&d.heap).(uint64)
+		delete(d.hashes, largest)
+		d.theta = largest
+	}
+}
+
+// keep lowers theta and keeps only the hashes below it that pass the filter
+func (d *ThetaSketch) keep(theta uint64, filter func(uint64) bool) {
+	if theta < d.theta {
+		d.theta = theta
+	}
+	d.heap = d.heap[:0]
+	for h := range d.hashes {
+		if h >= d.theta || !filter(h) {
+			delete(d.hashes, h)
+			continue
+		}
+		d.heap = append(d.heap, h)
+	}
+	heap.Init(&d.heap)
+}
+
+// Cardinality returns the number of kept hashes scaled up to the whole hash
+// space. With p = theta / 2^64 and n kept hashes the estimate n/p has a
+// variance of about n(1-p)/p^2, the bounds use at least n = 1 so that an
+// empty result still bounds what it could have missed.
+func (d *ThetaSketch) Cardinality(confidence float64) (*pb.CardinalityResult, error) {
+	n := float64(len(d.hashes))
+	p := math.Ldexp(float64(d.theta), -64)
+	estimate := n / p
+	margin := zScore(confidence) * math.Sqrt(math.Max(n, 1)*(1-p)) / p
+	return &pb.CardinalityResult{
+		Cardinality: utils.Int64p(int64(math.Floor(estimate + 0.5))),
+		Lower:       utils.Int64p(int64(math.Max(n, math.Floor(estimate-margin)))),
+		Upper:       utils.Int64p(int64(math.Ceil(estimate + margin))),
+		Confidence:  utils.Float32p(float32(confidence)),
+	}, nil
+}
+
+func (d *ThetaSketch) thetaSketch(other datamodel.Sketcher) (*ThetaSketch, error) {
+	o, ok := other.(*ThetaSketch)
+	if !ok {
+		return nil, fmt.Errorf("Can not combine %T with %T", other, d)
+	}
+	return o, nil
+}
+
+// Merge adds the values of another THETA sketch
+func (d *ThetaSketch) Merge(other datamodel.Sketcher) error {
+	o, err := d.thetaSketch(other)
+	if err != nil {
+		return err
+	}
+	d.keep(o.theta, func(uint64) bool { return true })
+	for h := range o.hashes {
+		d.insert(h)
+	}
+	return nil
+}
+
+// Intersect keeps only the values also added to another THETA sketch
+func (d *ThetaSketch) Intersect(other datamodel.Sketcher) error {
+	o, err := d.thetaSketch(other)
+	if err != nil {
+		return err
+	}
+	d.keep(o.theta, func(h uint64) bool {
+		_, ok := o.hashes[h]
+		return ok
+	})
+	return nil
+}
+
+// Subtract drops the values added to another THETA sketch
+func (d *ThetaSketch) Subtract(other datamodel.Sketcher) error {
+	o, err := d.thetaSketch(other)
+	if err != nil {
+		return err
+	}
+	d.keep(o.theta, func(h uint64) bool {
+		_, ok := o.hashes[h]
+		return !ok
+	})
+	return nil
+}
+
+// Marshal writes the size, theta and the kept hashes as big endian 64 bit
+// integers
+func (d *ThetaSketch) Marshal() ([]byte, error) {
+	data := make([]byte, 16+8*len(d.heap))
+	binary.BigEndian.PutUint64(data, uint64(d.size))
+	binary.BigEndian.PutUint64(data[8:], d.theta)
+	for i, h := range d.heap {
+		binary.BigEndian.PutUint64(data[16+8*i:], h)
+	}
+	return data, nil
+}
+
+// Unmarshal ...
+func (d *ThetaSketch) Unmarshal(data []byte) error {
+	if len(data) < 16 || len(data)%8 != 0 {
+		return fmt.Errorf("Invalid THETA sketch of %d bytes", len(data))
+	}
+	size := int(binary.BigEndian.Uint64(data))
+	theta := binary.BigEndian.Uint64(data[8:])
+	n := len(data)/8 - 2
+	if n > size {
+		return fmt.Errorf("Invalid THETA sketch of size %d holding %d hashes", size, n)
+	}
+	hashes := make(map[uint64]struct{}, n)
+	hh := make(hashHeap, 0, n)
+	for i := 0; i < n; i++ {
+		h := binary.BigEndian.Uint64(data[16+8*i:])
+		if h >= theta {
+			return fmt.Errorf("Invalid THETA sketch, hash %d is not below theta %d", h, theta)
+		}
+		hashes[h] = struct{}{}
+		hh = append(hh, h)
+	}
+	heap.Init(&hh)
+	d.size, d.theta, d.hashes, d.heap = size, theta, hashes, hh
+	return nil
+}
+
+// Clone ...
+func (d *ThetaSketch) Clone(info *datamodel.Info) (datamodel.Sketcher, error) {
+	clone := deepCopy(d).(*ThetaSketch)
+	clone.Info = info
+	return clone, nil
+}
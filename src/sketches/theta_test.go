@@ -0,0 +1,121 @@
+package sketches
+
+import (
+	"strconv"
+	"testing"
+
+	"datamodel"
+	"testutils"
+	"utils"
+)
+
+func thetaSketch(t *testing.T, size int64, from, to int) *ThetaSketch {
+	info := datamodel.NewEmptyInfo()
+	info.Properties.Size = utils.Int64p(size)
+	info.Name = utils.Stringp("visitors")
+	sketch, err := NewThetaSketch(info)
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	var values [][]byte
+	for i := from; i < to; i++ {
+		values = append(values, []byte("user"+strconv.Itoa(i)))
+	}
+	if _, err := sketch.Add(values); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	return sketch
+}
+
+func expectCardinality(t *testing.T, sketch *ThetaSketch, expected int64) {
+	res, err := sketch.Cardinality(0.99)
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	if res.GetLower() > expected || res.GetUpper() < expected {
+		t.Errorf("expected %d within [%d, %d], estimated %d", expected, res.GetLower(), res.GetUpper(), res.GetCardinality())
+	}
+}
+
+func TestThetaExact(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	sketch := thetaSketch(t, 1000, 0, 500)
+	if _, err := sketch.Add([][]byte{[]byte("user1"), []byte("user2")}); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	res, err := sketch.Cardinality(datamodel.DefaultConfidence)
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	if res.GetCardinality() != 500 || res.GetLower() != 500 || res.GetUpper() != 500 {
+		t.Error("expected exactly 500, got", res)
+	}
+}
+
+func TestThetaSetOperations(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	// 100000 visitors each, 1000 of them visited both
+	a := thetaSketch(t, 4096, 0, 100000)
+	b := thetaSketch(t, 4096, 99000, 199000)
+	expectCardinality(t, a, 100000)
+
+	union, _ := a.Clone(a.Info)
+	if err := union.(*ThetaSketch).Merge(b); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	expectCardinality(t, union.(*ThetaSketch), 199000)
+
+	both, _ := a.Clone(a.Info)
+	if err := both.(*ThetaSketch).Intersect(b); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	expectCardinality(t, both.(*ThetaSketch), 1000)
+
+	onlyA, _ := a.Clone(a.Info)
+	if err := onlyA.(*ThetaSketch).Subtract(b); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	expectCardinality(t, onlyA.(*ThetaSketch), 99000)
+
+	// Intersecting with a small exact sketch keeps the precision of the large one
+	small := thetaSketch(t, 4096, 99500, 100500)
+	both, _ = a.Clone(a.Info)
+	if err := both.(*ThetaSketch).Intersect(small); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+	expectCardinality(t, both.(*ThetaSketch), 500)
+
+	if err := a.Merge(&HLLPPSketch{}); err == nil {
+		t.Error("expected error merging a CARD sketch, got", err)
+	}
+}
+
+func TestThetaMarshal(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	sketch := thetaSketch(t, 64, 0, 1000)
+	data, err := sketch.Marshal()
+	if err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	restored := thetaSketch(t, 64, 0, 0)
+	if err := restored.Unmarshal(data); err != nil {
+		t.Fatal("expected no errors, got", err)
+	}
+	expected, _ := sketch.Cardinality(datamodel.DefaultConfidence)
+	if res, _ := restored.Cardinality(datamodel.DefaultConfidence); res.GetCardinality() != expected.GetCardinality() {
+		t.Errorf("expected %d after unmarshaling, got %d", expected.GetCardinality(), res.GetCardinality())
+	}
+	// Later adds must still evict the largest hash
+	if _, err := restored.Add([][]byte{[]byte("user1000")}); err != nil || len(restored.hashes) != 64 {
+		t.Error("expected 64 hashes, got", len(restored.hashes), err)
+	}
+	if err := restored.Unmarshal(data[:20]); err == nil {
+		t.Error("expected error unmarshaling truncated data, got", err)
+	}
+}
@@ -9,6 +9,21 @@ import (
 	"utils"
 )
 
+func init() {
+	datamodel.Register(&datamodel.SketchType{
+		Type: pb.SketchType_RANK,
+		Name: datamodel.TopK,
+		New: func(info *datamodel.Info) (datamodel.Sketcher, error) {
+			if info.Properties.GetHalfLife() > 0 {
+				return NewDecayingTopKSketch(info)
+			}
+			return NewTopKSketch(info)
+		},
+		Defaults:     &pb.SketchProperties{Size: utils.Int64p(datamodel.DefaultSize)},
+		Capabilities: datamodel.RankingsQuery | datamodel.Decay,
+	})
+}
+
 // TopKSketch is the toplevel sketch to control the HLL implementation
 type TopKSketch struct {
 	*datamodel.Info
@@ -26,7 +41,7 @@ func NewTopKSketch(info *datamodel.Info) (*TopKSketch, error) {
 }
 
 // Add ...
-func (d *TopKSketch) Add(values [][]byte) (bool, error) {
+func (d *TopKSketch) Add(values [][]byte) (int, error) {
 	dict := make(map[string]int)
 	for _, v := range values {
 		dict[string(v)]++
@@ -34,25 +49,69 @@ func (d *TopKSketch) Add(values [][]byte) (bool, error) {
 	for v, count := range dict {
 		d.impl.Insert(v, count)
 	}
-	return true, nil
+	return len(values), nil
 }
 
-// Get ...
-func (d *TopKSketch) Get(interface{}) (interface{}, error) {
+// Rankings returns the top values, by default up to half the size of the
+// sketch. An explicit limit can reach up to the number of tracked values,
+// twice the size, though lower ranks are less precise.
+func (d *TopKSketch) Rankings(opts datamodel.RankingsOptions) (*pb.RankingsResult, error) {
 	keys := d.impl.Keys()
-	size := len(keys)
-	if size > int(d.Info.Properties.GetSize())/2 {
-		size = int(d.Info.Properties.GetSize()) / 2
+	// Keys are sorted by count, cut off the ones below the minimum count
+	for i, k := range keys {
+		if int64(k.Count) < opts.MinCount {
+			keys = keys[:i]
+			break
+		}
 	}
+	start, end := rankingsPage(len(keys), opts, int(d.Info.Properties.GetSize())/2)
+	keys = keys[start:end]
 	result := &pb.RankingsResult{
-		Rankings: make([]*pb.Rank, size, size),
+		Rankings: make([]*pb.Rank, len(keys), len(keys)),
 	}
-	for i := range result.Rankings {
-		k := keys[i]
+	for i, k := range keys {
 		result.Rankings[i] = &pb.Rank{
 			Value: utils.Stringp(k.Key),
 			Count: utils.Int64p(int64(k.Count)),
+			Error: utils.Int64p(int64(k.Error)),
 		}
 	}
 	return result, nil
 }
+
+// rankingsPage returns the bounds of the page of n rankings selected by opts,
+// limit is used if opts do not have one
+func rankingsPage(n int, opts datamodel.RankingsOptions, limit int) (start, end int) {
+	if opts.Limit != 0 {
+		limit = opts.Limit
+	}
+	start = opts.Offset
+	if start > n {
+		start = n
+	}
+	end = start + limit
+	if end > n {
+		end = n
+	}
+	return start, end
+}
+
+// Marshal ...
+func (d *TopKSketch) Marshal() ([]byte, error) {
+	return d.impl.GobEncode()
+}
+
+// Unmarshal ...
+func (d *TopKSketch) Unmarshal(data []byte) error {
+	impl := &topk.Stream{}
+	if err := impl.GobDecode(data); err != nil {
+		return err
+	}
+	d.impl = impl
+	return nil
+}
+
+// Clone ...
+func (d *TopKSketch) Clone(info *datamodel.Info) (datamodel.Sketcher, error) {
+	return &TopKSketch{info, deepCopy(d.impl).(*topk.Stream)}, nil
+}
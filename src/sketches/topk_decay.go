@@ -0,0 +1,157 @@
+package sketches
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+	"time"
+
+	"datamodel"
+	pb "datamodel/protobuf"
+
+	"utils"
+)
+
+// DecayingTopKSketch ranks values by their counts decayed with the halfLife
+// of the sketch. Like go-topk it implements the Space-Saving algorithm, with
+// weighted counts so that recent values outrank historical ones.
+type DecayingTopKSketch struct {
+	*datamodel.Info
+	decay    forwardDecay
+	capacity int
+	counts   map[string]*decayedCount
+	// heap orders the counts from the smallest up, the smallest is evicted
+	// when a new value arrives at full capacity
+	heap decayedHeap
+}
+
+type decayedCount struct {
+	key   string
+	count float64
+	err   float64
+	index int
+}
+
+type decayedHeap []*decayedCount
+
+func (h decayedHeap) Len() int           { return len(h) }
+func (h decayedHeap) Less(i, j int) bool { return h[i].count < h[j].count }
+func (h decayedHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *decayedHeap) Push(x interface{}) {
+	c := x.(*decayedCount)
+	c.index = len(*h)
+	*h = append(*h, c)
+}
+
+func (h *decayedHeap) Pop() interface{} {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// byDecayedCount sorts counts from the largest down, ties by value
+type byDecayedCount []*decayedCount
+
+func (c byDecayedCount) Len() int      { return len(c) }
+func (c byDecayedCount) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+func (c byDecayedCount) Less(i, j int) bool {
+	if c[i].count != c[j].count {
+		return c[i].count > c[j].count
+	}
+	return c[i].key < c[j].key
+}
+
+// NewDecayingTopKSketch ...
+func NewDecayingTopKSketch(info *datamodel.Info) (*DecayingTopKSketch, error) {
+	capacity := int(info.Properties.GetSize()) * 2 // For higher precision
+	return &DecayingTopKSketch{
+		Info:     info,
+		decay:    newForwardDecay(info),
+		capacity: capacity,
+		counts:   make(map[string]*decayedCount, capacity),
+	}, nil
+}
+
+// Add ...
+func (d *DecayingTopKSketch) Add(values [][]byte) (int, error) {
+	return d.AddAt(values, now())
+}
+
+// AddAt adds values seen at the given time
+func (d *DecayingTopKSketch) AddAt(values [][]byte, at time.Time) (int, error) {
+	weight, rescale := d.decay.weight(at)
+	if rescale != 1 {
+		for _, c := range d.heap {
+			c.count *= rescale
+			c.err *= rescale
+		}
+	}
+	dict := make(map[string]int)
+	for _, v := range values {
+		dict[string(v)]++
+	}
+	for v, count := range dict {
+		d.insert(v, weight*float64(count))
+	}
+	return len(values), nil
+}
+
+func (d *DecayingTopKSketch) insert(key string, weight float64) {
+	if c, ok := d.counts[key]; ok {
+		c.count += weight
+		heap.Fix(&d.heap, c.index)
+		return
+	}
+	if len(d.heap) < d.capacity {
+		c := &decayedCount{key: key, count: weight}
+		d.counts[key] = c
+		heap.Push(&d.heap, c)
+		return
+	}
+	// Replace the smallest count, the new value may have been counted as
+	// often as the evicted one
+	c := d.heap[0]
+	delete(d.counts, c.key)
+	c.key = key
+	c.err = c.count
+	c.count += weight
+	d.counts[key] = c
+	heap.Fix(&d.heap, 0)
+}
+
+// Rankings returns the top values by their counts decayed to the current
+// time, by default up to half the size of the sketch
+func (d *DecayingTopKSketch) Rankings(opts datamodel.RankingsOptions) (*pb.RankingsResult, error) {
+	scale := d.decay.scale(now())
+	counts := make(byDecayedCount, len(d.heap))
+	copy(counts, d.heap)
+	sort.Sort(counts)
+
+	ranks := make([]*pb.Rank, 0, len(counts))
+	for _, c := range counts {
+		count := int64(math.Floor(c.count*scale + 0.5))
+		if count < opts.MinCount {
+			break
+		}
+		ranks = append(ranks, &pb.Rank{
+			Value: utils.Stringp(c.key),
+			Count: utils.Int64p(count),
+			Error: utils.Int64p(int64(math.Ceil(c.err * scale))),
+		})
+	}
+	start, end := rankingsPage(len(ranks), opts, int(d.Properties.GetSize())/2)
+	return &pb.RankingsResult{Rankings: ranks[start:end]}, nil
+}
+
+// Clone ...
+func (d *DecayingTopKSketch) Clone(info *datamodel.Info) (datamodel.Sketcher, error) {
+	clone := deepCopy(d).(*DecayingTopKSketch)
+	clone.Info = info
+	return clone, nil
+}
@@ -5,7 +5,6 @@ import (
 	"testing"
 
 	"datamodel"
-	pb "datamodel/protobuf"
 	"utils"
 	"testutils"
 )
@@ -70,15 +69,69 @@ func TestAddTopK(t *testing.T) {
 		Position: 4,
 	}
 
-	if res, err := sketch.Get(values); err != nil {
+	if res, err := sketch.Rankings(datamodel.RankingsOptions{}); err != nil {
 		t.Error("expected no errors, got", err)
 	} else {
-		tmp := res.(*pb.RankingsResult)
+		tmp := res
 		rres := tmp.GetRankings()
 		for i := 0; i < len(rres); i++ {
 			if expectedRankings[i].Value != rres[i].GetValue() {
 				t.Errorf("expected rank of %d := %s, got %s", expectedRankings[i].Position, expectedRankings[i].Value, rres[i].GetValue())
 			}
+			if c, e := rres[i].GetCount(), rres[i].GetError(); c-e > expectedRankings[i].Count || c < expectedRankings[i].Count {
+				t.Errorf("expected %s count in [%d, %d] to hold %d", rres[i].GetValue(), c-e, c, expectedRankings[i].Count)
+			}
+		}
+	}
+}
+
+func TestRankingsOptions(t *testing.T) {
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	info := datamodel.NewEmptyInfo()
+	info.Properties.Size = utils.Int64p(4)
+	info.Name = utils.Stringp("marvel")
+	sketch, err := NewTopKSketch(info)
+	if err != nil {
+		t.Error("expected no errors, got", err)
+	}
+
+	values := [][]byte{}
+	for i, v := range []string{"hulk", "thor", "loki", "odin", "sif"} {
+		for j := 0; j < 5-i; j++ {
+			values = append(values, []byte(v))
+		}
+	}
+	if _, err := sketch.Add(values); err != nil {
+		t.Error("expected no errors, got", err)
+	}
+
+	tests := []struct {
+		opts     datamodel.RankingsOptions
+		expected []string
+	}{
+		{datamodel.RankingsOptions{}, []string{"hulk", "thor"}},
+		{datamodel.RankingsOptions{Limit: 4}, []string{"hulk", "thor", "loki", "odin"}},
+		{datamodel.RankingsOptions{Limit: 2, Offset: 1}, []string{"thor", "loki"}},
+		{datamodel.RankingsOptions{Limit: 10, MinCount: 3}, []string{"hulk", "thor", "loki"}},
+		{datamodel.RankingsOptions{Offset: 10}, []string{}},
+	}
+	for _, test := range tests {
+		res, err := sketch.Rankings(test.opts)
+		if err != nil {
+			t.Error("expected no errors, got", err)
+			continue
+		}
+		ranks := res.GetRankings()
+		if len(ranks) != len(test.expected) {
+			t.Errorf("expected %d rankings for %+v, got %d", len(test.expected), test.opts, len(ranks))
+			continue
+		}
+		for i, v := range test.expected {
+			if ranks[i].GetValue() != v {
+				t.Errorf("expected rank %d == %s for %+v, got %s", i, v, test.opts, ranks[i].GetValue())
+			}
 		}
 	}
 }
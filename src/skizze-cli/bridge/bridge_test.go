@@ -0,0 +1,524 @@
+package bridge
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"config"
+	"manager"
+	"server"
+	"testutils"
+)
+
+var buf bytes.Buffer
+
+func setupServer() {
+	config.Reset()
+	testutils.SetupTests()
+	go server.Run(manager.NewManager(), "127.0.0.1", 7778, config.DataDir)
+	time.Sleep(time.Millisecond * 50)
+
+	address = "127.0.0.1:7778"
+	client, conn = setupClient()
+	output = jsonOutput
+	stdout = &buf
+	progress = ioutil.Discard
+	w.Init(stdout, 0, 8, 0, '\t', 0)
+}
+
+func tearDownServer() {
+	tearDownClient(conn)
+	server.Stop()
+	testutils.TearDownTests()
+}
+
+// query evaluates q and decodes the records it printed
+func query(t *testing.T, q string) []map[string]interface{} {
+	buf.Reset()
+	if err := evaluateQuery(q); err != nil {
+		t.Fatalf("Expected no errors evaluating %q, got %s", q, err)
+	}
+	var records []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+		t.Fatalf("Expected JSON output for %q, got %q", q, buf.String())
+	}
+	return records
+}
+
+func TestCreateDestroySketch(t *testing.T) {
+	setupServer()
+	defer tearDownServer()
+
+	if res := query(t, "CREATE FREQ marvel maxUniqueItems=1000 errorRate=0.02"); res[0]["status"] != "done" {
+		t.Error("Expected done, got", res)
+	}
+	res := query(t, "INFO FREQ marvel")
+	if len(res) != 1 {
+		t.Fatal("Expected 1 record, got", res)
+	}
+	if res[0]["maxUniqueItems"] != 1000.0 || res[0]["errorRate"].(float64) < 0.019 || res[0]["size"] != 0.0 {
+		t.Error("Expected maxUniqueItems=1000 errorRate=0.02 size=0, got", res[0])
+	}
+
+	query(t, "CREATE RANK marvel 10")
+	if res := query(t, "INFO RANK marvel"); res[0]["size"] != 10.0 || res[0]["maxUniqueItems"] != 0.0 {
+		t.Error("Expected size=10 maxUniqueItems=0, got", res[0])
+	}
+
+	query(t, "CREATE RANK trending size=10 halfLife=1h")
+	if res := query(t, "INFO RANK trending"); res[0]["halfLife"] != 3600.0 {
+		t.Error("Expected halfLife=3600, got", res[0])
+	}
+	query(t, "DESTROY RANK trending")
+
+	// INFO without a type shows the sketches of every type with the name
+	if res := query(t, "INFO marvel"); len(res) != 2 || res[0]["type"] == res[1]["type"] {
+		t.Error("Expected the FREQ and RANK marvel sketches, got", res)
+	}
+	if err := evaluateQuery("INFO trending"); err == nil {
+		t.Error("Expected error on a missing sketch, got", err)
+	}
+
+	query(t, "DESTROY FREQ marvel")
+	if res := query(t, "LIST"); len(res) != 1 || res[0]["type"] != "RANK" {
+		t.Error("Expected [marvel RANK], got", res)
+	}
+	if err := evaluateQuery("DESTROY FREQ marvel"); err == nil {
+		t.Error("Expected error destroying a non-existing sketch, got", err)
+	}
+}
+
+func TestCreateInvalidProperties(t *testing.T) {
+	setupServer()
+	defer tearDownServer()
+
+	for _, q := range []string{
+		"CREATE FREQ marvel",
+		"CREATE FREQ marvel foo=10",
+		"CREATE FREQ marvel errorRate=high",
+		"CREATE RANK marvel ten",
+		"CREATE RANK marvel size=10 halfLife=soon",
+		"CREATE CARD marvel halfLife=60",
+		"CREATE DOM marvel 10 size=10",
+	} {
+		if err := evaluateQuery(q); err == nil {
+			t.Errorf("Expected error evaluating %q, got %v", q, err)
+		}
+	}
+	if res := query(t, "LIST"); len(res) != 0 {
+		t.Error("Expected no sketches, got", res)
+	}
+}
+
+func TestMultiSketchGet(t *testing.T) {
+	setupServer()
+	defer tearDownServer()
+
+	query(t, "CREATE CARD marvel")
+	query(t, "CREATE CARD dc")
+	query(t, "ADD CARD marvel hulk thor hulk")
+	query(t, "ADD CARD dc batman")
+
+	res := query(t, "GET CARD marvel,dc")
+	if len(res) != 2 {
+		t.Fatal("Expected 2 records, got", res)
+	}
+	if res[0]["name"] != "marvel" || res[0]["cardinality"] != 2.0 {
+		t.Error("Expected marvel == 2, got", res[0])
+	}
+	if res[1]["name"] != "dc" || res[1]["cardinality"] != 1.0 {
+		t.Error("Expected dc == 1, got", res[1])
+	}
+	if err := evaluateQuery("GET CARD marvel,x-men"); err == nil {
+		t.Error("Expected error getting a non-existing sketch, got", err)
+	}
+}
+
+func TestDomainCommands(t *testing.T) {
+	setupServer()
+	defer tearDownServer()
+
+	query(t, "CREATE DOM marvel maxUniqueItems=1000 size=10")
+	if res := query(t, "ADD DOM marvel hulk thor hulk"); len(res) != 4 {
+		t.Error("Expected 4 add results, got", res)
+	}
+	if res := query(t, "INFO DOM marvel"); len(res) != 4 {
+		t.Error("Expected 4 sketches, got", res)
+	}
+	if res := query(t, "GET FREQ marvel hulk"); len(res) != 1 || res[0]["hits"] != 2.0 {
+		t.Error("Expected hulk == 2, got", res)
+	}
+	query(t, "CREATE DOM trending halfLife=1h")
+	if res := query(t, "INFO RANK trending"); res[0]["halfLife"] != 3600.0 {
+		t.Error("Expected halfLife=3600, got", res[0])
+	}
+	query(t, "DESTROY DOM trending")
+	query(t, "DESTROY DOM marvel")
+	if res := query(t, "LIST DOM"); len(res) != 0 {
+		t.Error("Expected no domains, got", res)
+	}
+}
+
+func TestAddNew(t *testing.T) {
+	setupServer()
+	defer tearDownServer()
+
+	query(t, "CREATE MEMB marvel 1000")
+	res := query(t, "ADDNEW MEMB marvel hulk thor hulk")
+	if len(res) != 3 || res[0]["present"] != false || res[1]["present"] != false || res[2]["present"] != true {
+		t.Error("Expected only the second hulk to be present, got", res)
+	}
+	if res := query(t, "GET MEMB marvel thor"); res[0]["member"] != true {
+		t.Error("Expected thor to be a member, got", res)
+	}
+
+	query(t, "CREATE CARD marvel")
+	if err := evaluateQuery("ADDNEW CARD marvel hulk"); err == nil {
+		t.Error("Expected error on a CARD sketch, got", err)
+	}
+}
+
+func TestRankPage(t *testing.T) {
+	setupServer()
+	defer tearDownServer()
+
+	query(t, "CREATE RANK marvel 10")
+	query(t, "ADD RANK marvel hulk hulk hulk thor thor loki")
+	if res := query(t, "GET RANK marvel"); len(res) != 3 {
+		t.Error("Expected 3 rankings, got", res)
+	}
+	res := query(t, "GET RANK marvel 1 1")
+	if len(res) != 1 || res[0]["value"] != "thor" || res[0]["rank"] != 2.0 {
+		t.Error("Expected only thor, got", res)
+	}
+	if err := evaluateQuery("GET RANK marvel ten"); err == nil {
+		t.Error("Expected error on an invalid limit, got", err)
+	}
+}
+
+func TestRange(t *testing.T) {
+	setupServer()
+	defer tearDownServer()
+
+	query(t, "CREATE RANGE latency minValue=-10 maxValue=100")
+	if res := query(t, "INFO RANGE latency"); res[0]["minValue"] != -10.0 || res[0]["maxValue"] != 100.0 {
+		t.Error("Expected minValue=-10 maxValue=100, got", res[0])
+	}
+	query(t, "ADD RANGE latency -5 10 20 30")
+	res := query(t, "GET RANGE latency 0 25")
+	if len(res) != 1 || res[0]["count"] != 2.0 || res[0]["lo"] != 0.0 || res[0]["hi"] != 25.0 {
+		t.Error("Expected 2 values in [0, 25], got", res)
+	}
+	for _, q := range []string{
+		"GET RANGE latency 0",
+		"GET RANGE latency zero 25",
+		"GET RANGE latency 25 0",
+	} {
+		if err := evaluateQuery(q); err == nil {
+			t.Errorf("Expected error evaluating %q, got %v", q, err)
+		}
+	}
+	if res := query(t, "ADD RANGE latency slow"); res[0]["status"] != "ERROR" || res[0]["error"] != `Value "slow" is not an integer` {
+		t.Error("Expected slow to be rejected, got", res)
+	}
+}
+
+func TestCardinalityExpression(t *testing.T) {
+	setupServer()
+	defer tearDownServer()
+
+	query(t, "CREATE DOM marvel")
+	query(t, "ADD DOM marvel hulk thor wolverine")
+	query(t, "CREATE CARD x-men")
+	query(t, "ADD CARD x-men wolverine storm")
+	tests := []struct {
+		query    string
+		expected float64
+	}{
+		{"GET CARD marvel | x-men", 4},
+		{"GET CARD marvel & x-men", 1},
+		{"GET CARD dom:marvel - x-men", 2},
+		{"GET CARD (marvel - x-men) | (x-men - marvel)", 3},
+	}
+	for _, test := range tests {
+		if res := query(t, test.query); len(res) != 1 || res[0]["cardinality"] != test.expected {
+			t.Errorf("Expected %q == %v, got %v", test.query, test.expected, res)
+		}
+	}
+	for _, q := range []string{
+		"GET CARD (marvel | x-men",
+		"GET CARD marvel | | x-men",
+		"GET CARD marvel &",
+		"GET CARD marvel & dom:x-men",
+	} {
+		if err := evaluateQuery(q); err == nil {
+			t.Errorf("Expected error evaluating %q, got %v", q, err)
+		}
+	}
+}
+
+func TestTheta(t *testing.T) {
+	setupServer()
+	defer tearDownServer()
+
+	query(t, "CREATE THETA marvel 1024")
+	if res := query(t, "INFO THETA marvel"); res[0]["size"] != 1024.0 {
+		t.Error("Expected size=1024, got", res[0])
+	}
+	query(t, "ADD THETA marvel hulk thor wolverine")
+	query(t, "CREATE THETA x-men size=1024")
+	query(t, "ADD THETA x-men wolverine storm")
+	if res := query(t, "GET THETA marvel"); len(res) != 1 || res[0]["cardinality"] != 3.0 {
+		t.Error("Expected 3 avengers, got", res)
+	}
+	if res := query(t, "GET THETA marvel & x-men"); len(res) != 1 || res[0]["cardinality"] != 1.0 {
+		t.Error("Expected 1 avenger in the x-men, got", res)
+	}
+	if err := evaluateQuery("GET CARD marvel & x-men"); err == nil {
+		t.Error("Expected error on THETA sketches in a CARD expression, got", err)
+	}
+}
+
+func TestMinHash(t *testing.T) {
+	setupServer()
+	defer tearDownServer()
+
+	query(t, "CREATE MINHASH marvel 64")
+	if res := query(t, "INFO MINHASH marvel"); res[0]["size"] != 64.0 {
+		t.Error("Expected size=64, got", res[0])
+	}
+	query(t, "ADD MINHASH marvel hulk thor wolverine")
+	query(t, "CREATE MINHASH avengers size=64")
+	query(t, "ADD MINHASH avengers hulk thor wolverine")
+	query(t, "CREATE MINHASH x-men size=64")
+	query(t, "ADD MINHASH x-men storm cyclops")
+	if res := query(t, "GET MINHASH marvel limit=1"); len(res) != 1 ||
+		res[0]["name"] != "avengers" || res[0]["similarity"] != 1.0 {
+		t.Error("Expected avengers to be identical, got", res)
+	}
+	if res := query(t, "GET MINHASH marvel x-men min=0.5"); len(res) != 0 {
+		t.Error("Expected x-men not to be similar, got", res)
+	}
+	if err := evaluateQuery("GET MINHASH marvel lsh=maybe"); err == nil {
+		t.Error("Expected error on an invalid lsh option, got", err)
+	}
+}
+
+func TestSample(t *testing.T) {
+	setupServer()
+	defer tearDownServer()
+
+	query(t, "CREATE SAMP marvel 2")
+	query(t, "ADD SAMP marvel hulk thor wolverine")
+	if res := query(t, "GET SAMP marvel"); len(res) != 2 || res[0]["count"] != 3.0 {
+		t.Error("Expected 2 of 3 values, got", res)
+	}
+	query(t, "CREATE SAMP heroes size=2 weighted=true")
+	if res := query(t, "INFO SAMP heroes"); res[0]["weighted"] != true {
+		t.Error("Expected weighted=true, got", res[0])
+	}
+	query(t, "ADD SAMP heroes hulk:1 thor:2")
+	if res := query(t, "GET SAMP heroes"); len(res) != 2 || res[0]["weight"] == nil {
+		t.Error("Expected 2 weighted values, got", res)
+	}
+	if err := evaluateQuery("CREATE CARD marvel weighted=true"); err == nil {
+		t.Error("Expected error on a weighted CARD sketch, got", err)
+	}
+}
+
+func TestGroups(t *testing.T) {
+	setupServer()
+	defer tearDownServer()
+
+	query(t, "CREATE KCARD pages")
+	query(t, "ADD KCARD pages /home neil seif martin")
+	query(t, "ADD KCARD pages /pricing neil")
+	if res := query(t, "GET KCARD pages /pricing /home"); len(res) != 2 ||
+		res[0]["key"] != "/pricing" || res[1]["cardinality"] != 3.0 {
+		t.Error("Expected 1 visitor of /pricing and 3 of /home, got", res)
+	}
+	if res := query(t, "GET KCARD pages limit=1"); len(res) != 1 || res[0]["key"] != "/home" {
+		t.Error("Expected /home to be the top group, got", res)
+	}
+	query(t, "CREATE KRANK browsers 10")
+	query(t, "ADD KRANK browsers /home chrome firefox chrome")
+	if res := query(t, "GET KRANK browsers /home"); len(res) != 2 || res[0]["value"] != "chrome" {
+		t.Error("Expected chrome to rank first on /home, got", res)
+	}
+	if err := evaluateQuery("ADD KCARD pages /home"); err == nil {
+		t.Error("Expected error on a group key without values, got", err)
+	}
+}
+
+func TestSnapshotStatus(t *testing.T) {
+	setupServer()
+	defer tearDownServer()
+
+	if res := query(t, "SAVE STATUS"); len(res) != 1 || res[0]["status"] != "FAILED" {
+		t.Error("Expected FAILED, got", res)
+	}
+}
+
+func TestPrintCSV(t *testing.T) {
+	buf.Reset()
+	stdout = &buf
+	output = csvOutput
+	defer func() { output = tableOutput }()
+
+	printRecords([]record{
+		{{"Type", "CARD"}, {"Cardinality", 2}},
+		{{"Type", "MEMB"}, {"Value", "hulk"}, {"Member", true}},
+	})
+	expected := "type,cardinality,value,member\nCARD,2,,\nMEMB,,hulk,true\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestLoad(t *testing.T) {
+	setupServer()
+	defer tearDownServer()
+
+	dir, err := ioutil.TempDir("", "skizze_load_test")
+	if err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	plain := filepath.Join(dir, "heroes.txt")
+	lines := "hulk\nthor\nhulk\n\niron man\nloki"
+	if err := ioutil.WriteFile(plain, []byte(lines), 0644); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+
+	query(t, "CREATE CARD marvel")
+	if res := query(t, "LOAD CARD marvel "+plain+" --batch 2"); res[0]["values"] != 5.0 || res[0]["batches"] != 3.0 {
+		t.Error("Expected 5 values in 3 batches, got", res)
+	} else if res[0]["offset"] != float64(len(lines)) {
+		t.Errorf("Expected offset %d, got %v", len(lines), res[0]["offset"])
+	}
+	if res := query(t, "GET CARD marvel"); res[0]["cardinality"] != 4.0 {
+		t.Error("Expected cardinality 4, got", res)
+	}
+
+	// Resume after "hulk\nthor\n"
+	query(t, "CREATE RANK marvel 10")
+	if res := query(t, "LOAD RANK marvel "+plain+" --offset 10"); res[0]["values"] != 3.0 {
+		t.Error("Expected 3 values, got", res)
+	}
+	if res := query(t, "GET RANK marvel"); len(res) != 3 {
+		t.Error("Expected 3 rankings, got", res)
+	}
+
+	// Second column of a gzip compressed file
+	compressed := filepath.Join(dir, "heroes.csv.gz")
+	f, err := os.Create(compressed)
+	if err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	gz := gzip.NewWriter(f)
+	_, _ = gz.Write([]byte("1;hulk\n2;thor\n3;hulk\n"))
+	_ = gz.Close()
+	_ = f.Close()
+
+	query(t, "CREATE DOM dc")
+	if res := query(t, "LOAD DOM dc "+compressed+" --column 2 --delimiter ;"); res[0]["values"] != 3.0 {
+		t.Error("Expected 3 values, got", res)
+	}
+	if res := query(t, "GET FREQ dc hulk"); res[0]["hits"] != 2.0 {
+		t.Error("Expected hulk == 2, got", res)
+	}
+
+	// A batch the RANGE sketch rejects is added to the other sketches of the
+	// domain, each sketch resumes from its own offset
+	numbers := filepath.Join(dir, "numbers.txt")
+	if err := ioutil.WriteFile(numbers, []byte("1\n2\nthree\n"), 0644); err != nil {
+		t.Fatal("Expected no errors, got", err)
+	}
+	query(t, "CREATE DOM counts")
+	query(t, "ATTACH DOM counts RANGE")
+	err = evaluateQuery("LOAD DOM counts " + numbers + " --batch 2")
+	if err == nil || !strings.Contains(err.Error(), "LOAD CARD counts "+numbers+" --offset 10") ||
+		!strings.Contains(err.Error(), "LOAD RANGE counts "+numbers+" --offset 4") {
+		t.Error("Expected error with the offset of each sketch, got", err)
+	}
+
+	err = evaluateQuery("LOAD CARD x-men " + plain)
+	if err == nil || !strings.Contains(err.Error(), "--offset 0") {
+		t.Error("Expected error with resume offset, got", err)
+	}
+	if err := evaluateQuery("LOAD CARD marvel " + plain + " --batch"); err == nil {
+		t.Error("Expected error (missing option value), got", err)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	setupServer()
+	defer tearDownServer()
+
+	query(t, "CREATE CARD users")
+	query(t, "CREATE CARD userbase")
+	query(t, "CREATE RANK users 10")
+	query(t, "CREATE DOM usage")
+	names.fetched = time.Time{}
+
+	// The domain brings a CARD sketch named after it
+	expected := []string{"GET CARD usage", "GET CARD userbase", "GET CARD users"}
+	if c := complete("GET CARD us"); strings.Join(c, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected %v, got %v", expected, c)
+	}
+	if c := complete("GET CARD users,userb"); len(c) != 1 || c[0] != "GET CARD users,userbase" {
+		t.Error("Expected [GET CARD users,userbase], got", c)
+	}
+	if c := complete("INFO DOM "); len(c) != 1 || c[0] != "INFO DOM usage" {
+		t.Error("Expected [INFO DOM usage], got", c)
+	}
+	if c := complete("get ca"); len(c) != 1 || c[0] != "get card" {
+		t.Error("Expected [get card], got", c)
+	}
+
+	// Names are cached, new ones show up once the cache expired
+	query(t, "CREATE CARD usual")
+	if c := complete("GET CARD usu"); len(c) != 0 {
+		t.Error("Expected no completions, got", c)
+	}
+	names.fetched = time.Time{}
+	if c := complete("GET CARD usu"); len(c) != 1 {
+		t.Error("Expected [GET CARD usual], got", c)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	setupServer()
+	defer tearDownServer()
+
+	query(t, "CREATE CARD users")
+	query(t, "ADD CARD users neil seif")
+
+	buf.Reset()
+	stop := make(chan os.Signal, 1)
+	go func() {
+		time.Sleep(25 * time.Millisecond)
+		stop <- os.Interrupt
+	}()
+	if err := watch(10*time.Millisecond, "GET CARD users", stop); err != nil {
+		t.Error("Expected no errors, got", err)
+	}
+	if n := strings.Count(buf.String(), `"cardinality":2`); n < 2 {
+		t.Error("Expected the query to run at least twice, got", n)
+	}
+
+	if err := evaluateQuery("WATCH 1 ADD CARD users neil"); err == nil {
+		t.Error("Expected error watching ADD, got", err)
+	}
+	if err := watch(time.Millisecond, "GET CARD x-men", stop); err == nil {
+		t.Error("Expected error watching a non-existing sketch, got", err)
+	}
+}
@@ -0,0 +1,109 @@
+package bridge
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"datamodel"
+	pb "datamodel/protobuf"
+)
+
+// completionTTL is how long fetched sketch and domain names are reused
+const completionTTL = 5 * time.Second
+
+// nameCache keeps the sketch and domain names known by the server
+type nameCache struct {
+	sync.Mutex
+	fetched  time.Time
+	sketches []*pb.Sketch
+	domains  []string
+}
+
+var names = &nameCache{}
+
+func (c *nameCache) refresh() {
+	if time.Since(c.fetched) < completionTTL {
+		return
+	}
+	if reply, err := client.ListAll(context.Background(), &pb.Empty{}); err == nil {
+		c.sketches = reply.GetSketches()
+	}
+	if reply, err := client.ListDomains(context.Background(), &pb.Empty{}); err == nil {
+		c.domains = reply.GetNames()
+	}
+	c.fetched = time.Now()
+}
+
+// get returns the names of the domains if kind is "dom", or the names of the
+// sketches of type kind
+func (c *nameCache) get(kind string) []string {
+	c.Lock()
+	defer c.Unlock()
+	c.refresh()
+
+	if kind == "dom" {
+		return c.domains
+	}
+	t, ok := datamodel.LookupName(kind)
+	if !ok {
+		return nil
+	}
+	var res []string
+	for _, v := range c.sketches {
+		if v.GetType() == t.Type {
+			res = append(res, v.GetName())
+		}
+	}
+	return res
+}
+
+// commands returns every command keyword, including the ones for each
+// registered sketch type
+func commands() []string {
+	cmds := append([]string{}, completion...)
+	for _, t := range datamodel.Types() {
+		for _, cmd := range sketchCommands {
+			cmds = append(cmds, cmd+" "+t.Name)
+		}
+		if t.Can(datamodel.AddIfAbsentQuery) {
+			cmds = append(cmds, "addnew "+t.Name)
+		}
+	}
+	return cmds
+}
+
+// complete returns the keywords starting with line, or once the command and
+// type are typed the matching sketch or domain names. Names in a comma
+// separated list, as taken by GET, are completed one at a time.
+func complete(line string) (c []string) {
+	for _, n := range commands() {
+		if strings.HasPrefix(n, strings.ToLower(line)) {
+			c = append(c, n)
+		}
+	}
+
+	fields := getFields(line)
+	partial := ""
+	if len(fields) == 3 && !strings.HasSuffix(line, " ") {
+		partial = fields[2]
+	} else if len(fields) != 2 || !strings.HasSuffix(line, " ") {
+		return
+	}
+	if strings.ToLower(fields[0]) == "create" {
+		return
+	}
+	if i := strings.LastIndex(partial, ","); i >= 0 {
+		partial = partial[i+1:]
+	}
+
+	prefix := line[:len(line)-len(partial)]
+	for _, name := range names.get(strings.ToLower(fields[1])) {
+		if strings.HasPrefix(name, partial) {
+			c = append(c, prefix+name)
+		}
+	}
+	return
+}
@@ -7,57 +7,77 @@ import (
 
 	"golang.org/x/net/context"
 
+	"datamodel"
 	pb "datamodel/protobuf"
 
 	"github.com/gogo/protobuf/proto"
 )
 
+// createDomain creates a domain with one sketch of every type that is not
+// optional. The
+// properties of the sketches are either given as key=value pairs or as the
+// positional <maxUniqueItems> <size> pair, the server defaults are used if
+// none are given.
 func createDomain(fields []string, in *pb.Domain) error {
-	if len(fields) != 5 {
-		return fmt.Errorf("Expected 5 arguments got %d", len(fields))
-	}
-
-	// FIXME make last 2 arguments optional
-	capa, err := strconv.Atoi(fields[3])
-	if err != nil {
-		return fmt.Errorf("Expected 3rd argument to be of type int: %q", err)
-	}
-
-	size, err := strconv.Atoi(fields[4])
-	if err != nil {
-		return fmt.Errorf("Expected last argument to be of type int: %q", err)
+	props := &pb.SketchProperties{}
+	args := fields[3:]
+	if len(args) == 2 && !strings.Contains(args[0], "=") && !strings.Contains(args[1], "=") {
+		capa, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("Expected 3rd argument to be of type int: %q", err)
+		}
+		size, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("Expected last argument to be of type int: %q", err)
+		}
+		props.MaxUniqueItems = proto.Int64(int64(capa))
+		props.Size = proto.Int64(int64(size))
+	} else {
+		for _, arg := range args {
+			if !strings.Contains(arg, "=") {
+				return fmt.Errorf("Expected property of the form key=value, got %s", arg)
+			}
+		}
+		var err error
+		if props, err = parseProperties(args, pb.SketchType_CARD); err != nil {
+			return err
+		}
 	}
 
-	types := []pb.SketchType{pb.SketchType_MEMB, pb.SketchType_FREQ, pb.SketchType_RANK, pb.SketchType_CARD}
-	for _, ty := range types {
+	for _, t := range datamodel.Types() {
+		if t.Optional {
+			continue
+		}
+		typ := t.Type
 		sketch := &pb.Sketch{}
-		sketch.Name = proto.String("")
-		sketch.Type = &ty
-		sketch.Properties = &pb.SketchProperties{
-			Size:           proto.Int64(int64(size)),
-			MaxUniqueItems: proto.Int64(int64(capa)),
+		sketch.Name = proto.String(in.GetName())
+		sketch.Type = &typ
+		sketch.Properties = proto.Clone(props).(*pb.SketchProperties)
+		// A halfLife only applies to the sketches that decay
+		if !t.Can(datamodel.Decay) {
+			sketch.Properties.HalfLife = nil
 		}
 		in.Sketches = append(in.Sketches, sketch)
 	}
 
-	_, err = client.CreateDomain(context.Background(), in)
+	_, err := client.CreateDomain(context.Background(), in)
 	if err == nil {
-		fmt.Println("done")
+		printDone()
 	}
 	return err
 }
 
 func addToDomain(fields []string, in *pb.Domain) error {
 	if len(fields) < 4 {
-		return fmt.Errorf("Expected at least 4 values, got %q", len(fields))
+		return fmt.Errorf("Expected at least 4 values, got %d", len(fields))
 	}
 	addRequest := &pb.AddRequest{
 		Domain: in,
 		Values: fields[3:],
 	}
-	_, err := client.Add(context.Background(), addRequest)
+	reply, err := client.Add(context.Background(), addRequest)
 	if err == nil {
-		fmt.Println("done")
+		printAddReply(reply)
 	}
 	return err
 }
@@ -73,10 +93,24 @@ func sendDomainRequest(fields []string) error {
 		return createDomain(fields, in)
 	case "add":
 		return addToDomain(fields, in)
+	case "load":
+		return loadFile(fields, &pb.AddRequest{Domain: in})
 	case "destroy":
 		return deleteDomain(fields, in)
 	case "info":
 		return getDomainInfo(fields, in)
+	case "query":
+		return queryDomain(fields, in)
+	case "attach":
+		return attachSketch(fields, in)
+	case "detach":
+		return detachSketch(fields, in)
+	case "clear":
+		return clearDomain(fields, in)
+	case "rename":
+		return renameDomain(fields, in)
+	case "clone":
+		return cloneDomain(fields, in)
 	default:
 		return fmt.Errorf("unkown operation: %s", fields[0])
 	}
@@ -85,29 +119,169 @@ func sendDomainRequest(fields []string) error {
 func listDomains() error {
 	reply, err := client.ListDomains(context.Background(), &pb.Empty{})
 	if err == nil {
-		for _, v := range reply.GetNames() {
-			_, _ = fmt.Fprintln(w, fmt.Sprintf("Name: %s\t", v))
+		records := make([]record, len(reply.GetNames()))
+		for i, v := range reply.GetNames() {
+			records[i] = record{{"Name", v}}
 		}
-		_ = w.Flush()
+		printRecords(records)
 	}
 	return err
 }
 
 func deleteDomain(fields []string, in *pb.Domain) error {
 	_, err := client.DeleteDomain(context.Background(), in)
+	if err == nil {
+		printDone()
+	}
+	return err
+}
+
+func clearDomain(fields []string, in *pb.Domain) error {
+	if len(fields) != 3 {
+		return fmt.Errorf("Expected 3 arguments got %d", len(fields))
+	}
+	_, err := client.ClearDomain(context.Background(), in)
+	if err == nil {
+		printDone()
+	}
 	return err
 }
 
+func renameDomain(fields []string, in *pb.Domain) error {
+	if len(fields) != 4 {
+		return fmt.Errorf("Expected 4 arguments got %d", len(fields))
+	}
+	req := &pb.DomainNameRequest{
+		Domain:  in,
+		NewName: proto.String(fields[3]),
+	}
+	dom, err := client.RenameDomain(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	printDomain(dom)
+	return nil
+}
+
+func cloneDomain(fields []string, in *pb.Domain) error {
+	if len(fields) != 4 {
+		return fmt.Errorf("Expected 4 arguments got %d", len(fields))
+	}
+	req := &pb.DomainNameRequest{
+		Domain:  in,
+		NewName: proto.String(fields[3]),
+	}
+	dom, err := client.CloneDomain(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	printDomain(dom)
+	return nil
+}
+
 func getDomainInfo(fields []string, in *pb.Domain) error {
 	dom, err := client.GetDomain(context.Background(), in)
 	if err != nil {
 		return err
 	}
-	_, _ = fmt.Fprintln(w, fmt.Sprintf("Name: %s  Type: %s\t", dom.GetName(), ""))
-	_, _ = fmt.Fprintln(w, fmt.Sprintf("%d Sketches attached:", len(dom.GetSketches())))
+	printDomain(dom)
+	return nil
+}
+
+func queryDomain(fields []string, in *pb.Domain) error {
+	req := &pb.QueryDomainRequest{
+		Name:   in.Name,
+		Values: fields[3:],
+	}
+	reply, err := client.QueryDomain(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	var records []record
+	for _, res := range reply.GetResults() {
+		var results []record
+		if res.Cardinality != nil {
+			results = append(results, cardinalityRecords(res.GetCardinality())...)
+		}
+		if res.Rankings != nil {
+			results = append(results, rankingsRecords(res.GetRankings(), 0)...)
+		}
+		if res.Frequency != nil {
+			results = append(results, frequencyRecords(res.GetFrequency())...)
+		}
+		if res.Membership != nil {
+			results = append(results, membershipRecords(res.GetMembership())...)
+		}
+		if res.Sample != nil {
+			results = append(results, sampleRecords(res.GetSample())...)
+		}
+		for _, r := range results {
+			records = append(records, append(record{{"Type", datamodel.TypeName(res.GetSketch().GetType())}}, r...))
+		}
+	}
+	printRecords(records)
+	return nil
+}
+
+func printDomain(dom *pb.Domain) {
+	records := make([]record, len(dom.GetSketches()))
 	for i, v := range dom.GetSketches() {
-		_, _ = fmt.Fprintln(w, fmt.Sprintf("  %d.  Name: %s  Type: %s\t", i+1, v.GetName(), v.GetType()))
+		records[i] = record{
+			{"Domain", dom.GetName()},
+			{"Name", v.GetName()},
+			{"Type", datamodel.TypeName(v.GetType())},
+		}
 	}
-	_ = w.Flush()
-	return err
+	printRecords(records)
+}
+
+func domainSketch(fields []string, in *pb.Domain) (*pb.Sketch, error) {
+	if len(fields) != 4 && len(fields) != 5 {
+		return nil, fmt.Errorf("Expected 4 or 5 arguments got %d", len(fields))
+	}
+	t, ok := datamodel.LookupName(fields[3])
+	if !ok {
+		return nil, fmt.Errorf("Invalid sketch type: %s", fields[3])
+	}
+	typ := t.Type
+	sketch := &pb.Sketch{
+		Name: proto.String(in.GetName()),
+		Type: &typ,
+	}
+	if len(fields) == 5 {
+		sketch.Name = proto.String(fields[4])
+	}
+	return sketch, nil
+}
+
+func attachSketch(fields []string, in *pb.Domain) error {
+	sketch, err := domainSketch(fields, in)
+	if err != nil {
+		return err
+	}
+	dom, err := client.AttachSketch(context.Background(), &pb.AttachSketchRequest{
+		Domain: in.Name,
+		Sketch: sketch,
+	})
+	if err != nil {
+		return err
+	}
+	printDomain(dom)
+	return nil
+}
+
+func detachSketch(fields []string, in *pb.Domain) error {
+	sketch, err := domainSketch(fields, in)
+	if err != nil {
+		return err
+	}
+	dom, err := client.DetachSketch(context.Background(), &pb.DetachSketchRequest{
+		Domain: in.Name,
+		Sketch: sketch,
+	})
+	if err != nil {
+		return err
+	}
+	printDomain(dom)
+	return nil
 }
@@ -0,0 +1,182 @@
+package bridge
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	pb "datamodel/protobuf"
+
+	"github.com/gogo/protobuf/proto"
+)
+
+// Operators of cardinality expressions, from the loosest binding up. Union
+// and difference bind alike and apply from left to right.
+var setOperators = map[string]pb.SetOperation{
+	"|": pb.SetOperation_UNION,
+	"-": pb.SetOperation_DIFFERENCE,
+	"&": pb.SetOperation_INTERSECTION,
+}
+
+// domainPrefix marks an operand naming a domain instead of a sketch
+const domainPrefix = "dom:"
+
+// tokenizeExpression splits the fields of an expression into names,
+// operators and parentheses. Operators must stand apart, so that names may
+// contain dashes.
+func tokenizeExpression(fields []string) []string {
+	var tokens []string
+	for _, f := range fields {
+		for strings.HasPrefix(f, "(") {
+			tokens = append(tokens, "(")
+			f = f[1:]
+		}
+		closing := 0
+		for strings.HasSuffix(f, ")") {
+			closing++
+			f = f[:len(f)-1]
+		}
+		if len(f) > 0 {
+			tokens = append(tokens, f)
+		}
+		for ; closing > 0; closing-- {
+			tokens = append(tokens, ")")
+		}
+	}
+	return tokens
+}
+
+// isExpression tells whether the fields of a GET CARD query form an
+// expression rather than a list of sketch names
+func isExpression(fields []string) bool {
+	for _, t := range tokenizeExpression(fields) {
+		if _, ok := setOperators[t]; ok || t == "(" || t == ")" {
+			return true
+		}
+	}
+	return false
+}
+
+type expressionParser struct {
+	tokens []string
+	pos    int
+	// typ is the type of the sketches named by the expression
+	typ pb.SketchType
+}
+
+func (p *expressionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// parseExpression reads an expression such as "(home | pricing) - dom:blog"
+// over sketches of type typ
+func parseExpression(fields []string, typ pb.SketchType) (*pb.CardinalityExpression, error) {
+	p := &expressionParser{tokens: tokenizeExpression(fields), typ: typ}
+	expr, err := p.parseUnion()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("Unexpected %q in expression", p.peek())
+	}
+	return expr, nil
+}
+
+// apply adds operand to expr if expr already applies op, so that chains of
+// the same operator end up in a single node. Operators apply from left to
+// right, which makes it hold for a - b - c too.
+func apply(op pb.SetOperation, expr, operand *pb.CardinalityExpression) *pb.CardinalityExpression {
+	if expr.Op != nil && expr.GetOp() == op {
+		expr.Operands = append(expr.Operands, operand)
+		return expr
+	}
+	return &pb.CardinalityExpression{Op: &op, Operands: []*pb.CardinalityExpression{expr, operand}}
+}
+
+func (p *expressionParser) parseUnion() (*pb.CardinalityExpression, error) {
+	expr, err := p.parseIntersection()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := setOperators[p.peek()]
+		if !ok || op == pb.SetOperation_INTERSECTION {
+			return expr, nil
+		}
+		p.pos++
+		operand, err := p.parseIntersection()
+		if err != nil {
+			return nil, err
+		}
+		expr = apply(op, expr, operand)
+	}
+}
+
+func (p *expressionParser) parseIntersection() (*pb.CardinalityExpression, error) {
+	expr, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	for setOperators[p.peek()] == pb.SetOperation_INTERSECTION {
+		p.pos++
+		operand, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		expr = apply(pb.SetOperation_INTERSECTION, expr, operand)
+	}
+	return expr, nil
+}
+
+func (p *expressionParser) parseOperand() (*pb.CardinalityExpression, error) {
+	token := p.peek()
+	p.pos++
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("Unexpected end of expression")
+	case token == "(":
+		expr, err := p.parseUnion()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("Missing closing parenthesis in expression")
+		}
+		p.pos++
+		return expr, nil
+	case token == ")":
+		return nil, fmt.Errorf("Unexpected %q in expression", token)
+	}
+	if _, ok := setOperators[token]; ok {
+		return nil, fmt.Errorf("Unexpected operator %q in expression", token)
+	}
+	if strings.HasPrefix(strings.ToLower(token), domainPrefix) {
+		return &pb.CardinalityExpression{Domain: proto.String(token[len(domainPrefix):])}, nil
+	}
+	typ := p.typ
+	return &pb.CardinalityExpression{Sketch: &pb.Sketch{Name: proto.String(token), Type: &typ}}, nil
+}
+
+// getCardinalityExpression answers GET CARD and GET THETA with an expression
+// over sketches of that type and domains
+func getCardinalityExpression(fields []string, typ pb.SketchType) error {
+	expr, err := parseExpression(fields, typ)
+	if err != nil {
+		return err
+	}
+	reply, err := client.GetCardinalityExpression(context.Background(),
+		&pb.CardinalityExpressionRequest{Expression: expr, Type: &typ})
+	if err != nil {
+		return err
+	}
+	records := cardinalityRecords(reply.GetResult())
+	if reply.Warning != nil {
+		records[0] = append(records[0], field{"Warning", reply.GetWarning()})
+	}
+	printRecords(records)
+	return nil
+}
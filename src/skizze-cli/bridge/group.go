@@ -0,0 +1,71 @@
+package bridge
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	pb "datamodel/protobuf"
+
+	"github.com/gogo/protobuf/proto"
+)
+
+// addToGroup adds values to a single group of a keyed sketch, the key
+// follows the name of the sketch
+func addToGroup(fields []string, in *pb.Sketch) error {
+	if len(fields) < 5 {
+		return fmt.Errorf("Expected a group key and at least 1 value, got %d values", len(fields)-3)
+	}
+	values := fields[4:]
+	keys := make([]string, len(values))
+	for i := range keys {
+		keys[i] = fields[3]
+	}
+	reply, err := client.Add(context.Background(), &pb.AddRequest{
+		Sketch: in,
+		Keys:   keys,
+		Values: values,
+	})
+	if err == nil {
+		printAddReply(reply)
+	}
+	return err
+}
+
+// getGroups answers GET KCARD and GET KRANK for the given group keys, or
+// lists the top groups if there are none
+func getGroups(fields []string, typ pb.SketchType) error {
+	req := &pb.GroupsRequest{Sketch: &pb.Sketch{Name: proto.String(fields[2]), Type: &typ}}
+	for _, arg := range fields[3:] {
+		if !strings.HasPrefix(strings.ToLower(arg), "limit=") {
+			req.Keys = append(req.Keys, arg)
+			continue
+		}
+		num, err := strconv.ParseInt(arg[len("limit="):], 10, 32)
+		if err != nil || num < 0 {
+			return fmt.Errorf("Invalid limit %q, expected a positive integer", arg[len("limit="):])
+		}
+		req.Limit = proto.Int32(int32(num))
+	}
+	reply, err := client.GetGroups(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	var records []record
+	for _, res := range reply.GetResults() {
+		var results []record
+		if res.Cardinality != nil {
+			results = cardinalityRecords(res.GetCardinality())
+		}
+		if res.Rankings != nil {
+			results = rankingsRecords(res.GetRankings(), 0)
+		}
+		for _, r := range results {
+			records = append(records, append(record{{"Key", res.GetKey()}}, r...))
+		}
+	}
+	printRecords(records)
+	return nil
+}
@@ -0,0 +1,262 @@
+package bridge
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"datamodel"
+	pb "datamodel/protobuf"
+)
+
+var progress io.Writer = os.Stderr
+
+// loadOptions are the arguments of LOAD, offset is counted in bytes of the
+// decompressed content
+type loadOptions struct {
+	file      string
+	column    int
+	delimiter rune
+	batch     int
+	offset    int64
+}
+
+func parseLoadOptions(args []string) (*loadOptions, error) {
+	if len(args) == 0 {
+		return nil, errors.New("Expected a file to load")
+	}
+	opts := &loadOptions{file: args[0], delimiter: ',', batch: 10000}
+	delimiter := false
+	for i := 1; i < len(args); i += 2 {
+		if i+1 >= len(args) {
+			return nil, fmt.Errorf("Expected a value for %s", args[i])
+		}
+		value := args[i+1]
+		switch strings.ToLower(args[i]) {
+		case "--column":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("Expected --column to be a positive int, got %s", value)
+			}
+			opts.column = n
+		case "--delimiter":
+			switch strings.ToLower(value) {
+			case "tab", `\t`:
+				opts.delimiter = '\t'
+			default:
+				if len([]rune(value)) != 1 {
+					return nil, fmt.Errorf("Expected --delimiter to be a single character, got %s", value)
+				}
+				opts.delimiter = []rune(value)[0]
+			}
+			delimiter = true
+		case "--batch":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("Expected --batch to be a positive int, got %s", value)
+			}
+			opts.batch = n
+		case "--offset":
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("Expected --offset to be a positive int, got %s", value)
+			}
+			opts.offset = n
+		default:
+			return nil, fmt.Errorf("Unknown option %s", args[i])
+		}
+	}
+	// A delimiter only makes sense for columns, default to the first one
+	if delimiter && opts.column == 0 {
+		opts.column = 1
+	}
+	return opts, nil
+}
+
+// value extracts the value to add from a line of the file. Without a column
+// the whole line is the value.
+func (opts *loadOptions) value(line string) (string, error) {
+	line = strings.TrimRight(line, "\r\n")
+	if opts.column == 0 || len(line) == 0 {
+		return line, nil
+	}
+	r := csv.NewReader(strings.NewReader(line))
+	r.Comma = opts.delimiter
+	r.LazyQuotes = true
+	rec, err := r.Read()
+	if err != nil {
+		return "", err
+	}
+	if len(rec) < opts.column {
+		return "", fmt.Errorf("Expected at least %d columns, got %d", opts.column, len(rec))
+	}
+	return rec[opts.column-1], nil
+}
+
+// openLoadFile opens a plain or gzip compressed file positioned at offset
+func openLoadFile(fn string, offset int64) (*bufio.Reader, io.Closer, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, nil, err
+	}
+	br := bufio.NewReader(f)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			_ = f.Close()
+			return nil, nil, err
+		}
+		// gzip streams can not seek, skip the decompressed bytes instead
+		if _, err := io.CopyN(ioutil.Discard, gz, offset); err != nil {
+			_ = f.Close()
+			return nil, nil, err
+		}
+		return bufio.NewReader(gz), f, nil
+	}
+	if offset > 0 {
+		if _, err := f.Seek(offset, 0); err != nil {
+			_ = f.Close()
+			return nil, nil, err
+		}
+		br.Reset(f)
+	}
+	return br, f, nil
+}
+
+// addFailure returns an error for the first sketch the values could not be
+// added to
+func addFailure(reply *pb.AddReply) error {
+	for _, v := range reply.GetResults() {
+		if !v.GetSuccess() {
+			return fmt.Errorf("Could not add to %s %s: %s",
+				v.GetSketch().GetType(), v.GetSketch().GetName(), v.GetError())
+		}
+	}
+	return nil
+}
+
+// partialAddError is returned when a batch was added to some sketches of a
+// domain only, resuming the whole domain would add it twice to the others
+type partialAddError struct {
+	err     error
+	results []*pb.AddResult
+}
+
+func (e *partialAddError) Error() string {
+	return e.err.Error()
+}
+
+// loadFile streams the values of a file into batched Add requests. On
+// failure the offset of the first value that was not added is reported so
+// that the load can be resumed with --offset. When a batch was added to some
+// sketches of a domain only, the offset of each sketch is reported instead.
+func loadFile(fields []string, req *pb.AddRequest) error {
+	opts, err := parseLoadOptions(fields[3:])
+	if err != nil {
+		return err
+	}
+	r, closer, err := openLoadFile(opts.file, opts.offset)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = closer.Close() }()
+
+	start := time.Now()
+	offset, read := opts.offset, opts.offset
+	total, batches := 0, 0
+	values := make([]string, 0, opts.batch)
+
+	rate := func() float64 {
+		secs := time.Since(start).Seconds()
+		if secs == 0 {
+			return 0
+		}
+		return float64(total) / secs
+	}
+	flush := func() error {
+		if len(values) == 0 {
+			offset = read
+			return nil
+		}
+		req.Values = values
+		reply, err := client.Add(context.Background(), req)
+		if err == nil {
+			err = addFailure(reply)
+			if err != nil && req.Domain != nil {
+				err = &partialAddError{err, reply.GetResults()}
+			}
+		}
+		if err != nil {
+			return err
+		}
+		total += len(values)
+		batches++
+		offset = read
+		values = values[:0]
+		_, _ = fmt.Fprintf(progress, "\rLoaded %d values in %d batches (%.0f values/s), offset %d",
+			total, batches, rate(), offset)
+		return nil
+	}
+	resumable := func(err error) error {
+		_, _ = fmt.Fprintln(progress)
+		if e, ok := err.(*partialAddError); ok {
+			resumes := make([]string, len(e.results))
+			for i, res := range e.results {
+				at := offset
+				if res.GetSuccess() {
+					at = read
+				}
+				resumes[i] = fmt.Sprintf("LOAD %s %s %s --offset %d", datamodel.TypeName(res.GetSketch().GetType()),
+					res.GetSketch().GetName(), opts.file, at)
+			}
+			return fmt.Errorf("%s, resume the sketches one by one with the same options: %s",
+				e.err.Error(), strings.Join(resumes, ", "))
+		}
+		return fmt.Errorf("%s, resume with --offset %d", err.Error(), offset)
+	}
+
+	for {
+		line, rerr := r.ReadString('\n')
+		if rerr != nil && rerr != io.EOF {
+			return resumable(rerr)
+		}
+		v, err := opts.value(line)
+		if err != nil {
+			return resumable(fmt.Errorf("Invalid line at offset %d: %s", read, err.Error()))
+		}
+		read += int64(len(line))
+		if len(v) > 0 {
+			values = append(values, v)
+		}
+		if len(values) == opts.batch || rerr == io.EOF {
+			if err := flush(); err != nil {
+				return resumable(err)
+			}
+		}
+		if rerr == io.EOF {
+			break
+		}
+	}
+	if batches > 0 {
+		_, _ = fmt.Fprintln(progress)
+	}
+
+	printRecords([]record{{
+		{"Values", total},
+		{"Batches", batches},
+		{"Offset", offset},
+		{"Seconds", time.Since(start).Seconds()},
+		{"Rate", rate()},
+	}})
+	return nil
+}
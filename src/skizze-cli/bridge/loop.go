@@ -1,6 +1,7 @@
 package bridge
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
 	"io"
@@ -18,71 +19,137 @@ import (
 
 	"datamodel"
 	pb "datamodel/protobuf"
+	// Registers the built-in sketch types
+	_ "sketches"
 
 	"github.com/martinpinto/liner"
 )
 
 const helpString = `
-  CREATE DOM  <name> <maxUniqueItems> <rank>  Create a new Domain with options
+  CREATE DOM  <name> [properties...]          Create a new Domain with one Sketch of each type
+  CREATE DOM  <name> <maxUniqueItems> <size>  Create a new Domain with options
   DESTROY DOM <name>                          Destroy a Domain
-
-  CREATE CARD <name>                          Create a Cardinality Sketch
-  CREATE MEMB <name>                          Create a Membership Sketch
-  CREATE FREQ <name>                          Create a Frequency Sketch
-  CREATE RANK <name>                          Create a Rankings Sketch
+  ATTACH DOM <name> <type> [sketch]           Attach a new Sketch of <type> or an existing <sketch> to a Domain
+  DETACH DOM <name> <type> [sketch]           Detach a Sketch from a Domain, keeping it as a standalone Sketch
+  CLEAR DOM <name>                            Reset all Sketches of a Domain, keeping their properties
+  RENAME DOM <name> <newName>                 Rename a Domain and the Sketches named after it
+  CLONE DOM <name> <newName>                  Copy a Domain and its Sketches to a new name
+
+  CREATE CARD <name> [properties...]          Create a Cardinality Sketch
+  CREATE MEMB <name> <properties...>          Create a Membership Sketch
+  CREATE FREQ <name> <properties...>          Create a Frequency Sketch
+  CREATE RANK <name> <properties...>          Create a Rankings Sketch
+  CREATE RANGE <name> <properties...>         Create a Range Sketch of integers
+  CREATE THETA <name> <properties...>         Create a Theta Sketch, a Cardinality Sketch that intersects precisely
+  CREATE SAMP <name> <properties...>          Create a Sample Sketch, keeping a random sample of the values
+  CREATE KCARD <name>                         Create a Keyed Cardinality Sketch, counting distinct values per group key
+  CREATE KRANK <name> <properties...>         Create a Keyed Rankings Sketch, ranking values per group key
+  CREATE MINHASH <name> <properties...>       Create a MinHash Sketch, comparing its values with other MinHash Sketches
+  DESTROY <type> <name>                       Destroy a Sketch
+
+  CLEAR <type> <name>                         Reset a Sketch, keeping its properties
+  RENAME <type> <name> <newName>              Rename a Sketch
+  CLONE <type> <name> <newName>               Copy a Sketch to a new name
 
   LIST DOM                                    List existing Domains
   LIST                                        List existing Sketches
 
   INFO DOM <name>                             Get details of a Domain
-  INFO <name>                                 Get details of a Sketch
+  INFO <name>                                 Get details of the Sketches named <name>, of any type
+
+  QUERY DOM <name> [value1...]                Get the results of all Sketches of a Domain at once
 
   ADD DOM  <name> <value1> [value2...]        Add values to a Domain
   ADD FREQ <name> <value1> [value2...]        Add values to a frequency Sketch
   ADD MEMB <name> <value1> [value2...]        Add values to a membership Sketch
   ADD RANK <name> <value1> [value2...]        Add values to a rankings Sketch
   ADD CARD <name> <value1> [value2...]        Add values to a cardinality Sketch
+  ADD RANGE <name> <int1> [int2...]           Add integers to a range Sketch
+  ADD THETA <name> <value1> [value2...]       Add values to a theta Sketch
+  ADD SAMP <name> <value1> [value2...]        Add values to a sample Sketch, as value:weight if it is weighted
+  ADD KCARD <name> <key> <value1> [value2...] Add values to the group of key in a keyed Sketch (KCARD or KRANK)
+  ADD MINHASH <name> <value1> [value2...]     Add values to a MinHash Sketch
+  ADDNEW MEMB <name> <value1> [value2...]     Add values missing from a membership Sketch, report the present ones
+
+  LOAD DOM <name> <file> [options...]         Add the values of a file to a Domain
+  LOAD <type> <name> <file> [options...]      Add the values of a file to a Sketch
 
   GET FREQ <name> <value1> [value2...]        Get the frequencies of the values in a FREQ Sketch
   GET MEMB <name> <value1> [value2...]        Get the memberships of the values in  a MEMB Sketch
-  GET RANK <name>                             Get the top ranking values in a RANK Sketch
+  GET RANK <name> [limit] [offset]            Get the top ranking values in a RANK Sketch
   GET CARD <name>                             Get the cardinality of a CARD Sketch
+  GET CARD <expression>                       Get the cardinality of a union (|), intersection (&) or
+                                              difference (-) of CARD Sketches and Domains (dom:<name>),
+                                              e.g. GET CARD (home | dom:blog) & pricing
+  GET THETA <name|expression>                 Get the cardinality of a THETA Sketch or of an expression,
+                                              intersections and differences are computed natively
+  GET RANGE <name> <lo> <hi>                  Get the number of values between lo and hi in a RANGE Sketch
+  GET SAMP <name>                             Get the values sampled by a SAMP Sketch
+  GET KCARD <name> <key1> [key2...]           Get the results of groups of a keyed Sketch (KCARD or KRANK)
+  GET KCARD <name> [limit=<n>]                Get the groups with the largest cardinality in a KCARD Sketch
+  GET MINHASH <name> [other...] [options...]  Get the MINHASH Sketches most similar to a MINHASH Sketch, among the
+                                              given ones or all of them (limit=<n>, min=<float>, lsh=true)
+  GET <type> <name1,name2...> [values...]     Get the results of several Sketches of the same type
+
+  WATCH <seconds> <query>                     Re-run a GET, QUERY, INFO or LIST query until Ctrl+c
+
+  SAVE                                        Create a snapshot
+  SAVE STATUS                                 Get the status of the last snapshot
 
   QUIT                                        Exit skizze-cli
 
+PROPERTIES:
+  maxUniqueItems=<int>                        Expected number of unique values (MEMB, FREQ)
+  errorRate=<float>                           Acceptable error rate, between 0 and 1 (MEMB, FREQ)
+  size=<int>                                  Number of top ranking values (RANK, per group for KRANK), hashes (THETA),
+                                              signature rows (MINHASH) or values (SAMP) to keep
+  halfLife=<seconds|duration>                 Time after which counts weigh half, e.g. 3600 or 1h (RANK, FREQ)
+  minValue=<int>, maxValue=<int>              Smallest and largest integer that can be added (RANGE)
+  weighted=<bool>                             Sample values added as value:weight in proportion to their weight (SAMP)
+  <int>                                       Short for size=<int> on RANK, KRANK, THETA, MINHASH and SAMP, maxValue=<int> on RANGE,
+                                              maxUniqueItems=<int> otherwise
+
+LOAD OPTIONS:
+  --column <n>                                Take the values from the n-th column instead of the whole line
+  --delimiter <char>                          Column delimiter, defaults to "," (use "tab" for tabs)
+  --batch <n>                                 Number of values sent per request, defaults to 10000
+  --offset <bytes>                            Resume a failed load from the reported offset
+
 SHORTCUTS:
   Ctrl+d                                      Exit skizze-cli
 
 EXAMPLES:
-  CREATE DOM users 100 100000
+  CREATE DOM users 100000 100
+  CREATE FREQ logins maxUniqueItems=100000 errorRate=0.01
   ADD DOM users neil seif martin conor neil conor seif seif seif
   GET FREQ users neil
-  GET RANK users
+  GET RANK users 10 20
   GET CARD users
+  GET CARD users & admins
 `
 
 var (
-	address    string
-	client     pb.SkizzeClient
+	address   string
+	command   string
+	script    string
+	keepGoing bool
+	client    pb.SkizzeClient
+	// completion holds the commands that do not name a sketch type, the
+	// ones that do are added for every registered type by commands
 	completion = []string{
-		"create dom", "destroy dom",
-		"create card", "create memb", "create freq", "create rank",
+		"create dom", "destroy dom", "attach dom", "detach dom",
+		"clear dom", "rename dom", "clone dom",
 		"list", "list dom",
-		"info", "info dom",
-		"add dom", "add freq", "add memb", "add rank", "add card",
-		"get freq", "get memb", "get rank", "get card",
+		"info", "info dom", "query dom",
+		"add dom", "load dom",
+		"save", "save status", "watch",
 		"help", "exit",
 	}
-	conn      *grpc.ClientConn
-	historyFn = filepath.Join(os.TempDir(), ".skizze_history")
-	w         = new(tabwriter.Writer)
-	typeMap   = map[string]pb.SketchType{
-		datamodel.HLLPP: pb.SketchType_CARD,
-		datamodel.CML:   pb.SketchType_FREQ,
-		datamodel.Bloom: pb.SketchType_MEMB,
-		datamodel.TopK:  pb.SketchType_RANK,
-	}
-	version string
+	sketchCommands = []string{"create", "destroy", "clear", "rename", "clone", "add", "load", "get"}
+	conn           *grpc.ClientConn
+	historyFn      = filepath.Join(os.TempDir(), ".skizze_history")
+	w              = new(tabwriter.Writer)
+	version        string
 )
 
 func setupClient() (pb.SkizzeClient, *grpc.ClientConn) {
@@ -111,6 +178,9 @@ func getFields(query string) []string {
 
 func evaluateQuery(query string) error {
 	fields := getFields(query)
+	if len(fields) > 0 && strings.ToLower(fields[0]) == "watch" {
+		return watchQuery(fields)
+	}
 	if len(fields) != 0 && len(fields) <= 2 {
 		//TODO: global stuff might be set
 		switch strings.ToLower(fields[0]) {
@@ -127,15 +197,22 @@ func evaluateQuery(query string) error {
 			} else if len(fields) == 2 && strings.ToLower(fields[1]) == "dom" {
 				return listDomains()
 			} else if len(fields) == 2 {
-				v, ok := typeMap[strings.ToLower(fields[1])]
+				t, ok := datamodel.LookupName(fields[1])
 				if !ok {
 					return fmt.Errorf("Invalid operation: %s", query)
 				}
-				return listSketchType(v)
+				return listSketchType(t.Type)
+			}
+		case "info":
+			if len(fields) == 2 {
+				return getNamedSketchesInfo(fields[1])
 			}
+			return fmt.Errorf("Invalid operation: %s", query)
 		case "save":
 			if len(fields) == 1 {
 				return save()
+			} else if strings.ToLower(fields[1]) == "status" {
+				return snapshotStatus()
 			}
 			return fmt.Errorf("Invalid operation: %s", query)
 		default:
@@ -144,27 +221,134 @@ func evaluateQuery(query string) error {
 	}
 
 	if len(fields) > 2 {
-		switch strings.ToLower(fields[1]) {
-		case datamodel.HLLPP:
-			return sendSketchRequest(fields, pb.SketchType_CARD)
-		case datamodel.CML:
-			return sendSketchRequest(fields, pb.SketchType_FREQ)
-		case datamodel.TopK:
-			return sendSketchRequest(fields, pb.SketchType_RANK)
-		case datamodel.Bloom:
-			return sendSketchRequest(fields, pb.SketchType_MEMB)
-		case datamodel.DOM:
+		if strings.ToLower(fields[1]) == datamodel.DOM {
 			return sendDomainRequest(fields)
-		default:
-			return fmt.Errorf("unkown field or command %s", fields[1])
 		}
+		if t, ok := datamodel.LookupName(fields[1]); ok {
+			return sendSketchRequest(fields, t.Type)
+		}
+		return fmt.Errorf("unkown field or command %s", fields[1])
 	}
 	return errors.New("Invalid operation")
 }
 
 func save() error {
-	_, err := client.CreateSnapshot(context.Background(), &pb.CreateSnapshotRequest{})
-	return err
+	reply, err := client.CreateSnapshot(context.Background(), &pb.CreateSnapshotRequest{})
+	if err != nil {
+		return err
+	}
+	printRecords([]record{{
+		{"Status", reply.GetStatus().String()},
+		{"Message", reply.GetStatusMessage()},
+	}})
+	return nil
+}
+
+func snapshotStatus() error {
+	reply, err := client.GetSnapshot(context.Background(), &pb.GetSnapshotRequest{})
+	if err != nil {
+		return err
+	}
+	printRecords([]record{{
+		{"Status", reply.GetStatus().String()},
+		{"Message", reply.GetStatusMessage()},
+		{"Timestamp", reply.GetTimestamp()},
+	}})
+	return nil
+}
+
+// runScript evaluates the queries read line by line from r. Empty lines and
+// lines starting with # are skipped. It stops at the first failing query
+// unless keepGoing is set, in which case the number of failed queries is
+// reported once all of them have been evaluated.
+func runScript(r io.Reader) error {
+	failed := 0
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		query := strings.TrimSpace(scanner.Text())
+		if len(query) == 0 || strings.HasPrefix(query, "#") {
+			continue
+		}
+		if err := evaluateQuery(query); err != nil {
+			err = fmt.Errorf("line %d: %s", n, err.Error())
+			if !keepGoing {
+				return err
+			}
+			printError(err)
+			failed++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d queries failed", failed)
+	}
+	return nil
+}
+
+func runFile(fn string) error {
+	f, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	return runScript(f)
+}
+
+func isTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
+
+func exitOnError(err error) {
+	if err != nil {
+		printError(err)
+		tearDownClient(conn)
+		os.Exit(1)
+	}
+}
+
+func runInteractive() {
+	line := liner.NewLiner()
+
+	defer func() { _ = line.Close() }()
+
+	line.SetCtrlCAborts(true)
+
+	line.SetCompleter(complete)
+
+	if f, err := os.Open(historyFn); err == nil {
+		if _, err := line.ReadHistory(f); err == nil {
+			_ = f.Close()
+		}
+	}
+
+	for {
+		if query, err := line.Prompt("skizze> "); err == nil {
+			if err := evaluateQuery(query); err != nil {
+				printError(err)
+			}
+			line.AppendHistory(query)
+		} else if err == io.EOF {
+			return
+		} else if err == liner.ErrPromptAborted {
+			fmt.Println("")
+		} else {
+			log.Printf("Error reading line: %s", err.Error())
+		}
+
+		if f, err := os.Create(historyFn); err != nil {
+			log.Fatalf("Error writing history file: %s", err.Error())
+		} else {
+			if _, err := line.WriteHistory(f); err != nil {
+				_ = f.Close()
+			}
+		}
+	}
 }
 
 func printHelp() {
@@ -187,6 +371,27 @@ func Run() {
 			Destination: &address,
 			EnvVar:      "SKIZZE_ADDRESS",
 		},
+		cli.StringFlag{
+			Name:        "exec, e",
+			Usage:       "evaluate a single query and exit",
+			Destination: &command,
+		},
+		cli.StringFlag{
+			Name:        "file, f",
+			Usage:       "evaluate the queries of a script, one per line, and exit",
+			Destination: &script,
+		},
+		cli.StringFlag{
+			Name:        "output, o",
+			Value:       tableOutput,
+			Usage:       "the output format: table, json or csv",
+			Destination: &output,
+		},
+		cli.BoolFlag{
+			Name:        "continue, c",
+			Usage:       "keep evaluating queries after an error, still exiting with a non-zero status",
+			Destination: &keepGoing,
+		},
 	}
 
 	app.Commands = []cli.Command{
@@ -201,52 +406,23 @@ func Run() {
 	}
 
 	app.Action = func(*cli.Context) {
-		client, conn = setupClient()
-		line := liner.NewLiner()
-		w.Init(os.Stdout, 0, 8, 0, '\t', 0)
-
-		defer func() { _ = line.Close() }()
-
-		line.SetCtrlCAborts(true)
-
-		line.SetCompleter(func(line string) (c []string) {
-			for _, n := range completion {
-				if strings.HasPrefix(n, strings.ToLower(line)) {
-					c = append(c, n)
-				}
-			}
-			return
-		})
-
-		if f, err := os.Open(historyFn); err == nil {
-			if _, err := line.ReadHistory(f); err == nil {
-				_ = f.Close()
-			}
+		if !isValidOutput(output) {
+			log.Fatalf("Invalid output format: %s", output)
 		}
-
-		for {
-			if query, err := line.Prompt("skizze> "); err == nil {
-				if err := evaluateQuery(query); err != nil {
-					log.Printf("Error evaluating query: %s", err.Error())
-				}
-				line.AppendHistory(query)
-			} else if err == io.EOF {
-				tearDownClient(conn)
-				return
-			} else if err == liner.ErrPromptAborted {
-				fmt.Println("")
-			} else {
-				log.Printf("Error reading line: %s", err.Error())
-			}
-
-			if f, err := os.Create(historyFn); err != nil {
-				log.Fatalf("Error writing history file: %s", err.Error())
-			} else {
-				if _, err := line.WriteHistory(f); err != nil {
-					_ = f.Close()
-				}
-			}
+		client, conn = setupClient()
+		w.Init(stdout, 0, 8, 0, '\t', 0)
+
+		switch {
+		case len(command) > 0:
+			exitOnError(evaluateQuery(command))
+		case len(script) > 0:
+			exitOnError(runFile(script))
+		case !isTerminal(os.Stdin):
+			exitOnError(runScript(os.Stdin))
+		default:
+			runInteractive()
 		}
+		tearDownClient(conn)
 	}
 
 	app.Run(os.Args)
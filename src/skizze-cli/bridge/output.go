@@ -0,0 +1,153 @@
+package bridge
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/grpc"
+)
+
+// Output formats selectable with --output
+const (
+	tableOutput = "table"
+	jsonOutput  = "json"
+	csvOutput   = "csv"
+)
+
+var (
+	output           = tableOutput
+	stdout io.Writer = os.Stdout
+)
+
+// field is a single named value of a record
+type field struct {
+	key   string
+	value interface{}
+}
+
+// record is one row of output, its fields are printed in order
+type record []field
+
+func isValidOutput(format string) bool {
+	switch format {
+	case tableOutput, jsonOutput, csvOutput:
+		return true
+	default:
+		return false
+	}
+}
+
+// jsonKey turns a table column such as "MaxUniqueItems" into "maxUniqueItems"
+func jsonKey(key string) string {
+	r, n := utf8.DecodeRuneInString(key)
+	return string(unicode.ToLower(r)) + key[n:]
+}
+
+// printRecords writes the result of a command in the selected output format.
+// A command always emits one JSON array or one CSV document, even when it
+// has no records.
+func printRecords(records []record) {
+	switch output {
+	case jsonOutput:
+		printJSON(records)
+	case csvOutput:
+		printCSV(records)
+	default:
+		printTable(records)
+	}
+}
+
+func printTable(records []record) {
+	for _, rec := range records {
+		cols := make([]string, len(rec))
+		for i, f := range rec {
+			cols[i] = fmt.Sprintf("%s: %v", f.key, f.value)
+		}
+		_, _ = fmt.Fprintln(w, strings.Join(cols, "\t  "))
+	}
+	_ = w.Flush()
+}
+
+func printJSON(records []record) {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, rec := range records {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('{')
+		for j, f := range rec {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(jsonKey(f.key))
+			value, err := json.Marshal(f.value)
+			if err != nil {
+				value, _ = json.Marshal(fmt.Sprint(f.value))
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			buf.Write(value)
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteString("]\n")
+	_, _ = stdout.Write(buf.Bytes())
+}
+
+// printCSV writes a header made of every column of the records, in order of
+// appearance, followed by one line per record
+func printCSV(records []record) {
+	var header []string
+	index := make(map[string]int)
+	for _, rec := range records {
+		for _, f := range rec {
+			key := jsonKey(f.key)
+			if _, ok := index[key]; !ok {
+				index[key] = len(header)
+				header = append(header, key)
+			}
+		}
+	}
+
+	cw := csv.NewWriter(stdout)
+	_ = cw.Write(header)
+	for _, rec := range records {
+		line := make([]string, len(header))
+		for _, f := range rec {
+			line[index[jsonKey(f.key)]] = fmt.Sprint(f.value)
+		}
+		_ = cw.Write(line)
+	}
+	cw.Flush()
+}
+
+// printDone acknowledges a command that has no result
+func printDone() {
+	if output == tableOutput {
+		_, _ = fmt.Fprintln(stdout, "done")
+		return
+	}
+	printRecords([]record{{{"Status", "done"}}})
+}
+
+// printError reports a failed command, as a record carrying the gRPC status
+// code unless the output is a table
+func printError(err error) {
+	if output == tableOutput {
+		log.Printf("Error evaluating query: %s", err.Error())
+		return
+	}
+	printRecords([]record{{
+		{"Error", grpc.ErrorDesc(err)},
+		{"Code", grpc.Code(err).String()},
+	}})
+}
@@ -0,0 +1,77 @@
+package bridge
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	pb "datamodel/protobuf"
+
+	"github.com/gogo/protobuf/proto"
+)
+
+// parseSimilarOptions reads the limit=, min= and lsh= options of a
+// similarity query, the remaining arguments name the candidates
+func parseSimilarOptions(args []string, in *pb.FindSimilarRequest) ([]string, error) {
+	var names []string
+	for _, arg := range args {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 {
+			names = append(names, arg)
+			continue
+		}
+		switch strings.ToLower(kv[0]) {
+		case "limit":
+			num, err := strconv.ParseInt(kv[1], 10, 32)
+			if err != nil || num < 0 {
+				return nil, fmt.Errorf("Invalid limit %q, expected a positive integer", kv[1])
+			}
+			in.Limit = proto.Int32(int32(num))
+		case "min":
+			min, err := strconv.ParseFloat(kv[1], 32)
+			if err != nil || min < 0 || min > 1 {
+				return nil, fmt.Errorf("Invalid minimum similarity %q, expected a float between 0 and 1", kv[1])
+			}
+			in.MinSimilarity = proto.Float32(float32(min))
+		case "lsh":
+			lsh, err := strconv.ParseBool(kv[1])
+			if err != nil {
+				return nil, fmt.Errorf("Invalid lsh %q, expected true or false", kv[1])
+			}
+			in.Lsh = proto.Bool(lsh)
+		default:
+			return nil, fmt.Errorf("Unknown option %s", kv[0])
+		}
+	}
+	return names, nil
+}
+
+// getSimilar answers GET MINHASH, comparing a sketch with the given ones or
+// with every other sketch of its type
+func getSimilar(fields []string, typ pb.SketchType) error {
+	req := &pb.FindSimilarRequest{Sketch: &pb.Sketch{Name: proto.String(fields[2]), Type: &typ}}
+	names, err := parseSimilarOptions(fields[3:], req)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		styp := typ
+		req.Candidates = append(req.Candidates, &pb.Sketch{Name: proto.String(name), Type: &styp})
+	}
+	reply, err := client.FindSimilar(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	records := make([]record, 0, len(reply.GetResults()))
+	for _, res := range reply.GetResults() {
+		records = append(records, record{
+			{"Name", res.GetSketch().GetName()},
+			{"Similarity", res.GetSimilarity()},
+			{"Error", res.GetError()},
+		})
+	}
+	printRecords(records)
+	return nil
+}
@@ -2,48 +2,210 @@ package bridge
 
 import (
 	"fmt"
-	"log"
 	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/net/context"
 
+	"datamodel"
 	pb "datamodel/protobuf"
 
 	"github.com/gogo/protobuf/proto"
 )
 
-func createSketch(fields []string, in *pb.Sketch) error {
-	if in.GetType() != pb.SketchType_CARD {
-		if len(fields) > 4 {
-			return fmt.Errorf("Too many argumets, expected 4 got %d", len(fields))
-		}
-		num, err := strconv.Atoi(fields[3])
+// parseProperties reads sketch properties given as key=value pairs
+// (maxUniqueItems, errorRate, size, halfLife, minValue, maxValue and
+// weighted, case insensitive). A single integer is still accepted for backwards
+// compatibility, it sets the size of types that only have a size, such as
+// RANK, MINHASH, SAMP or KRANK, the maxValue of RANGE and the maxUniqueItems of any other type.
+func parseProperties(args []string, typ pb.SketchType) (*pb.SketchProperties, error) {
+	props := &pb.SketchProperties{}
+	if len(args) == 1 && !strings.Contains(args[0], "=") {
+		num, err := strconv.ParseInt(args[0], 10, 64)
 		if err != nil {
-			return fmt.Errorf("Expected last argument to be of type int: %q", err)
+			return nil, fmt.Errorf("Expected last argument to be of type int: %q", err)
+		}
+		var defaults *pb.SketchProperties
+		if t, ok := datamodel.LookupType(typ); ok {
+			defaults = t.Defaults
 		}
+		switch {
+		case defaults.GetMaxUniqueItems() != 0:
+			props.MaxUniqueItems = proto.Int64(num)
+		case defaults.GetSize() != 0:
+			props.Size = proto.Int64(num)
+		case defaults.GetMaxValue() != 0:
+			props.MaxValue = proto.Int64(num)
+		default:
+			props.MaxUniqueItems = proto.Int64(num)
+		}
+		return props, nil
+	}
 
-		in.Properties = &pb.SketchProperties{
-			Size:           proto.Int64(int64(num)),
-			MaxUniqueItems: proto.Int64(int64(num)),
+	for _, arg := range args {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("Expected property of the form key=value, got %s", arg)
+		}
+		switch strings.ToLower(kv[0]) {
+		case "maxuniqueitems":
+			num, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("Expected maxUniqueItems to be of type int: %q", err)
+			}
+			props.MaxUniqueItems = proto.Int64(num)
+		case "errorrate":
+			rate, err := strconv.ParseFloat(kv[1], 32)
+			if err != nil {
+				return nil, fmt.Errorf("Expected errorRate to be of type float: %q", err)
+			}
+			props.ErrorRate = proto.Float32(float32(rate))
+		case "size":
+			num, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("Expected size to be of type int: %q", err)
+			}
+			props.Size = proto.Int64(num)
+		case "minvalue", "maxvalue":
+			num, err := strconv.ParseInt(kv[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("Expected %s to be of type int: %q", kv[0], err)
+			}
+			if strings.ToLower(kv[0]) == "minvalue" {
+				props.MinValue = proto.Int64(num)
+			} else {
+				props.MaxValue = proto.Int64(num)
+			}
+		case "halflife":
+			halfLife, err := parseHalfLife(kv[1])
+			if err != nil {
+				return nil, err
+			}
+			props.HalfLife = proto.Int64(halfLife)
+		case "weighted":
+			weighted, err := strconv.ParseBool(kv[1])
+			if err != nil {
+				return nil, fmt.Errorf("Expected weighted to be true or false: %q", err)
+			}
+			props.Weighted = proto.Bool(weighted)
+		default:
+			return nil, fmt.Errorf("Unknown property %s", kv[0])
 		}
 	}
-	_, err := client.CreateSketch(context.Background(), in)
+	return props, nil
+}
+
+// parseHalfLife reads a half-life given in seconds or as a duration such as
+// 1h30m, and returns it in seconds
+func parseHalfLife(s string) (int64, error) {
+	if num, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return num, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("Expected halfLife to be seconds or a duration: %q", err)
+	}
+	if d%time.Second != 0 {
+		return 0, fmt.Errorf("Expected halfLife to be whole seconds, got %s", s)
+	}
+	return int64(d / time.Second), nil
+}
+
+func createSketch(fields []string, in *pb.Sketch) error {
+	// Types without defaults have no properties
+	t, ok := datamodel.LookupType(in.GetType())
+	if ok && t.Defaults != nil && len(fields) < 4 {
+		return fmt.Errorf("Expected at least 4 arguments got %d", len(fields))
+	}
+	props, err := parseProperties(fields[3:], in.GetType())
+	if err != nil {
+		return err
+	}
+	in.Properties = props
+	_, err = client.CreateSketch(context.Background(), in)
+	if err == nil {
+		printDone()
+	}
+	return err
+}
+
+func deleteSketch(fields []string, in *pb.Sketch) error {
+	if len(fields) != 3 {
+		return fmt.Errorf("Expected 3 arguments got %d", len(fields))
+	}
+	_, err := client.DeleteSketch(context.Background(), in)
+	if err == nil {
+		printDone()
+	}
 	return err
 }
 
 func addToSketch(fields []string, in *pb.Sketch) error {
 	if len(fields) < 4 {
-		return fmt.Errorf("Expected at least 4 values, got %q", len(fields))
+		return fmt.Errorf("Expected at least 4 values, got %d", len(fields))
+	}
+	if t, ok := datamodel.LookupType(in.GetType()); ok && t.Can(datamodel.GroupQuery) {
+		return addToGroup(fields, in)
 	}
 	addRequest := &pb.AddRequest{
 		Sketch: in,
 		Values: fields[3:],
 	}
-	_, err := client.Add(context.Background(), addRequest)
+	reply, err := client.Add(context.Background(), addRequest)
+	if err == nil {
+		printAddReply(reply)
+	}
 	return err
 }
 
+// addIfAbsent adds the values that are not in the sketch yet, printing for
+// every value whether it was present
+func addIfAbsent(fields []string, in *pb.Sketch) error {
+	if len(fields) < 4 {
+		return fmt.Errorf("Expected at least 4 values, got %d", len(fields))
+	}
+	req := &pb.AddIfAbsentRequest{
+		Sketch: in,
+		Values: fields[3:],
+	}
+	reply, err := client.AddIfAbsent(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	records := make([]record, len(reply.GetResults()))
+	for i, v := range reply.GetResults() {
+		records[i] = record{{"Value", v.GetValue()}, {"Present", v.GetIsMember()}}
+	}
+	printRecords(records)
+	return nil
+}
+
+func printAddReply(reply *pb.AddReply) {
+	records := make([]record, len(reply.GetResults()))
+	for i, v := range reply.GetResults() {
+		sketch := v.GetSketch()
+		records[i] = record{
+			{"Name", sketch.GetName()},
+			{"Type", datamodel.TypeName(sketch.GetType())},
+			{"Applied", v.GetApplied()},
+			{"Status", v.GetStatus().String()},
+		}
+		if !v.GetSuccess() {
+			records[i] = append(records[i], field{"Error", v.GetError()})
+		}
+	}
+	printRecords(records)
+}
+
+func printSketches(sketches []*pb.Sketch) {
+	records := make([]record, len(sketches))
+	for i, v := range sketches {
+		records[i] = record{{"Name", v.GetName()}, {"Type", datamodel.TypeName(v.GetType())}}
+	}
+	printRecords(records)
+}
+
 func sendSketchRequest(fields []string, typ pb.SketchType) error {
 	name := fields[2]
 	in := &pb.Sketch{
@@ -56,25 +218,72 @@ func sendSketchRequest(fields []string, typ pb.SketchType) error {
 		return createSketch(fields, in)
 	case "add":
 		return addToSketch(fields, in)
+	case "addnew":
+		return addIfAbsent(fields, in)
+	case "load":
+		return loadFile(fields, &pb.AddRequest{Sketch: in})
 	case "get":
-		return getFromSketch(fields, in)
+		return getFromSketches(fields, typ)
 	case "destroy":
+		return deleteSketch(fields, in)
 	case "info":
 		return getSketchInfo(in)
+	case "clear":
+		return clearSketch(fields, in)
+	case "rename":
+		return renameSketch(fields, in)
+	case "clone":
+		return cloneSketch(fields, in)
 	default:
 		return fmt.Errorf("unkown operation: %s", fields[0])
 	}
-	return nil
+}
+
+func clearSketch(fields []string, in *pb.Sketch) error {
+	if len(fields) != 3 {
+		return fmt.Errorf("Expected 3 arguments got %d", len(fields))
+	}
+	_, err := client.ClearSketch(context.Background(), in)
+	if err == nil {
+		printDone()
+	}
+	return err
+}
+
+func renameSketch(fields []string, in *pb.Sketch) error {
+	if len(fields) != 4 {
+		return fmt.Errorf("Expected 4 arguments got %d", len(fields))
+	}
+	req := &pb.SketchNameRequest{
+		Sketch:  in,
+		NewName: proto.String(fields[3]),
+	}
+	_, err := client.RenameSketch(context.Background(), req)
+	if err == nil {
+		printDone()
+	}
+	return err
+}
+
+func cloneSketch(fields []string, in *pb.Sketch) error {
+	if len(fields) != 4 {
+		return fmt.Errorf("Expected 4 arguments got %d", len(fields))
+	}
+	req := &pb.SketchNameRequest{
+		Sketch:  in,
+		NewName: proto.String(fields[3]),
+	}
+	_, err := client.CloneSketch(context.Background(), req)
+	if err == nil {
+		printDone()
+	}
+	return err
 }
 
 func listSketches() error {
 	reply, err := client.ListAll(context.Background(), &pb.Empty{})
 	if err == nil {
-		for _, v := range reply.GetSketches() {
-			line := fmt.Sprintf("Name: %s\t  Type: %s", v.GetName(), v.GetType().String())
-			_, _ = fmt.Fprintln(w, line)
-		}
-		_ = w.Flush()
+		printSketches(reply.GetSketches())
 	}
 	return err
 }
@@ -82,11 +291,7 @@ func listSketches() error {
 func listSketchType(typ pb.SketchType) error {
 	reply, err := client.List(context.Background(), &pb.ListRequest{Type: &typ})
 	if err == nil {
-		for _, v := range reply.GetSketches() {
-			line := fmt.Sprintf("Name: %s\t  Type: %s", v.GetName(), v.GetType().String())
-			_, _ = fmt.Fprintln(w, line)
-		}
-		_ = w.Flush()
+		printSketches(reply.GetSketches())
 	}
 	return err
 }
@@ -97,74 +302,269 @@ func getSketchInfo(in *pb.Sketch) error {
 		Size:           proto.Int64(0),
 	}
 	reply, err := client.GetSketch(context.Background(), in)
-	log.Printf("%s", reply)
-	return err
+	if err != nil {
+		return err
+	}
+	printRecords([]record{sketchInfoRecord(reply)})
+	return nil
+}
+
+// getNamedSketchesInfo prints the details of every sketch named name,
+// whatever its type
+func getNamedSketchesInfo(name string) error {
+	reply, err := client.ListAll(context.Background(), &pb.Empty{})
+	if err != nil {
+		return err
+	}
+	var records []record
+	for _, sketch := range reply.GetSketches() {
+		if sketch.GetName() != name {
+			continue
+		}
+		info, err := client.GetSketch(context.Background(), sketch)
+		if err != nil {
+			return err
+		}
+		records = append(records, sketchInfoRecord(info))
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("No sketch named %s", name)
+	}
+	printRecords(records)
+	return nil
 }
 
-func getFromSketch(fields []string, in *pb.Sketch) error {
+func sketchInfoRecord(reply *pb.Sketch) record {
+	props := reply.GetProperties()
+	return record{
+		{"Name", reply.GetName()},
+		{"Type", datamodel.TypeName(reply.GetType())},
+		{"MaxUniqueItems", props.GetMaxUniqueItems()},
+		{"ErrorRate", props.GetErrorRate()},
+		{"Size", props.GetSize()},
+		{"HalfLife", props.GetHalfLife()},
+		{"MinValue", props.GetMinValue()},
+		{"MaxValue", props.GetMaxValue()},
+		{"Weighted", props.GetWeighted()},
+	}
+}
+
+// getFromSketches queries one or more sketches of the same type, given as a
+// comma separated list of names
+func getFromSketches(fields []string, typ pb.SketchType) error {
 	if len(fields) < 3 {
-		return fmt.Errorf("Expected at least 3 values, got %q", len(fields))
+		return fmt.Errorf("Expected at least 3 values, got %d", len(fields))
+	}
+	t, ok := datamodel.LookupType(typ)
+	if !ok {
+		return fmt.Errorf("Unkown Type %s", datamodel.TypeName(typ))
+	}
+	if t.Can(datamodel.SimilarityQuery) {
+		return getSimilar(fields, typ)
+	}
+	if t.Can(datamodel.GroupQuery) {
+		return getGroups(fields, typ)
+	}
+	if t.Can(datamodel.CardinalityQuery) && isExpression(fields[2:]) {
+		return getCardinalityExpression(fields[2:], typ)
 	}
 	getRequest := &pb.GetRequest{
-		Sketches: []*pb.Sketch{in},
-		Values:   fields[3:],
+		Values: fields[3:],
+	}
+	var names []string
+	for _, name := range strings.Split(fields[2], ",") {
+		if len(name) == 0 {
+			continue
+		}
+		styp := typ
+		getRequest.Sketches = append(getRequest.Sketches, &pb.Sketch{
+			Name: proto.String(name),
+			Type: &styp,
+		})
+		names = append(names, name)
 	}
 
-	switch in.GetType() {
-	case pb.SketchType_CARD:
+	// Results are returned in the order of the requested sketches
+	named := func(i int, results []record) []record {
+		for j, r := range results {
+			results[j] = append(record{{"Name", names[i]}}, r...)
+		}
+		return results
+	}
+
+	// Sketches that only rank take a limit and an offset instead of values
+	if t.Can(datamodel.RankingsQuery) && !t.Can(datamodel.FrequencyQuery|datamodel.MembershipQuery) {
+		if err := parseRankingsPage(fields[3:], getRequest); err != nil {
+			return err
+		}
+		getRequest.Values = nil
+	}
+	var records []record
+	if t.Can(datamodel.CardinalityQuery) {
 		reply, err := client.GetCardinality(context.Background(), getRequest)
-		if err == nil {
-			if len(reply.GetResults()) == 0 {
-				log.Printf("%s does not exist", in.GetName())
-			} else {
-				fmt.Printf("Cardinality: %d", reply.GetResults()[0].GetCardinality())
-				fmt.Println("")
-			}
+		if err != nil {
+			return err
 		}
-		return err
-	case pb.SketchType_FREQ:
+		for i, v := range reply.GetResults() {
+			records = append(records, named(i, cardinalityRecords(v))...)
+		}
+	}
+	if t.Can(datamodel.FrequencyQuery) {
 		reply, err := client.GetFrequency(context.Background(), getRequest)
-		if err == nil {
-			if len(reply.GetResults()) == 0 {
-				log.Printf("%s does not exist", in.GetName())
-			} else {
-				for _, v := range reply.GetResults()[0].GetFrequencies() {
-					line := fmt.Sprintf("Value: %s\t  Hits: %d", v.GetValue(), v.GetCount())
-					_, _ = fmt.Fprintln(w, line)
-				}
-			}
-			_ = w.Flush()
+		if err != nil {
+			return err
 		}
-		return err
-	case pb.SketchType_MEMB:
+		for i, v := range reply.GetResults() {
+			records = append(records, named(i, frequencyRecords(v))...)
+		}
+	}
+	if t.Can(datamodel.MembershipQuery) {
 		reply, err := client.GetMembership(context.Background(), getRequest)
-		if err == nil {
-			if len(reply.GetResults()) == 0 {
-				log.Printf("%s does not exist", in.GetName())
-			} else {
-				for _, v := range reply.GetResults()[0].GetMemberships() {
-					line := fmt.Sprintf("Value: %s\t  Member: %t", v.GetValue(), v.GetIsMember())
-					_, _ = fmt.Fprintln(w, line)
-				}
-				_ = w.Flush()
-			}
+		if err != nil {
+			return err
 		}
-		return err
-	case pb.SketchType_RANK:
+		for i, v := range reply.GetResults() {
+			records = append(records, named(i, membershipRecords(v))...)
+		}
+	}
+	if t.Can(datamodel.RankingsQuery) {
 		reply, err := client.GetRankings(context.Background(), getRequest)
-		if err == nil {
-			if len(reply.GetResults()) == 0 {
-				log.Printf("%s does not exist", in.GetName())
-			} else {
-				for i, v := range reply.GetResults()[0].GetRankings() {
-					line := fmt.Sprintf("Rank: %d\t  Value: %s\t  Hits: %d", i+1, v.GetValue(), v.GetCount())
-					_, _ = fmt.Fprintln(w, line)
-				}
-				_ = w.Flush()
-			}
+		if err != nil {
+			return err
 		}
-		return err
-	default:
-		return fmt.Errorf("Unkown Type %s", in.GetType().String())
+		for i, v := range reply.GetResults() {
+			records = append(records, named(i, rankingsRecords(v, int(getRequest.GetOffset())))...)
+		}
+	}
+	if t.Can(datamodel.RangeQuery) {
+		req, err := parseRange(fields[3:], getRequest.GetSketches())
+		if err != nil {
+			return err
+		}
+		reply, err := client.GetRangeCount(context.Background(), req)
+		if err != nil {
+			return err
+		}
+		for i, v := range reply.GetResults() {
+			records = append(records, named(i, rangeCountRecords(v))...)
+		}
+	}
+	if t.Can(datamodel.SampleQuery) {
+		reply, err := client.GetSample(context.Background(), getRequest)
+		if err != nil {
+			return err
+		}
+		for i, v := range reply.GetResults() {
+			records = append(records, named(i, sampleRecords(v))...)
+		}
+	}
+	printRecords(records)
+	return nil
+}
+
+// parseRankingsPage reads the optional limit and offset of a rankings query
+func parseRankingsPage(args []string, in *pb.GetRequest) error {
+	if len(args) > 2 {
+		return fmt.Errorf("Expected at most a limit and an offset, got %d values", len(args))
+	}
+	page := make([]int32, len(args))
+	for i, arg := range args {
+		num, err := strconv.ParseInt(arg, 10, 32)
+		if err != nil || num < 0 {
+			return fmt.Errorf("Invalid number %q, expected a positive integer", arg)
+		}
+		page[i] = int32(num)
+	}
+	if len(page) > 0 {
+		in.Limit = proto.Int32(page[0])
+	}
+	if len(page) > 1 {
+		in.Offset = proto.Int32(page[1])
+	}
+	return nil
+}
+
+// parseRange reads the lower and upper bound of a range count query
+func parseRange(args []string, sketches []*pb.Sketch) (*pb.RangeCountRequest, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("Expected a lower and an upper bound, got %d values", len(args))
+	}
+	bounds := make([]int64, 2)
+	for i, arg := range args {
+		num, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid bound %q, expected an integer", arg)
+		}
+		bounds[i] = num
+	}
+	return &pb.RangeCountRequest{
+		Sketches: sketches,
+		Lo:       proto.Int64(bounds[0]),
+		Hi:       proto.Int64(bounds[1]),
+	}, nil
+}
+
+func rangeCountRecords(res *pb.RangeCountResult) []record {
+	return []record{{
+		{"Lo", res.GetLo()},
+		{"Hi", res.GetHi()},
+		{"Count", res.GetCount()},
+		{"Error", res.GetError()},
+	}}
+}
+
+// sampleRecords lists the sampled values, with their weight if the sample
+// is weighted
+func sampleRecords(res *pb.SampleResult) []record {
+	var records []record
+	for _, v := range res.GetValues() {
+		r := record{{"Value", v.GetValue()}}
+		if v.Weight != nil {
+			r = append(r, field{"Weight", v.GetWeight()})
+		}
+		records = append(records, append(r, field{"Count", res.GetCount()}))
+	}
+	return records
+}
+
+func cardinalityRecords(res *pb.CardinalityResult) []record {
+	return []record{{
+		{"Cardinality", res.GetCardinality()},
+		{"Lower", res.GetLower()},
+		{"Upper", res.GetUpper()},
+	}}
+}
+
+func frequencyRecords(res *pb.FrequencyResult) []record {
+	var records []record
+	for _, v := range res.GetFrequencies() {
+		records = append(records, record{{"Value", v.GetValue()}, {"Hits", v.GetCount()}, {"Error", v.GetError()}})
+	}
+	return records
+}
+
+func membershipRecords(res *pb.MembershipResult) []record {
+	var records []record
+	for _, v := range res.GetMemberships() {
+		records = append(records, record{
+			{"Value", v.GetValue()},
+			{"Member", v.GetIsMember()},
+			{"FalsePositiveRate", v.GetFalsePositiveRate()},
+		})
+	}
+	return records
+}
+
+// rankingsRecords numbers the rankings from offset + 1
+func rankingsRecords(res *pb.RankingsResult, offset int) []record {
+	var records []record
+	for i, v := range res.GetRankings() {
+		records = append(records, record{
+			{"Rank", offset + i + 1},
+			{"Value", v.GetValue()},
+			{"Hits", v.GetCount()},
+			{"Error", v.GetError()},
+		})
 	}
+	return records
 }
@@ -0,0 +1,57 @@
+package bridge
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clearScreen moves the cursor home and clears the terminal
+const clearScreen = "\033[H\033[2J"
+
+// watchQuery re-runs a read-only query every few seconds until interrupted
+func watchQuery(fields []string) error {
+	if len(fields) < 3 {
+		return fmt.Errorf("Expected at least 3 arguments got %d", len(fields))
+	}
+	secs, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil || secs <= 0 {
+		return fmt.Errorf("Expected interval to be a positive number of seconds, got %s", fields[1])
+	}
+	switch strings.ToLower(fields[2]) {
+	case "get", "query", "info", "list":
+	default:
+		return fmt.Errorf("Can only watch GET, QUERY, INFO or LIST, got %s", fields[2])
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	interval := time.Duration(secs * float64(time.Second))
+	return watch(interval, strings.Join(fields[2:], " "), interrupt)
+}
+
+// watch evaluates query every interval, redrawing table output in place,
+// until a signal is received on stop or the query fails
+func watch(interval time.Duration, query string, stop <-chan os.Signal) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if output == tableOutput {
+			_, _ = fmt.Fprint(stdout, clearScreen)
+			_, _ = fmt.Fprintf(stdout, "Every %s: %s\t%s\n\n", interval, query, time.Now().Format(time.Stamp))
+		}
+		if err := evaluateQuery(query); err != nil {
+			return err
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -17,34 +18,62 @@ import (
 
 var logger = loggo.GetLogger("storage")
 
+// aofHeader starts every AOF, it tells the length-prefixed entries apart
+// from the legacy ones (see upgradeLegacy)
+const aofHeader = "SKIZZE-AOF 2\n"
+
 // AOF ...
 type AOF struct {
-	file     *os.File
-	buffer   *bufio.ReadWriter
-	lock     sync.RWMutex
-	inChan   chan *Entry
-	tickChan <-chan time.Time
+	path string
+	file *os.File
+	// offset is the end of the last complete entry read
+	offset    int64
+	buffer    *bufio.ReadWriter
+	lock      sync.RWMutex
+	inChan    chan *Entry
+	tickChan  <-chan time.Time
+	closeChan chan chan error
+	running   bool
 }
 
-// NewAOF ...
+// NewAOF opens the AOF at path, creating it if needed. An AOF written in
+// the legacy format is upgraded first.
 func NewAOF(path string) *AOF {
+	utils.PanicOnError(upgradeLegacy(path))
 	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0600)
 	utils.PanicOnError(err)
+	stat, err := file.Stat()
+	utils.PanicOnError(err)
+	if stat.Size() == 0 {
+		_, err = file.WriteString(aofHeader)
+		utils.PanicOnError(err)
+	}
+	// Appends move the offset to the end, entries are read from the start
+	_, err = file.Seek(0, io.SeekStart)
+	utils.PanicOnError(err)
 	rdr := bufio.NewReader(file)
+	header := make([]byte, len(aofHeader))
+	if _, err := io.ReadFull(rdr, header); err != nil || string(header) != aofHeader {
+		utils.PanicOnError(fmt.Errorf("%s is not an AOF, it does not start with %q", path, aofHeader))
+	}
 	wtr := bufio.NewWriter(file)
 	inChan := make(chan *Entry, 100)
 	tickChan := time.NewTicker(time.Second).C
 	return &AOF{
-		file:     file,
-		buffer:   bufio.NewReadWriter(rdr, wtr),
-		lock:     sync.RWMutex{},
-		inChan:   inChan,
-		tickChan: tickChan,
+		path:      path,
+		file:      file,
+		offset:    int64(len(aofHeader)),
+		buffer:    bufio.NewReadWriter(rdr, wtr),
+		lock:      sync.RWMutex{},
+		inChan:    inChan,
+		tickChan:  tickChan,
+		closeChan: make(chan chan error),
 	}
 }
 
 // Run ...
 func (aof *AOF) Run() {
+	aof.running = true
 	go func() {
 		for {
 			select {
@@ -54,14 +83,44 @@ func (aof *AOF) Run() {
 				if err := aof.buffer.Flush(); err != nil {
 					logger.Errorf("an error has occurred while flushing AOF: %s", err.Error())
 				}
+			case done := <-aof.closeChan:
+				for len(aof.inChan) > 0 {
+					aof.write(<-aof.inChan)
+				}
+				done <- aof.buffer.Flush()
+				return
 			}
 		}
 	}()
 }
 
+// Close writes the pending entries to disk and closes the file
+func (aof *AOF) Close() error {
+	var err error
+	if aof.running {
+		done := make(chan error)
+		aof.closeChan <- done
+		err = <-done
+		aof.running = false
+	}
+	if err2 := aof.file.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
+
+// writeEntry writes an entry as its op, the length of the message and the
+// message, so that the message can hold any byte
+func writeEntry(w *bufio.Writer, op uint8, raw []byte) error {
+	if _, err := fmt.Fprintf(w, "%d|%d|", op, len(raw)); err != nil {
+		return err
+	}
+	_, err := w.Write(raw)
+	return err
+}
+
 func (aof *AOF) write(e *Entry) {
-	line := fmt.Sprintf("%d|%s/", e.op, string(e.raw))
-	if _, err := aof.buffer.WriteString(line); err != nil {
+	if err := writeEntry(aof.buffer.Writer, e.op, e.raw); err != nil {
 		logger.Errorf("an error has ocurred while writing AOF: %s", err.Error())
 	}
 }
@@ -77,19 +136,44 @@ func (aof *AOF) Append(op uint8, msg proto.Message) error {
 	return nil
 }
 
-// Read ...
+// Read returns the next entry, io.EOF once every entry has been read. An
+// entry cut short by a crash can only be the last one, it is dropped and the
+// file truncated after the last complete entry.
 func (aof *AOF) Read() (*Entry, error) {
-	line, err := aof.buffer.ReadBytes('/')
-	if err != nil {
-		return nil, err
+	var header [2]int
+	read := 0
+	for i := range header {
+		field, err := aof.buffer.ReadString('|')
+		read += len(field)
+		if err == io.EOF && read > 0 {
+			return nil, aof.truncateTail()
+		} else if err != nil {
+			return nil, err
+		}
+		if header[i], err = strconv.Atoi(strings.TrimSuffix(field, "|")); err != nil {
+			return nil, fmt.Errorf("Invalid AOF entry header %q at byte %d", field, aof.offset)
+		}
 	}
-	line = line[:len(line)-1]
-	rs := strings.Split(string(line), "|")
-	msg := rs[1]
-	op, err := strconv.Atoi(rs[0])
-	if err != nil {
+	op, size := header[0], header[1]
+	if op < 0 || op > 255 || size < 0 {
+		return nil, fmt.Errorf("Invalid AOF entry header %d|%d| at byte %d", op, size, aof.offset)
+	}
+	raw := make([]byte, size)
+	if _, err := io.ReadFull(aof.buffer, raw); err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, aof.truncateTail()
+	} else if err != nil {
 		return nil, err
 	}
-	e := &Entry{uint8(op), nil, []byte(msg)}
-	return e, nil
+	aof.offset += int64(read + size)
+	return &Entry{uint8(op), nil, raw}, nil
+}
+
+// truncateTail drops the incomplete entry at the end of the AOF, so that
+// appends follow the last complete one
+func (aof *AOF) truncateTail() error {
+	logger.Warningf("AOF %s ends with an incomplete entry at byte %d, dropping it", aof.path, aof.offset)
+	if err := aof.file.Truncate(aof.offset); err != nil {
+		return err
+	}
+	return io.EOF
 }
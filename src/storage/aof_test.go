@@ -1,8 +1,11 @@
 package storage
 
 import (
+	"bytes"
 	"config"
 	pb "datamodel/protobuf"
+	"io"
+	"io/ioutil"
 	"path/filepath"
 	"testing"
 
@@ -191,3 +194,132 @@ func TestCreateDeleteSketch(t *testing.T) {
 		}
 	}
 }
+
+func TestReadAnyBytes(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	path := filepath.Join(config.DataDir, "skizze.aof")
+	aof := NewAOF(path)
+	aof.Run()
+
+	addReq := &pb.AddRequest{
+		Sketch: createSketch("pages/2|3", pb.SketchType_CARD),
+		Values: []string{"/home", "a|b", "", "/"},
+	}
+	if err := aof.Append(Add, addReq); err != nil {
+		t.Error("Expected no error, got", err)
+	}
+	if err := aof.Append(DeleteSketch, addReq.Sketch); err != nil {
+		t.Error("Expected no error, got", err)
+	}
+	if err := aof.Close(); err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+
+	aof = NewAOF(path)
+	e, err := aof.Read()
+	if err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	req := &pb.AddRequest{}
+	if err := proto.Unmarshal(e.raw, req); err != nil {
+		t.Error("Expected no error, got", err)
+	}
+	if e.op != Add || !proto.Equal(req, addReq) {
+		t.Errorf("Expected %v, got %d %v", addReq, e.op, req)
+	}
+	if e, err = aof.Read(); err != nil || e.op != DeleteSketch {
+		t.Error("Expected a DeleteSketch entry, got", e, err)
+	}
+	if _, err = aof.Read(); err != io.EOF {
+		t.Error("Expected EOF, got", err)
+	}
+	if err := aof.Close(); err != nil {
+		t.Error("Expected no error, got", err)
+	}
+
+	// An entry cut short by a crash is dropped, not read as a smaller message,
+	// and appends continue after the last complete entry
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	if err := ioutil.WriteFile(path, data[:len(data)-1], 0600); err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	aof = NewAOF(path)
+	if _, err := aof.Read(); err != nil {
+		t.Error("Expected no error, got", err)
+	}
+	if _, err := aof.Read(); err != io.EOF {
+		t.Error("Expected EOF, got", err)
+	}
+	aof.Run()
+	if err := aof.Append(DeleteSketch, addReq.Sketch); err != nil {
+		t.Error("Expected no error, got", err)
+	}
+	if err := aof.Close(); err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	aof = NewAOF(path)
+	defer aof.Close()
+	ops := []uint8{}
+	for {
+		e, err := aof.Read()
+		if err != nil {
+			if err != io.EOF {
+				t.Error("Expected EOF, got", err)
+			}
+			break
+		}
+		ops = append(ops, e.op)
+	}
+	if len(ops) != 2 || ops[0] != Add || ops[1] != DeleteSketch {
+		t.Error("Expected an Add and a DeleteSketch entry, got", ops)
+	}
+}
+
+func TestUpgradeLegacy(t *testing.T) {
+	config.Reset()
+	testutils.SetupTests()
+	defer testutils.TearDownTests()
+
+	path := filepath.Join(config.DataDir, "skizze.aof")
+	sketch := createSketch("skz1", pb.SketchType_CARD)
+	raw, err := proto.Marshal(sketch)
+	if err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+	legacy := []byte("2|")
+	legacy = append(legacy, raw...)
+	legacy = append(legacy, []byte("/3|")...)
+	legacy = append(legacy, raw...)
+	legacy = append(legacy, []byte("/4|cut")...)
+	if err := ioutil.WriteFile(path, legacy, 0600); err != nil {
+		t.Fatal("Expected no error, got", err)
+	}
+
+	aof := NewAOF(path)
+	defer aof.Close()
+	for _, op := range []uint8{CreateSketch, DeleteSketch} {
+		e, err := aof.Read()
+		if err != nil {
+			t.Fatal("Expected no error, got", err)
+		}
+		read := &pb.Sketch{}
+		if err := proto.Unmarshal(e.raw, read); err != nil {
+			t.Error("Expected no error, got", err)
+		}
+		if e.op != op || !proto.Equal(read, sketch) {
+			t.Errorf("Expected entry %d %v, got %d %v", op, sketch, e.op, read)
+		}
+	}
+	if _, err := aof.Read(); err != io.EOF {
+		t.Error("Expected EOF, got", err)
+	}
+	if kept, err := ioutil.ReadFile(path + ".v1"); err != nil || !bytes.Equal(kept, legacy) {
+		t.Error("Expected the legacy AOF to be kept, got", err)
+	}
+}
@@ -9,6 +9,15 @@ const (
 	CreateSketch = uint8(2)
 	DeleteSketch = uint8(3)
 	Add          = uint8(4)
+	AttachSketch = uint8(5)
+	DetachSketch = uint8(6)
+	ClearSketch  = uint8(7)
+	RenameSketch = uint8(8)
+	CloneSketch  = uint8(9)
+	ClearDom     = uint8(10)
+	RenameDom    = uint8(11)
+	CloneDom     = uint8(12)
+	AddIfAbsent  = uint8(13)
 )
 
 // Entry ...
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// upgradeLegacy rewrites an AOF written before aofHeader was introduced,
+// whose entries are the op and the message separated by '|' and terminated
+// by '/'. Messages holding either byte were split there, the fragments that
+// do not start with an op are dropped and logged. The legacy file is kept
+// next to the new one with a .v1 suffix.
+func upgradeLegacy(path string) error {
+	file, err := os.OpenFile(path, os.O_RDWR, 0600)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	rdr := bufio.NewReader(file)
+	start, err := rdr.Peek(len(aofHeader))
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if string(start) == aofHeader {
+		return nil
+	}
+	if strings.HasPrefix(aofHeader, string(start)) {
+		// Empty, or the header of a new AOF was cut short
+		return file.Truncate(0)
+	}
+
+	tmpPath := path + ".upgrade"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tmp.Close() }()
+	wtr := bufio.NewWriter(tmp)
+	if _, err := wtr.WriteString(aofHeader); err != nil {
+		return err
+	}
+	entries := 0
+	for {
+		line, err := rdr.ReadBytes('/')
+		if err == io.EOF {
+			if len(line) > 0 {
+				logger.Warningf("Legacy AOF %s ends with an incomplete entry, dropping it", path)
+			}
+			break
+		} else if err != nil {
+			return err
+		}
+		line = line[:len(line)-1]
+		i := bytes.IndexByte(line, '|')
+		op := -1
+		if i > 0 {
+			op, _ = strconv.Atoi(string(line[:i]))
+		}
+		if op < 0 || op > 255 {
+			logger.Errorf("Legacy AOF %s holds an invalid entry %q after entry %d, dropping it", path, line, entries)
+			continue
+		}
+		if err := writeEntry(wtr, uint8(op), line[i+1:]); err != nil {
+			return err
+		}
+		entries++
+	}
+	if err := wtr.Flush(); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := os.Rename(path, path+".v1"); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	logger.Infof("Upgraded legacy AOF %s, %d entries, the original is kept in %s.v1", path, entries, path)
+	return nil
+}